@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "sync/atomic"
+
+// accessGuard detects overlapping accesses to an arena that is not concurrent-safe.
+// It's only used when the package is built with the nuke_debug build tag.
+type accessGuard struct {
+	busy atomic.Bool
+}
+
+func (g *accessGuard) enter() {
+	if !g.busy.CompareAndSwap(false, true) {
+		panic("nuke: concurrent access to an arena that is not concurrent-safe (see NewConcurrentArena)")
+	}
+}
+
+func (g *accessGuard) exit() {
+	g.busy.Store(false)
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "unsafe"
+
+// AllocAligned allocates memory for a value of type T aligned to at least alignment bytes, which must
+// be a power of two, using the provided Arena. It's an escape hatch for values that need a stricter
+// alignment than that of their type, such as those updated with 128-bit atomics or kept on cache lines
+// of their own, in arenas not created with WithMinAlignment.
+//
+// The memory carries no type information, even in arenas returned by NewGCArena, so T must not hold
+// pointers. If the arena is nil or can't serve the allocation, or the package has been built with the
+// nuke_off build tag, the memory is allocated from the heap.
+func AllocAligned[T any](a Arena, alignment uintptr) *T {
+	var x T
+	if !validAlloc(unsafe.Sizeof(x), alignment) {
+		panic(invalidAllocError(unsafe.Sizeof(x), alignment))
+	}
+	alignment = max(alignment, unsafe.Alignof(x))
+	if debugEnabled {
+		assertPointerFree(typeOf[T]())
+	}
+	if a != nil && !arenasDisabled {
+		if ptr := a.Alloc(unsafe.Sizeof(x), alignment); ptr != nil {
+			if debugEnabled {
+				assertAligned(ptr, alignment)
+			}
+			return (*T)(ptr)
+		}
+		recordHeapFallback(a)
+	}
+	if alignment == unsafe.Alignof(x) {
+		return new(T)
+	}
+	// T is pointer-free, so it can live in a byte buffer big enough to be aligned within.
+	buf := make([]byte, max(unsafe.Sizeof(x), 1)+alignment-1)
+	return (*T)(alignPtr(unsafe.Pointer(unsafe.SliceData(buf)), alignment))
+}
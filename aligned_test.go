@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"sync/atomic"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMinAlignment(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(1024, 1, WithMinAlignment(64))
+
+	var prev uintptr
+	for i := 0; i < 4; i++ {
+		ptr := uintptr(unsafe.Pointer(New[atomic.Uint64](arena)))
+		require.Zero(t, ptr%64)
+		if prev != 0 {
+			require.Equal(t, prev+64, ptr) // every value gets a cache line of its own
+		}
+		prev = ptr
+	}
+	require.Zero(t, uintptr(arena.Alloc(8, 128))%128)
+
+	require.Panics(t, func() { NewMonotonicArena(1024, 1, WithMinAlignment(24)) })
+}
+
+func TestAllocAligned(t *testing.T) {
+	arenas := map[string]Arena{
+		"monotonic": NewMonotonicArena(1024, 1),
+		"gc":        NewGCArena(1024),
+		"nil":       nil,
+		"full":      NewMonotonicArena(8, 1),
+	}
+	for name, arena := range arenas {
+		t.Run(name, func(t *testing.T) {
+			_ = New[byte](arena)
+			for _, alignment := range []uintptr{1, 16, 64, 256} {
+				p := AllocAligned[[2]atomic.Uint64](arena, alignment)
+				require.Zero(t, uintptr(unsafe.Pointer(p))%max(alignment, 8))
+				p[1].Store(1)
+				require.Equal(t, uint64(1), p[1].Load())
+			}
+		})
+	}
+	require.Panics(t, func() { AllocAligned[int](nil, 3) })
+}
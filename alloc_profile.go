@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// AllocProfileName is the name of the pprof profile recording arena allocations.
+const AllocProfileName = "nuke_allocs"
+
+var (
+	allocProfileRate atomic.Int64
+
+	allocProfileOnce sync.Once
+	allocProfile     allocProfiler
+)
+
+// allocProfiler is the part of pprof.Profile used to record arena allocations.
+type allocProfiler interface {
+	Add(value any, skip int)
+	Remove(value any)
+}
+
+// SetAllocProfileRate enables the nuke_allocs pprof profile, which samples on average one
+// arena allocation every rate allocated bytes, along with its call stack.
+// Samples are kept until the arena they were allocated from is reset, so the profile
+// reflects the arena memory in use, with each sample accounting for about rate bytes.
+// A rate of zero disables the profile, which is the default.
+// Under TinyGo, which lacks runtime/pprof, nothing is recorded.
+func SetAllocProfileRate(rate int) {
+	allocProfileOnce.Do(func() {
+		allocProfile = newAllocProfile()
+	})
+	allocProfileRate.Store(int64(rate))
+}
+
+// allocSample is the unique key under which a sampled allocation is recorded in the profile.
+type allocSample struct {
+	size uintptr
+}
+
+// allocSampler decides which allocations of an arena get recorded into the profile.
+type allocSampler struct {
+	next    int64
+	samples []*allocSample
+}
+
+// sample records the allocation in the profile if it's due. The skip parameter
+// has the same meaning as in pprof.Profile.Add.
+func (s *allocSampler) sample(size uintptr, rate int64, skip int) {
+	s.next -= int64(size)
+	if s.next > 0 {
+		return
+	}
+	s.next = int64(rand.ExpFloat64() * float64(rate))
+
+	key := &allocSample{size: size}
+	allocProfile.Add(key, skip+1)
+	s.samples = append(s.samples, key)
+}
+
+// reset removes from the profile all samples recorded so far.
+func (s *allocSampler) reset() {
+	for i, key := range s.samples {
+		allocProfile.Remove(key)
+		s.samples[i] = nil
+	}
+	s.samples = s.samples[:0]
+}
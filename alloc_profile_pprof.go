@@ -0,0 +1,11 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+
+package nuke
+
+import "runtime/pprof"
+
+func newAllocProfile() allocProfiler {
+	return pprof.NewProfile(AllocProfileName)
+}
@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+
+package nuke
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocProfile(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	SetAllocProfileRate(1)
+	defer SetAllocProfileRate(0)
+
+	arena := NewMonotonicArena(1024, 1)
+
+	for i := 0; i < 10; i++ {
+		_ = New[int](arena)
+	}
+	prof := pprof.Lookup(AllocProfileName)
+	require.NotZero(t, prof.Count())
+
+	var buf bytes.Buffer
+	require.NoError(t, prof.WriteTo(&buf, 1))
+	require.Contains(t, buf.String(), "TestAllocProfile")
+
+	arena.Reset(false)
+	require.Equal(t, 0, prof.Count())
+}
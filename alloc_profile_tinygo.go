@@ -0,0 +1,15 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build tinygo
+
+package nuke
+
+func newAllocProfile() allocProfiler {
+	return noAllocProfile{}
+}
+
+type noAllocProfile struct{}
+
+func (noAllocProfile) Add(any, int) {}
+
+func (noAllocProfile) Remove(any) {}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"reflect"
+	"sort"
+)
+
+// maxAllocSiteDepth is the maximum number of stack frames recorded per allocation.
+const maxAllocSiteDepth = 16
+
+var pkgPath = reflect.TypeOf(Metrics{}).PkgPath()
+
+// AllocSite describes a code location allocating memory from an arena.
+type AllocSite struct {
+	// Function is the fully qualified name of the allocating function.
+	Function string
+
+	// File and Line locate the allocation within the source code.
+	File string
+	Line int
+
+	// Allocs is the number of allocations performed from this site.
+	Allocs uint64
+
+	// Bytes is the number of bytes allocated from this site.
+	Bytes uint64
+}
+
+// AllocSites returns up to n call sites that allocated the most bytes from the provided Arena
+// since it was last reset, in descending order.
+// Allocation sites are only tracked when the package is built with the nuke_debug build tag,
+// otherwise it returns nil.
+func AllocSites(a Arena, n int) []AllocSite {
+	r, ok := a.(allocSiteReporter)
+	if !ok {
+		return nil
+	}
+	sites := r.allocSites()
+	sort.Slice(sites, func(i, j int) bool {
+		return sites[i].Bytes > sites[j].Bytes
+	})
+	if len(sites) > n {
+		sites = sites[:n]
+	}
+	return sites
+}
+
+// allocSiteReporter is implemented by arenas tracking their allocation sites.
+type allocSiteReporter interface {
+	allocSites() []AllocSite
+}
+
+type allocStack [maxAllocSiteDepth]uintptr
+
+type allocStackStats struct {
+	allocs uint64
+	bytes  uint64
+}
+
+// allocSiteTracker aggregates allocations by call stack.
+type allocSiteTracker struct {
+	stacks map[allocStack]*allocStackStats
+}
+
+// record accounts for an allocation of the given size and returns its call stack. The skip
+// parameter has the same meaning as in runtime.Callers, with 0 identifying the caller of record.
+func (t *allocSiteTracker) record(size uintptr, skip int) allocStack {
+	stk := callers(skip + 1)
+
+	if t.stacks == nil {
+		t.stacks = make(map[allocStack]*allocStackStats)
+	}
+	st := t.stacks[stk]
+	if st == nil {
+		st = &allocStackStats{}
+		t.stacks[stk] = st
+	}
+	st.allocs++
+	st.bytes += uint64(size)
+	return stk
+}
+
+func (t *allocSiteTracker) reset() {
+	clear(t.stacks)
+}
+
+// sites returns the allocation sites recorded so far, unsorted. Every stack is attributed
+// to its innermost frame lying outside of this package.
+func (t *allocSiteTracker) sites() []AllocSite {
+	siteIdx := make(map[AllocSite]int)
+
+	var sites []AllocSite
+	for stk, st := range t.stacks {
+		site := allocSite(stk)
+
+		idx, ok := siteIdx[site]
+		if !ok {
+			idx = len(sites)
+			siteIdx[site] = idx
+			sites = append(sites, site)
+		}
+		sites[idx].Allocs += st.allocs
+		sites[idx].Bytes += st.bytes
+	}
+	return sites
+}
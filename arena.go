@@ -3,13 +3,30 @@
 package nuke
 
 import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"reflect"
 	"unsafe"
 )
 
+// maxAllocSize is the maximum size of a single allocation, which like in the Go runtime
+// is bounded by the largest value of int.
+const maxAllocSize = uint(^uint(0) >> 1)
+
+// ErrInvalidAlloc is the error arenas panic with when requested an allocation whose alignment
+// isn't a power of two or whose size is out of range (see WithPanicOnInvalidAlloc), as well as
+// the one New and MakeSlice panic with in nuke_debug builds when an arena returns misaligned memory.
+var ErrInvalidAlloc = errors.New("nuke: invalid allocation")
+
+// ErrHeapFallback is the error strict arenas panic with when they can't serve an allocation
+// that would otherwise be allocated from the heap (see WithStrictMode).
+var ErrHeapFallback = errors.New("nuke: allocation not served by the arena")
+
 // Arena is an interface that describes a memory allocation arena.
 type Arena interface {
 	// Alloc allocates memory of the given size and returns a pointer to it.
-	// The alignment parameter specifies the alignment of the allocated memory.
+	// The alignment parameter specifies the alignment of the allocated memory and must be a power of two.
 	Alloc(size, alignment uintptr) unsafe.Pointer
 
 	// Reset resets the arena's state, optionally releasing the memory.
@@ -19,13 +36,27 @@ type Arena interface {
 
 // New allocates memory for a value of type T using the provided Arena.
 // If the arena is non-nil, it returns a  *T pointer with memory allocated from the arena.
-// If passed arena is nil, it allocates memory using Go's built-in new function.
+// If passed arena is nil, or the package has been built with the nuke_off build tag,
+// it allocates memory using Go's built-in new function.
 func New[T any](a Arena) *T {
-	if a != nil {
+	if a != nil && !arenasDisabled {
 		var x T
-		if ptr := a.Alloc(unsafe.Sizeof(x), unsafe.Alignof(x)); ptr != nil {
+		var ptr unsafe.Pointer
+		if ta, ok := a.(typedAllocator); ok {
+			ptr = ta.allocTyped(typeOf[T](), 1)
+		} else {
+			if debugEnabled {
+				assertPointerFree(typeOf[T]())
+			}
+			ptr = a.Alloc(unsafe.Sizeof(x), unsafe.Alignof(x))
+		}
+		if ptr != nil {
+			if debugEnabled {
+				assertAligned(ptr, unsafe.Alignof(x))
+			}
 			return (*T)(ptr)
 		}
+		recordHeapFallback(a)
 	}
 	return new(T)
 }
@@ -33,15 +64,66 @@ func New[T any](a Arena) *T {
 // MakeSlice creates a slice of type T with a given length and capacity,
 // using the provided Arena for memory allocation.
 // If the arena is non-nil, it returns a slice with memory allocated from the arena.
-// Otherwise, it returns a slice using Go's built-in make function.
+// Otherwise, or if the package has been built with the nuke_off build tag,
+// it returns a slice using Go's built-in make function.
 func MakeSlice[T any](a Arena, len, cap int) []T {
-	if a != nil {
+	if a != nil && !arenasDisabled {
 		var x T
-		bufSize := int(unsafe.Sizeof(x)) * cap
-		if ptr := (*T)(a.Alloc(uintptr(bufSize), unsafe.Alignof(x))); ptr != nil {
+		bufSize, ok := sliceSize(unsafe.Sizeof(x), len, cap)
+		if !ok {
+			// Leave invalid lengths and capacities to make, which panics accordingly.
+			return make([]T, len, cap)
+		}
+		var ptr *T
+		if ta, ok := a.(typedAllocator); ok {
+			ptr = (*T)(ta.allocTyped(typeOf[T](), cap))
+		} else {
+			if debugEnabled {
+				assertPointerFree(typeOf[T]())
+			}
+			ptr = (*T)(a.Alloc(bufSize, unsafe.Alignof(x)))
+		}
+		if ptr != nil {
+			if debugEnabled {
+				assertAligned(unsafe.Pointer(ptr), unsafe.Alignof(x))
+			}
 			s := unsafe.Slice(ptr, cap)
 			return s[:len]
 		}
+		recordHeapFallback(a)
 	}
 	return make([]T, len, cap)
 }
+
+// sliceSize returns the size in bytes of the backing array of a slice with the given length and
+// capacity, reporting false if they're out of range or the size overflows the address space.
+func sliceSize(elemSize uintptr, len, cap int) (uintptr, bool) {
+	if len < 0 || len > cap {
+		return 0, false
+	}
+	hi, lo := bits.Mul(uint(elemSize), uint(cap))
+	if hi != 0 || lo > maxAllocSize {
+		return 0, false
+	}
+	return uintptr(lo), true
+}
+
+// validAlloc reports whether alignment is a power of two and size is within range.
+func validAlloc(size, alignment uintptr) bool {
+	return alignment != 0 && alignment&(alignment-1) == 0 && uint(size) <= maxAllocSize
+}
+
+func invalidAllocError(size, alignment uintptr) error {
+	return fmt.Errorf("%w: size %d, alignment %d", ErrInvalidAlloc, size, alignment)
+}
+
+// assertAligned panics if ptr isn't aligned to the given alignment.
+func assertAligned(ptr unsafe.Pointer, alignment uintptr) {
+	if uintptr(ptr)&(alignment-1) != 0 {
+		panic(fmt.Errorf("%w: arena returned %p, which isn't aligned to %d bytes", ErrInvalidAlloc, ptr, alignment))
+	}
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
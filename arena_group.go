@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "sync"
+
+// ArenaGroup owns several named arenas, such as the ones serving the different stages of
+// a request pipeline, giving them a single lifecycle: they're reset together, and their
+// metrics are combined. It's safe to be accessed concurrently from multiple goroutines,
+// while its arenas are only as safe as the ones added to it.
+type ArenaGroup struct {
+	mtx    sync.RWMutex
+	names  []string
+	arenas map[string]Arena
+}
+
+// NewArenaGroup returns an empty ArenaGroup.
+func NewArenaGroup() *ArenaGroup {
+	return &ArenaGroup{arenas: make(map[string]Arena)}
+}
+
+// Add adds the arena to the group under the given name.
+// It panics if the group holds an arena with that name already.
+func (g *ArenaGroup) Add(name string, a Arena) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if _, ok := g.arenas[name]; ok {
+		panic("nuke: arena " + name + " already in group")
+	}
+	g.names = append(g.names, name)
+	g.arenas[name] = a
+}
+
+// Arena returns the arena of the group with the given name, or nil if there's none.
+func (g *ArenaGroup) Arena(name string) Arena {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+	return g.arenas[name]
+}
+
+// Names returns the names of the arenas of the group, in the order they were added.
+func (g *ArenaGroup) Names() []string {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+	return append([]string(nil), g.names...)
+}
+
+// Reset resets every arena of the group, optionally releasing their memory, in the order
+// they were added. Arenas can't be added to the group while it's being reset.
+func (g *ArenaGroup) Reset(release bool) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	for _, name := range g.names {
+		g.arenas[name].Reset(release)
+	}
+}
+
+// Metrics returns the sum of the allocation metrics of the arenas of the group.
+func (g *ArenaGroup) Metrics() Metrics {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	var m Metrics
+	for _, name := range g.names {
+		am := arenaMetrics(g.arenas[name])
+		m.Allocs += am.Allocs
+		m.AllocatedBytes += am.AllocatedBytes
+		m.FailedAllocs += am.FailedAllocs
+		m.HeapFallbacks += am.HeapFallbacks
+		m.Resets += am.Resets
+		m.GrownBuffers += am.GrownBuffers
+		m.Frees += am.Frees
+		m.FreedBytes += am.FreedBytes
+	}
+	return m
+}
+
+// ArenaMetrics returns the allocation metrics of every arena of the group, by name.
+func (g *ArenaGroup) ArenaMetrics() map[string]Metrics {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	m := make(map[string]Metrics, len(g.names))
+	for _, name := range g.names {
+		m[name] = arenaMetrics(g.arenas[name])
+	}
+	return m
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArenaGroup(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	headers := NewMonotonicArena(1024, 1)
+	body := NewMonotonicArena(1024, 1)
+
+	g := NewArenaGroup()
+	g.Add("headers", headers)
+	g.Add("body", body)
+	require.Panics(t, func() { g.Add("body", body) })
+
+	require.Equal(t, []string{"headers", "body"}, g.Names())
+	require.Same(t, body, g.Arena("body"))
+	require.Nil(t, g.Arena("index"))
+
+	New[int](g.Arena("headers"))
+	MakeSlice[byte](g.Arena("body"), 2048, 2048) // falls back to the heap
+
+	m := g.Metrics()
+	require.Equal(t, uint64(1), m.Allocs)
+	require.Equal(t, uint64(1), m.FailedAllocs)
+	require.Equal(t, uint64(1), m.HeapFallbacks)
+	require.Equal(t, uint64(1), g.ArenaMetrics()["body"].HeapFallbacks)
+
+	g.Reset(false)
+	require.Equal(t, uint64(2), g.Metrics().Resets)
+	require.Zero(t, headers.(bufferUsageReporter).BufferUsage()[0].Used)
+}
@@ -0,0 +1,243 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PoolOption configures an ArenaPool.
+type PoolOption func(*poolOptions)
+
+type poolOptions struct {
+	maxArenas int
+	blocking  bool
+	idleTTL   time.Duration
+	sizer     *ArenaSizer
+	pressure  *PressureMonitor
+}
+
+// WithMaxArenas bounds the number of live arenas of the pool, counting both those handed out
+// and those idle in the pool, to n. Once reached, Get allocates arenas beyond the bound, which
+// are dropped rather than pooled when put back, unless WithBlockingGet is also given.
+func WithMaxArenas(n int) PoolOption {
+	return func(o *poolOptions) { o.maxArenas = n }
+}
+
+// WithBlockingGet makes Get block, when the number of live arenas has reached the bound set
+// with WithMaxArenas, until an arena is put back into the pool.
+func WithBlockingGet() PoolOption {
+	return func(o *poolOptions) { o.blocking = true }
+}
+
+// WithIdleTTL makes the pool drop, releasing their memory, the arenas that have been idle in it
+// for longer than ttl. Close must be called to stop the goroutine checking for them.
+func WithIdleTTL(ttl time.Duration) PoolOption {
+	return func(o *poolOptions) { o.idleTTL = ttl }
+}
+
+// PoolStats holds the number of arenas of an ArenaPool.
+type PoolStats struct {
+	// Live is the number of arenas created by the pool and not dropped yet, either handed out or idle.
+	Live int `json:"live"`
+
+	// Idle is the number of arenas idle in the pool.
+	Idle int `json:"idle"`
+
+	// Waiting is the number of Get calls blocked waiting for an arena.
+	Waiting int `json:"waiting"`
+}
+
+// ArenaPool is a pool of arenas, allowing their memory to be reused across units of work
+// such as requests. It's safe to be accessed concurrently from multiple goroutines, while
+// the arenas it hands out are only as safe as the ones returned by the creation function.
+//
+// By default, the pool is backed by a sync.Pool, so idle arenas may be dropped at any garbage
+// collection. Pools created with WithMaxArenas or WithIdleTTL keep track of their arenas instead,
+// keeping idle ones until they're handed out again or expire.
+type ArenaPool struct {
+	newArena func() Arena
+	opts     poolOptions
+	pool     sync.Pool
+	managed  bool
+
+	mtx     sync.Mutex
+	idle    []idleArena // from least to most recently put
+	live    int
+	waiters []chan struct{}
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+type idleArena struct {
+	a     Arena
+	since time.Time
+}
+
+// NewArenaPool returns an ArenaPool creating arenas with newArena when empty.
+func NewArenaPool(newArena func() Arena, opts ...PoolOption) *ArenaPool {
+	p := &ArenaPool{
+		newArena: newArena,
+		pool:     sync.Pool{New: func() any { return newArena() }},
+		stopCh:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&p.opts)
+	}
+	p.managed = p.opts.maxArenas > 0 || p.opts.idleTTL > 0
+	if p.opts.idleTTL > 0 {
+		go p.expire(p.opts.idleTTL)
+	}
+	if p.opts.pressure != nil {
+		p.opts.pressure.OnPressure(p.releaseIdle)
+	}
+	return p
+}
+
+// Get returns an arena from the pool, creating a new one if none is available.
+// Pools created with WithBlockingGet may block until an arena is available.
+func (p *ArenaPool) Get() Arena {
+	a, _ := p.GetContext(context.Background())
+	return a
+}
+
+// GetContext is like Get, but returns the context error if the context is done
+// while waiting for an arena.
+func (p *ArenaPool) GetContext(ctx context.Context) (Arena, error) {
+	if !p.managed {
+		return p.pool.Get().(Arena), nil
+	}
+	p.mtx.Lock()
+	for {
+		if n := len(p.idle); n > 0 {
+			a := p.idle[n-1].a
+			p.idle[n-1] = idleArena{}
+			p.idle = p.idle[:n-1]
+			p.mtx.Unlock()
+			return a, nil
+		}
+		if p.opts.maxArenas <= 0 || p.live < p.opts.maxArenas || !p.opts.blocking {
+			p.live++
+			p.mtx.Unlock()
+			return p.newArena(), nil
+		}
+		ch := make(chan struct{})
+		p.waiters = append(p.waiters, ch)
+		p.mtx.Unlock()
+
+		select {
+		case <-ch:
+			p.mtx.Lock()
+
+		case <-ctx.Done():
+			p.mtx.Lock()
+			if !p.removeWaiter(ch) {
+				// The wake-up raced with the cancellation, so pass it on.
+				p.wakeWaiter()
+			}
+			p.mtx.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Put resets the arena, keeping its memory unless under memory pressure (see WithPressureMonitor),
+// and returns it to the pool.
+// Any pointer previously allocated from the arena becomes invalid.
+func (p *ArenaPool) Put(a Arena) {
+	if p.opts.sizer != nil {
+		p.opts.sizer.Observe(a)
+	}
+	a.Reset(p.opts.pressure != nil && p.opts.pressure.UnderPressure())
+	if !p.managed {
+		p.pool.Put(a)
+		return
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.opts.maxArenas > 0 && p.live > p.opts.maxArenas {
+		p.live-- // drop arenas allocated beyond the bound
+		return
+	}
+	p.idle = append(p.idle, idleArena{a: a, since: time.Now()})
+	p.wakeWaiter()
+}
+
+// Stats returns the number of arenas of the pool. Pools not created with WithMaxArenas
+// or WithIdleTTL don't keep track of their arenas, so they report zero stats.
+func (p *ArenaPool) Stats() PoolStats {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return PoolStats{Live: p.live, Idle: len(p.idle), Waiting: len(p.waiters)}
+}
+
+// Close stops the goroutine dropping expired arenas, if any. The pool remains usable afterwards,
+// but its idle arenas won't expire anymore.
+func (p *ArenaPool) Close() {
+	p.stopOnce.Do(func() { close(p.stopCh) })
+}
+
+// releaseIdle releases the memory of the arenas idle in the pool, if it keeps track of them.
+func (p *ArenaPool) releaseIdle() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for _, ia := range p.idle {
+		// Idle arenas are reset already, which makes Reset(true) keep the memory of some of them.
+		if t, ok := ia.a.(Trimmer); ok {
+			t.Trim()
+		} else {
+			ia.a.Reset(true)
+		}
+	}
+}
+
+func (p *ArenaPool) wakeWaiter() {
+	if len(p.waiters) > 0 {
+		close(p.waiters[0])
+		p.waiters[0] = nil
+		p.waiters = p.waiters[1:]
+	}
+}
+
+func (p *ArenaPool) removeWaiter(ch chan struct{}) bool {
+	for i, w := range p.waiters {
+		if w == ch {
+			p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ArenaPool) expire(ttl time.Duration) {
+	tc := time.NewTicker(max(ttl/2, time.Millisecond))
+	defer tc.Stop()
+
+	for {
+		select {
+		case now := <-tc.C:
+			p.mtx.Lock()
+			n := 0
+			for n < len(p.idle) && now.Sub(p.idle[n].since) >= ttl {
+				n++
+			}
+			expired := append([]idleArena(nil), p.idle[:n]...)
+			m := copy(p.idle, p.idle[n:])
+			clear(p.idle[m:])
+			p.idle = p.idle[:m]
+			p.live -= n
+			p.mtx.Unlock()
+
+			for _, ia := range expired {
+				ia.a.Reset(true)
+			}
+
+		case <-p.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArenaPool(t *testing.T) {
+	pool := NewArenaPool(func() Arena { return NewMonotonicArena(1024, 1) })
+
+	a := pool.Get()
+	require.NotNil(t, a)
+
+	_ = New[int](a)
+	pool.Put(a)
+
+	// Arenas are reset before being returned to the pool.
+	require.Equal(t, uint64(1), a.(Stater).Metrics().Resets)
+	require.Zero(t, a.(bufferUsageReporter).BufferUsage()[0].Used)
+}
+
+func TestArenaPoolMaxArenas(t *testing.T) {
+	pool := NewArenaPool(func() Arena { return NewMonotonicArena(1024, 1) }, WithMaxArenas(1))
+
+	a1 := pool.Get()
+	a2 := pool.Get() // allocated beyond the bound
+	require.Equal(t, PoolStats{Live: 2}, pool.Stats())
+
+	pool.Put(a1)
+	require.Equal(t, PoolStats{Live: 1}, pool.Stats())
+
+	pool.Put(a2)
+	require.Equal(t, PoolStats{Live: 1, Idle: 1}, pool.Stats())
+	require.Same(t, a2, pool.Get())
+}
+
+func TestArenaPoolBlockingGet(t *testing.T) {
+	pool := NewArenaPool(func() Arena { return NewMonotonicArena(1024, 1) }, WithMaxArenas(1), WithBlockingGet())
+
+	a := pool.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := pool.GetContext(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	got := make(chan Arena)
+	go func() { got <- pool.Get() }()
+	require.Eventually(t, func() bool { return pool.Stats().Waiting == 1 }, time.Second, time.Millisecond)
+
+	pool.Put(a)
+	require.Same(t, a, <-got)
+	require.Equal(t, PoolStats{Live: 1}, pool.Stats())
+}
+
+func TestArenaPoolIdleTTL(t *testing.T) {
+	pool := NewArenaPool(func() Arena { return NewMonotonicArena(1024, 1) }, WithIdleTTL(5*time.Millisecond))
+	defer pool.Close()
+
+	a := pool.Get()
+	_ = New[int](a)
+	pool.Put(a)
+	require.Equal(t, PoolStats{Live: 1, Idle: 1}, pool.Stats())
+
+	require.Eventually(t, func() bool { return pool.Stats() == PoolStats{} }, time.Second, time.Millisecond)
+}
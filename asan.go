@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build asan
+
+package nuke
+
+/*
+#include <sanitizer/asan_interface.h>
+
+static void nuke_asan_poison(void *addr, size_t size) {
+	ASAN_POISON_MEMORY_REGION(addr, size);
+}
+
+static void nuke_asan_unpoison(void *addr, size_t size) {
+	ASAN_UNPOISON_MEMORY_REGION(addr, size);
+}
+
+static int nuke_asan_is_poisoned(void *addr) {
+	return __asan_address_is_poisoned(addr);
+}
+*/
+import "C"
+
+import "unsafe"
+
+// asanEnabled reports whether the package has been built with address sanitizer support.
+const asanEnabled = true
+
+// asanPoison marks the given memory region as unaddressable for the address sanitizer.
+func asanPoison(ptr unsafe.Pointer, size uintptr) {
+	C.nuke_asan_poison(ptr, C.size_t(size))
+}
+
+// asanUnpoison marks the given memory region as addressable for the address sanitizer.
+func asanUnpoison(ptr unsafe.Pointer, size uintptr) {
+	C.nuke_asan_unpoison(ptr, C.size_t(size))
+}
+
+// asanIsPoisoned reports whether the address sanitizer considers the byte at ptr unaddressable.
+func asanIsPoisoned(ptr unsafe.Pointer) bool {
+	return C.nuke_asan_is_poisoned(ptr) != 0
+}
@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build asan
+
+package nuke
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonotonicArenaASanPoisoning(t *testing.T) {
+	arena := NewMonotonicArena(1024, 1).(*monotonicArena)
+
+	s := MakeSlice[byte](arena, 32, 32)
+	base := unsafe.Pointer(unsafe.SliceData(s))
+
+	// Allocated memory is addressable, while the rest of the buffer is not.
+	require.False(t, asanIsPoisoned(base))
+	require.False(t, asanIsPoisoned(unsafe.Add(base, 31)))
+	require.True(t, asanIsPoisoned(unsafe.Add(base, 32)))
+
+	// Only the bytes handed out from a tiny block are addressable.
+	b := New[int64](arena)
+	require.False(t, asanIsPoisoned(unsafe.Pointer(b)))
+	require.True(t, asanIsPoisoned(unsafe.Add(unsafe.Pointer(b), 8)))
+
+	arena.Reset(false)
+	require.True(t, asanIsPoisoned(base))
+	require.True(t, asanIsPoisoned(unsafe.Pointer(b)))
+
+	// Reused memory becomes addressable again.
+	s = MakeSlice[byte](arena, 32, 32)
+	require.False(t, asanIsPoisoned(unsafe.Pointer(unsafe.SliceData(s))))
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"io"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// minRead is the minimum space ReadFrom makes available for every Read call.
+const minRead = 512
+
+// Buffer is a variable-sized buffer of bytes whose memory is allocated from an arena,
+// growing like SliceAppend does. It's meant as the destination of encoders and writers
+// whose output is short-lived, and its contents are only valid until the arena is reset.
+// The zero value is an empty buffer allocating from the heap.
+type Buffer struct {
+	a   Arena
+	buf []byte
+}
+
+// NewBuffer returns an empty Buffer allocating from the arena, with room for size bytes.
+func NewBuffer(a Arena, size int) *Buffer {
+	return &Buffer{a: a, buf: MakeSlice[byte](a, 0, size)}
+}
+
+// Write appends the contents of p to the buffer. It satisfies the io.Writer interface
+// and never returns an error.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.buf = SliceAppend(b.a, b.buf, p...)
+	return len(p), nil
+}
+
+// WriteString appends the contents of s to the buffer. It never returns an error.
+func (b *Buffer) WriteString(s string) (int, error) {
+	b.buf = SliceAppend(b.a, b.buf, unsafe.Slice(unsafe.StringData(s), len(s))...)
+	return len(s), nil
+}
+
+// WriteByte appends the byte c to the buffer. It never returns an error.
+func (b *Buffer) WriteByte(c byte) error {
+	b.buf = SliceAppend(b.a, b.buf, c)
+	return nil
+}
+
+// WriteRune appends the UTF-8 encoding of r to the buffer. It never returns an error.
+func (b *Buffer) WriteRune(r rune) (int, error) {
+	var p [utf8.UTFMax]byte
+	n := utf8.EncodeRune(p[:], r)
+	b.buf = SliceAppend(b.a, b.buf, p[:n]...)
+	return n, nil
+}
+
+// ReadFrom reads data from r until EOF and appends it to the buffer, growing it as needed.
+// The return value n is the number of bytes read. Any error except io.EOF encountered
+// during the read is also returned.
+func (b *Buffer) ReadFrom(r io.Reader) (n int64, err error) {
+	for {
+		if cap(b.buf)-len(b.buf) < minRead {
+			b.buf = growSlice(b.a, b.buf, minRead)
+		}
+		m, err := r.Read(b.buf[len(b.buf):cap(b.buf)])
+		if m < 0 {
+			panic("nuke: reader returned negative count from Read")
+		}
+		b.buf = b.buf[:len(b.buf)+m]
+		n += int64(m)
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+}
+
+// Grow grows the buffer's capacity, if necessary, to guarantee space for another n bytes.
+func (b *Buffer) Grow(n int) {
+	if n < 0 {
+		panic("nuke: negative Buffer.Grow count")
+	}
+	b.buf = growSlice(b.a, b.buf, n)
+}
+
+// Bytes returns the contents of the buffer, which remain valid until the next buffer
+// modification or arena reset.
+func (b *Buffer) Bytes() []byte {
+	return b.buf
+}
+
+// String returns the contents of the buffer as a string sharing its memory, so it's only
+// valid until the next buffer modification or arena reset.
+func (b *Buffer) String() string {
+	return unsafe.String(unsafe.SliceData(b.buf), len(b.buf))
+}
+
+// Len returns the number of bytes in the buffer.
+func (b *Buffer) Len() int {
+	return len(b.buf)
+}
+
+// Cap returns the capacity of the buffer.
+func (b *Buffer) Cap() int {
+	return cap(b.buf)
+}
+
+// Truncate discards all but the first n bytes of the buffer.
+func (b *Buffer) Truncate(n int) {
+	b.buf = b.buf[:n]
+}
+
+// Reset empties the buffer, keeping its memory for future writes.
+func (b *Buffer) Reset() {
+	b.buf = b.buf[:0]
+}
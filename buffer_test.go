@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuffer(t *testing.T) {
+	arena := NewMonotonicArena(1024, 1)
+
+	b := NewBuffer(arena, 4)
+	_, _ = b.WriteString("hello")
+	_ = b.WriteByte(',')
+	_, _ = b.WriteRune('🙂')
+	_, _ = fmt.Fprintf(b, " %d", 42)
+
+	require.Equal(t, "hello,🙂 42", b.String())
+	require.Equal(t, b.Len(), len(b.Bytes()))
+	require.Zero(t, arenaMetrics(arena).HeapFallbacks)
+
+	b.Truncate(5)
+	require.Equal(t, "hello", b.String())
+
+	b.Reset()
+	require.Zero(t, b.Len())
+
+	b.Grow(100)
+	require.GreaterOrEqual(t, b.Cap(), 100)
+}
+
+func TestBufferReadFrom(t *testing.T) {
+	arena := NewMonotonicArena(64*1024, 1)
+
+	data := strings.Repeat("nuke", 1000)
+	b := NewBuffer(arena, 0)
+	n, err := b.ReadFrom(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(data)), n)
+	require.Equal(t, data, b.String())
+	require.Zero(t, arenaMetrics(arena).HeapFallbacks)
+}
+
+func TestBufferZeroValue(t *testing.T) {
+	var b Buffer
+	_, _ = b.Write([]byte("hello"))
+	require.Equal(t, "hello", b.String())
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+const (
+	// canarySize is the size of the canary word placed after every allocation (see WithCanaries).
+	canarySize = 8
+
+	// canaryByte is the value canary words are filled with.
+	canaryByte = 0xCA
+)
+
+// CanaryError is the error reported when the canary word following an allocation has been
+// overwritten, which means that the code using the allocation wrote past its end.
+type CanaryError struct {
+	// Site is the location the overrun allocation was made from.
+	Site AllocSite
+
+	// Size is the size of the overrun allocation.
+	Size uintptr
+}
+
+// Error satisfies the error interface.
+func (e *CanaryError) Error() string {
+	return fmt.Sprintf("nuke: write past the end of a %d byte allocation made by %s (%s:%d)",
+		e.Size, e.Site.Function, e.Site.File, e.Site.Line)
+}
+
+// CheckCanaries verifies the canary words placed after every allocation made from the provided
+// Arena since it was last reset, returning a *CanaryError for the first overwritten one.
+// It returns nil if the arena doesn't place canaries (see WithCanaries).
+func CheckCanaries(a Arena) error {
+	if c, ok := a.(canaryChecker); ok {
+		return c.checkCanaries()
+	}
+	return nil
+}
+
+// canaryChecker is implemented by arenas placing canaries after their allocations.
+type canaryChecker interface {
+	checkCanaries() error
+}
+
+type canary struct {
+	ptr   unsafe.Pointer
+	size  uintptr
+	stack allocStack
+}
+
+// canaryTracker keeps track of the canary words placed after every allocation.
+type canaryTracker struct {
+	enabled  bool
+	canaries []canary
+}
+
+// add places a canary word at ptr, which follows an allocation of the given size made from stk.
+func (t *canaryTracker) add(ptr unsafe.Pointer, size uintptr, stk allocStack) {
+	b := unsafe.Slice((*byte)(ptr), canarySize)
+	for i := range b {
+		b[i] = canaryByte
+	}
+	asanPoison(ptr, canarySize)
+
+	t.canaries = append(t.canaries, canary{ptr: ptr, size: size, stack: stk})
+}
+
+func (t *canaryTracker) check() error {
+	for _, c := range t.canaries {
+		asanUnpoison(c.ptr, canarySize)
+		b := unsafe.Slice((*byte)(c.ptr), canarySize)
+		for i := range b {
+			if b[i] != canaryByte {
+				site := allocSite(c.stack)
+				site.Allocs = 1
+				site.Bytes = uint64(c.size)
+				return &CanaryError{Site: site, Size: c.size}
+			}
+		}
+		asanPoison(c.ptr, canarySize)
+	}
+	return nil
+}
+
+func (t *canaryTracker) reset() {
+	clear(t.canaries) // don't keep released buffers reachable
+	t.canaries = t.canaries[:0]
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"errors"
+	"strings"
+	"unsafe"
+)
+
+// ErrArenaFull is the error TryAlloc returns when the arena has no room for an allocation.
+var ErrArenaFull = errors.New("nuke: arena full")
+
+// ErrArenaDrained is the error TryAlloc returns when the arena is in drain mode (see Drain).
+var ErrArenaDrained = errors.New("nuke: arena drained")
+
+// TryAllocator is implemented by arenas reporting why they couldn't serve an allocation.
+type TryAllocator interface {
+	// TryAlloc is like Alloc, but returns an error describing why the allocation couldn't be served,
+	// such as ErrArenaFull, ErrArenaDrained or ErrInvalidAlloc, instead of a nil pointer.
+	TryAlloc(size, alignment uintptr) (unsafe.Pointer, error)
+}
+
+// TryAlloc allocates memory of the given size and alignment from the arena, returning an error
+// if it can't be served. Arenas not implementing TryAllocator report ErrArenaFull for any failure.
+func TryAlloc(a Arena, size, alignment uintptr) (unsafe.Pointer, error) {
+	if ta, ok := a.(TryAllocator); ok {
+		return ta.TryAlloc(size, alignment)
+	}
+	if ptr := a.Alloc(size, alignment); ptr != nil {
+		return ptr, nil
+	}
+	return nil, ErrArenaFull
+}
+
+// Capability is a set of optional features an arena supports.
+type Capability uint
+
+const (
+	// CapMetrics means the arena reports its allocation metrics.
+	CapMetrics Capability = 1 << iota
+
+	// CapBufferUsage means the arena reports the usage of its buffers.
+	CapBufferUsage
+
+	// CapSizeHistogram means the arena may keep a histogram of allocation sizes (see WithSizeHistogram).
+	CapSizeHistogram
+
+	// CapTypedAlloc means the arena is told the type of the values allocated by New, MakeSlice
+	// and SliceAppend, which makes it safe for types holding pointers (see NewGCArena).
+	CapTypedAlloc
+
+	// CapTrim means the arena can release the memory of its unused buffers.
+	CapTrim
+
+	// CapDrain means the arena supports drain mode (see Drain).
+	CapDrain
+
+	// CapTryAlloc means the arena reports why allocations fail (see TryAllocator).
+	CapTryAlloc
+
+	// CapFree means the arena may take back the memory of allocations before being reset (see Freer).
+	CapFree
+
+	// CapSnapshot means the arena can copy out the memory it has handed out (see Snapshotter).
+	CapSnapshot
+
+	// CapSave means the arena can be saved and loaded, and addressed by offset (see Save).
+	CapSave
+
+	// CapPin means the arena can pin the memory backing it for cgo interop (see PinArena).
+	CapPin
+)
+
+var capabilityNames = []string{
+	"metrics",
+	"buffer-usage",
+	"size-histogram",
+	"typed-alloc",
+	"trim",
+	"drain",
+	"try-alloc",
+	"free",
+	"snapshot",
+	"save",
+	"pin",
+}
+
+// Has reports whether the set holds all the capabilities of c2.
+func (c Capability) Has(c2 Capability) bool {
+	return c&c2 == c2
+}
+
+// String returns the names of the capabilities of the set, separated by '|'.
+func (c Capability) String() string {
+	var names []string
+	for i, name := range capabilityNames {
+		if c&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// wrapper is implemented by arenas forwarding to other arenas, such as the ones returned by
+// NewConcurrentArena, which only support the capabilities the arenas they wrap support.
+type wrapper interface {
+	wrapped() []Arena
+}
+
+// Capabilities returns the optional features the arena supports, so that code can check for
+// them up front rather than finding out by trial. The capabilities of wrappers such as the ones
+// returned by NewConcurrentArena are resolved through the arenas they wrap.
+func Capabilities(a Arena) Capability {
+	var c Capability
+	if _, ok := a.(Stater); ok {
+		c |= CapMetrics
+	}
+	if _, ok := a.(bufferUsageReporter); ok {
+		c |= CapBufferUsage
+	}
+	if _, ok := a.(sizeHistogramReporter); ok {
+		c |= CapSizeHistogram
+	}
+	if _, ok := a.(typedAllocator); ok {
+		c |= CapTypedAlloc
+	}
+	if _, ok := a.(Trimmer); ok {
+		c |= CapTrim
+	}
+	if _, ok := a.(drainer); ok {
+		c |= CapDrain
+	}
+	if _, ok := a.(TryAllocator); ok {
+		c |= CapTryAlloc
+	}
+	if _, ok := a.(Freer); ok {
+		c |= CapFree
+	}
+	if _, ok := a.(Snapshotter); ok {
+		c |= CapSnapshot
+	}
+	if _, ok := a.(persister); ok {
+		c |= CapSave
+	}
+	if _, ok := a.(pinner); ok {
+		c |= CapPin
+	}
+	if w, ok := a.(wrapper); ok {
+		for _, inner := range w.wrapped() {
+			c &= Capabilities(inner)
+		}
+	}
+	return c
+}
+
+func (a *concurrentArena) wrapped() []Arena {
+	return []Arena{a.a}
+}
+
+func (a *ScavengingArena) wrapped() []Arena {
+	return []Arena{a.a}
+}
+
+func (a *FlipArena) wrapped() []Arena {
+	return a.arenas[:]
+}
+
+// TryAlloc satisfies the TryAllocator interface.
+func (a *monotonicArena) TryAlloc(size, alignment uintptr) (unsafe.Pointer, error) {
+	switch {
+	case arenasDisabled:
+		return nil, ErrArenaFull
+	case a.drained:
+		return nil, ErrArenaDrained
+	case !validAlloc(size, alignment):
+		a.metrics.FailedAllocs++
+		return nil, invalidAllocError(size, alignment)
+	}
+	if ptr := a.Alloc(size, alignment); ptr != nil {
+		return ptr, nil
+	}
+	return nil, ErrArenaFull
+}
+
+// TryAlloc satisfies the TryAllocator interface.
+func (a *gcArena) TryAlloc(size, alignment uintptr) (unsafe.Pointer, error) {
+	switch {
+	case arenasDisabled:
+		return nil, ErrArenaFull
+	case a.drained:
+		return nil, ErrArenaDrained
+	case !validAlloc(size, alignment):
+		a.metrics.FailedAllocs++
+		return nil, invalidAllocError(size, alignment)
+	}
+	if ptr := a.Alloc(size, alignment); ptr != nil {
+		return ptr, nil
+	}
+	return nil, ErrArenaFull
+}
+
+// TryAlloc satisfies the TryAllocator interface.
+func (a *concurrentArena) TryAlloc(size, alignment uintptr) (unsafe.Pointer, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return TryAlloc(a.a, size, alignment)
+}
+
+// TryAlloc satisfies the TryAllocator interface.
+func (a *ScavengingArena) TryAlloc(size, alignment uintptr) (unsafe.Pointer, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.active = true
+	return TryAlloc(a.a, size, alignment)
+}
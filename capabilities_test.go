@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryAlloc(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewMonotonicArena(64, 1)
+
+	ptr, err := TryAlloc(a, 64, 8)
+	require.NoError(t, err)
+	require.NotNil(t, ptr)
+
+	_, err = TryAlloc(a, 8, 8)
+	require.ErrorIs(t, err, ErrArenaFull)
+
+	_, err = TryAlloc(a, 8, 3)
+	require.ErrorIs(t, err, ErrInvalidAlloc)
+	require.Equal(t, uint64(2), arenaMetrics(a).FailedAllocs)
+
+	Drain(a)
+	_, err = TryAlloc(a, 8, 8)
+	require.ErrorIs(t, err, ErrArenaDrained)
+}
+
+func TestTryAllocWrapped(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewConcurrentArena(NewMonotonicArena(64, 1))
+	Drain(a)
+	_, err := TryAlloc(a, 8, 8)
+	require.ErrorIs(t, err, ErrArenaDrained)
+
+	// Arenas not reporting errors fail with ErrArenaFull.
+	_, err = TryAlloc(nilArena{}, 8, 8)
+	require.ErrorIs(t, err, ErrArenaFull)
+}
+
+func TestCapabilities(t *testing.T) {
+	c := Capabilities(NewMonotonicArena(64, 1))
+	require.True(t, c.Has(CapMetrics|CapBufferUsage|CapTrim|CapDrain|CapTryAlloc|CapFree|CapSnapshot|CapSave|CapPin))
+	require.False(t, c.Has(CapTypedAlloc))
+
+	c = Capabilities(NewConcurrentArena(NewGCArena(64)))
+	require.True(t, c.Has(CapTypedAlloc|CapTryAlloc))
+
+	// Wrappers only support what the arenas they wrap support.
+	scavenging := NewScavengingArena(NewMonotonicArena(64, 1), time.Hour)
+	defer scavenging.Close()
+	require.False(t, Capabilities(scavenging).Has(CapTypedAlloc))
+	require.True(t, Capabilities(scavenging).Has(CapSave))
+	require.False(t, Capabilities(NewFlipArena(NewMonotonicArena(64, 1), NewMonotonicArena(64, 1))).Has(CapTypedAlloc))
+	require.False(t, Capabilities(NewFlipArena(NewGCArena(64), NewMonotonicArena(64, 1))).Has(CapTypedAlloc))
+	require.True(t, Capabilities(NewFlipArena(NewGCArena(64), NewGCArena(64))).Has(CapTypedAlloc))
+	require.Zero(t, Capabilities(NewConcurrentArena(nilArena{})))
+	require.Zero(t, Capabilities(NewFlipArena(nilArena{}, NewGCArena(64))))
+	require.False(t, Capabilities(NewConcurrentArena(NewGCArena(64))).Has(CapFree|CapSnapshot|CapSave))
+
+	require.Zero(t, Capabilities(nilArena{}))
+	require.Equal(t, "metrics|trim|try-alloc", (CapMetrics | CapTrim | CapTryAlloc).String())
+}
+
+type nilArena struct{}
+
+func (nilArena) Alloc(uintptr, uintptr) unsafe.Pointer { return nil }
+
+func (nilArena) Reset(bool) {}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// ErrChecksumMismatch is the error Verify returns when the memory of an arena doesn't match a checksum.
+var ErrChecksumMismatch = errors.New("nuke: checksum mismatch")
+
+// castagnoli is the CRC-32C table, which most CPUs compute in hardware.
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// Checksum returns the CRC-32C checksum of the memory handed out by the arena since its last
+// reset, as returned by Bytes, which is the checksum of its snapshot too. Receivers of a snapshot
+// can thus verify it with crc32.Checksum and a crc32.Castagnoli table. Arenas not implementing
+// Snapshotter have the checksum of no memory, which is zero.
+func Checksum(a Arena) uint32 {
+	var sum uint32
+	for _, r := range Bytes(a) {
+		sum = crc32.Update(sum, castagnoli, r)
+	}
+	return sum
+}
+
+// Checksums returns the CRC-32C checksum of every region of memory returned by Bytes, such as
+// every buffer of a monotonic arena, so that corruption can be narrowed down to a region.
+func Checksums(a Arena) []uint32 {
+	regions := Bytes(a)
+	sums := make([]uint32, len(regions))
+	for i, r := range regions {
+		sums[i] = crc32.Checksum(r, castagnoli)
+	}
+	return sums
+}
+
+// Verify checks that the memory handed out by the arena matches a checksum returned by Checksum,
+// returning ErrChecksumMismatch otherwise.
+func Verify(a Arena, sum uint32) error {
+	if got := Checksum(a); got != sum {
+		return fmt.Errorf("%w: got %#08x, want %#08x", ErrChecksumMismatch, got, sum)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewMonotonicArena(64, 2)
+	x := New[[6]uint64](a)
+	x[0] = 42
+	*New[[4]uint64](a) = [4]uint64{1, 2, 3, 4} // doesn't fit the first buffer
+
+	sum := Checksum(a)
+	require.Equal(t, crc32.Checksum(Snapshot(a), crc32.MakeTable(crc32.Castagnoli)), sum)
+	require.NoError(t, Verify(a, sum))
+
+	sums := Checksums(a)
+	require.Len(t, sums, 2)
+
+	x[5] = 1
+	require.ErrorIs(t, Verify(a, sum), ErrChecksumMismatch)
+	require.NotEqual(t, sums[0], Checksums(a)[0])
+	require.Equal(t, sums[1], Checksums(a)[1])
+
+	require.Zero(t, Checksum(NewGCArena(64)))
+	require.Empty(t, Checksums(NewGCArena(64)))
+}
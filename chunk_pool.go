@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ChunkPool hands out fixed-size byte chunks allocated from an arena, meant for framing layers
+// such as WebSocket or QUIC, where a frame may be shared by several writers before being recycled.
+// Chunks are reference counted: they're recycled into the pool once every holder has released them,
+// so that the memory of the arena is reused instead of growing with every frame.
+//
+// ChunkPool is safe to be accessed concurrently from multiple goroutines, and the arena is only
+// accessed while holding the pool lock, so it needs not be concurrent-safe itself. The arena must
+// not be reset while any chunk obtained from the pool is still in use.
+type ChunkPool struct {
+	mtx       sync.Mutex
+	a         Arena
+	chunkSize int
+	free      []*Chunk
+}
+
+// Chunk is a reference-counted byte chunk obtained from a ChunkPool.
+type Chunk struct {
+	pool *ChunkPool
+	buf  []byte
+	refs atomic.Int32
+}
+
+// NewChunkPool returns a ChunkPool handing out chunks of chunkSize bytes allocated from the arena.
+func NewChunkPool(a Arena, chunkSize int) *ChunkPool {
+	return &ChunkPool{a: a, chunkSize: chunkSize}
+}
+
+// Get returns a chunk holding a single reference, reusing a released one if available.
+// Chunks are not cleared when recycled, so they may hold data written by previous holders.
+func (p *ChunkPool) Get() *Chunk {
+	p.mtx.Lock()
+	var c *Chunk
+	if n := len(p.free); n > 0 {
+		c = p.free[n-1]
+		p.free[n-1] = nil
+		p.free = p.free[:n-1]
+	} else {
+		c = &Chunk{pool: p, buf: MakeSlice[byte](p.a, p.chunkSize, p.chunkSize)}
+	}
+	p.mtx.Unlock()
+
+	c.refs.Store(1)
+	return c
+}
+
+// Reset drops every chunk that has been released back to the pool, so that the arena can
+// be reset afterwards, provided that no other chunk is still in use.
+func (p *ChunkPool) Reset() {
+	p.mtx.Lock()
+	clear(p.free)
+	p.free = p.free[:0]
+	p.mtx.Unlock()
+}
+
+func (p *ChunkPool) put(c *Chunk) {
+	if debugEnabled {
+		poison(c.buf)
+	}
+	p.mtx.Lock()
+	p.free = append(p.free, c)
+	p.mtx.Unlock()
+}
+
+// Bytes returns the memory of the chunk, which must not be accessed once the caller
+// has released its reference.
+func (c *Chunk) Bytes() []byte {
+	return c.buf
+}
+
+// Retain adds a reference to the chunk, which must be released separately.
+func (c *Chunk) Retain() {
+	if c.refs.Add(1) <= 1 {
+		panic("nuke: retain of a released chunk")
+	}
+}
+
+// Release drops a reference to the chunk, returning it to its pool once no reference is left.
+// In nuke_debug builds the memory of recycled chunks is poisoned (see IsPoisoned).
+func (c *Chunk) Release() {
+	switch refs := c.refs.Add(-1); {
+	case refs == 0:
+		c.pool.put(c)
+	case refs < 0:
+		panic("nuke: release of a released chunk")
+	}
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkPool(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(4096, 1)
+	pool := NewChunkPool(arena, 512)
+
+	c := pool.Get()
+	require.Len(t, c.Bytes(), 512)
+
+	// Chunks are recycled once every reference has been released.
+	c.Retain()
+	c.Release()
+	require.NotSame(t, c, pool.Get())
+
+	c.Release()
+	require.Same(t, c, pool.Get())
+
+	require.Equal(t, uint64(2), arenaMetrics(arena).Allocs)
+
+	c.Release()
+	require.Panics(t, func() { c.Release() })
+	require.Panics(t, func() { c.Retain() })
+
+	pool.Reset()
+	require.NotSame(t, c, pool.Get())
+}
+
+func TestChunkPoolConcurrentWriters(t *testing.T) {
+	pool := NewChunkPool(NewMonotonicArena(64*1024, 1), 1024)
+
+	for i := 0; i < 100; i++ {
+		c := pool.Get()
+
+		var wg sync.WaitGroup
+		for j := 0; j < 4; j++ {
+			c.Retain()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer c.Release()
+				_ = c.Bytes()[0]
+			}()
+		}
+		c.Release()
+		wg.Wait()
+	}
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "unsafe"
+
+// Relocatable is implemented by values able to move what they reference into another arena,
+// which lets Compact copy the live part of a long-lived arena into a fresh one.
+type Relocatable interface {
+	// Relocate replaces every arena-allocated value referenced by the receiver with a copy
+	// obtained from the Relocator, such as with RelocateValue, RelocateSlice and RelocateString.
+	Relocate(r *Relocator)
+}
+
+// Relocator copies values into the destination arena of Compact. Every value is copied only
+// once, so values referenced several times, cycles included, keep being shared by the copies.
+type Relocator struct {
+	dst   Arena
+	moved map[relocKey]unsafe.Pointer
+}
+
+type relocKey struct {
+	ptr unsafe.Pointer
+	len int
+}
+
+// Compact copies the values reachable from the roots into dst, by calling their Relocate methods,
+// which are expected to update them in place to reference the copies. Roots usually live outside
+// of the arena being compacted, such as in the structure holding the pointers to its contents.
+//
+// Once compacted, the values left behind are dead, and the arena they were allocated from can be
+// reset, reclaiming the space of those that had become unreachable too. Values are copied as they
+// are, so types holding pointers must only be copied into arenas created with NewGCArena.
+func Compact(dst Arena, roots ...Relocatable) {
+	r := &Relocator{dst: dst, moved: make(map[relocKey]unsafe.Pointer)}
+	for _, root := range roots {
+		root.Relocate(r)
+	}
+}
+
+// RelocateValue returns a copy of *p allocated from the destination arena, relocating
+// in turn the values it references if *T implements Relocatable. A nil p is returned as is.
+func RelocateValue[T any](r *Relocator, p *T) *T {
+	if p == nil {
+		return nil
+	}
+	key := relocKey{ptr: unsafe.Pointer(p), len: -1}
+	if q, ok := r.moved[key]; ok {
+		return (*T)(q)
+	}
+	q := New[T](r.dst)
+	*q = *p
+	r.moved[key] = unsafe.Pointer(q)
+	if rel, ok := any(q).(Relocatable); ok {
+		rel.Relocate(r)
+	}
+	return q
+}
+
+// RelocateSlice returns a copy of s allocated from the destination arena, whose capacity is
+// its length, relocating in turn the values its elements reference if *T implements Relocatable.
+func RelocateSlice[T any](r *Relocator, s []T) []T {
+	if len(s) == 0 {
+		return s[:0:0]
+	}
+	key := relocKey{ptr: unsafe.Pointer(unsafe.SliceData(s)), len: len(s)}
+	if q, ok := r.moved[key]; ok {
+		return unsafe.Slice((*T)(q), len(s))
+	}
+	s2 := MakeSlice[T](r.dst, len(s), len(s))
+	copy(s2, s)
+	r.moved[key] = unsafe.Pointer(unsafe.SliceData(s2))
+	if _, ok := any(&s2[0]).(Relocatable); ok {
+		for i := range s2 {
+			any(&s2[i]).(Relocatable).Relocate(r)
+		}
+	}
+	return s2
+}
+
+// RelocateString returns a copy of s allocated from the destination arena.
+func RelocateString(r *Relocator, s string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	key := relocKey{ptr: unsafe.Pointer(unsafe.StringData(s)), len: len(s)}
+	if q, ok := r.moved[key]; ok {
+		return unsafe.String((*byte)(q), len(s))
+	}
+	s2 := MakeString(r.dst, unsafe.Slice(unsafe.StringData(s), len(s)))
+	r.moved[key] = unsafe.Pointer(unsafe.StringData(s2))
+	return s2
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+type compactNode struct {
+	name     string
+	parent   *compactNode
+	children []*compactNode
+	tags     []compactTag
+}
+
+func (n *compactNode) Relocate(r *Relocator) {
+	n.name = RelocateString(r, n.name)
+	n.parent = RelocateValue(r, n.parent)
+	n.children = RelocateSlice(r, n.children)
+	for i, c := range n.children {
+		n.children[i] = RelocateValue(r, c)
+	}
+	n.tags = RelocateSlice(r, n.tags)
+}
+
+type compactTag struct {
+	key string
+}
+
+func (t *compactTag) Relocate(r *Relocator) {
+	t.key = RelocateString(r, t.key)
+}
+
+type compactIndex struct {
+	root *compactNode
+}
+
+func (idx *compactIndex) Relocate(r *Relocator) {
+	idx.root = RelocateValue(r, idx.root)
+}
+
+func TestCompact(t *testing.T) {
+	src := NewGCArena(1024)
+
+	newNode := func(name string, parent *compactNode) *compactNode {
+		n := New[compactNode](src)
+		n.name = MakeString(src, []byte(name))
+		n.parent = parent
+		n.tags = SliceAppend(src, n.tags, compactTag{key: MakeString(src, []byte(name+"-tag"))})
+		return n
+	}
+	root := newNode("root", nil)
+	for _, name := range []string{"a", "b"} {
+		newNode("dead", root) // unreachable
+		root.children = SliceAppend(src, root.children, newNode(name, root))
+	}
+	idx := &compactIndex{root: root}
+
+	dst := NewGCArena(1024)
+	Compact(dst, idx)
+	require.NotSame(t, root, idx.root)
+	src.Reset(true)
+
+	r := idx.root
+	require.Equal(t, "root", r.name)
+	require.Nil(t, r.parent)
+	require.Len(t, r.children, 2)
+	for i, name := range []string{"a", "b"} {
+		c := r.children[i]
+		require.Equal(t, name, c.name)
+		require.Same(t, r, c.parent) // shared values are copied once
+		require.Equal(t, []compactTag{{key: name + "-tag"}}, c.tags)
+	}
+}
+
+func TestRelocateShared(t *testing.T) {
+	dst := NewMonotonicArena(1024, 1)
+	r := &Relocator{dst: dst, moved: make(map[relocKey]unsafe.Pointer)}
+
+	s := "shared"
+	require.Equal(t, RelocateString(r, s), RelocateString(r, s))
+	require.Same(t, unsafe.StringData(RelocateString(r, s)), unsafe.StringData(RelocateString(r, s)))
+
+	b := []byte("bytes")
+	require.Same(t, unsafe.SliceData(RelocateSlice(r, b)), unsafe.SliceData(RelocateSlice(r, b)))
+	require.Same(t, unsafe.SliceData(RelocateSlice(r, b[:2])), unsafe.SliceData(RelocateSlice(r, b[:2])))
+	require.NotSame(t, unsafe.SliceData(RelocateSlice(r, b)), unsafe.SliceData(RelocateSlice(r, b[:2])))
+
+	require.Nil(t, RelocateValue[int](r, nil))
+	require.Empty(t, RelocateString(r, ""))
+	require.Empty(t, RelocateSlice[int](r, nil))
+}
@@ -3,6 +3,8 @@
 package nuke
 
 import (
+	"io"
+	"reflect"
 	"sync"
 	"unsafe"
 )
@@ -15,20 +17,116 @@ type concurrentArena struct {
 // NewConcurrentArena returns an arena that is safe to be accessed concurrently
 // from multiple goroutines.
 func NewConcurrentArena(a Arena) Arena {
+	if ta, ok := a.(typedAllocator); ok {
+		return &concurrentTypedArena{concurrentArena: concurrentArena{a: a}, ta: ta}
+	}
 	return &concurrentArena{a: a}
 }
 
 // Alloc satisfies the Arena interface.
 func (a *concurrentArena) Alloc(size, alignment uintptr) unsafe.Pointer {
 	a.mtx.Lock()
-	ptr := a.a.Alloc(size, alignment)
-	a.mtx.Unlock()
-	return ptr
+	defer a.mtx.Unlock()
+	return a.a.Alloc(size, alignment)
 }
 
 // Reset satisfies the Arena interface.
 func (a *concurrentArena) Reset(release bool) {
 	a.mtx.Lock()
+	defer a.mtx.Unlock()
 	a.a.Reset(release)
-	a.mtx.Unlock()
+}
+
+// Metrics returns the allocation metrics of the underlying arena.
+func (a *concurrentArena) Metrics() Metrics {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return arenaMetrics(a.a)
+}
+
+// SizeHistogram returns the histogram of allocation sizes of the underlying arena, if enabled.
+func (a *concurrentArena) SizeHistogram() (SizeHistogram, bool) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if r, ok := a.a.(sizeHistogramReporter); ok {
+		return r.SizeHistogram()
+	}
+	return SizeHistogram{}, false
+}
+
+// BufferUsage returns the buffer usage of the underlying arena.
+func (a *concurrentArena) BufferUsage() []BufferUsage {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if r, ok := a.a.(bufferUsageReporter); ok {
+		return r.BufferUsage()
+	}
+	return nil
+}
+
+func (a *concurrentArena) recordHeapFallback() {
+	a.mtx.Lock()
+	defer a.mtx.Unlock() // strict arenas panic
+	recordHeapFallback(a.a)
+}
+
+func (a *concurrentArena) checkCanaries() error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return CheckCanaries(a.a)
+}
+
+func (a *concurrentArena) epoch() uint64 {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if r, ok := a.a.(epochReporter); ok {
+		return r.epoch()
+	}
+	return 0
+}
+
+func (a *concurrentArena) demand() (uint64, bool) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if r, ok := a.a.(demandReporter); ok {
+		return r.demand()
+	}
+	return 0, false
+}
+
+func (a *concurrentArena) allocSites() []AllocSite {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if r, ok := a.a.(allocSiteReporter); ok {
+		return r.allocSites()
+	}
+	return nil
+}
+
+// Trim releases the memory of the unused buffers of the underlying arena, if it implements Trimmer.
+func (a *concurrentArena) Trim() {
+	if t, ok := a.a.(Trimmer); ok {
+		a.mtx.Lock()
+		t.Trim()
+		a.mtx.Unlock()
+	}
+}
+
+func (a *concurrentArena) dump(w io.Writer, hexdump bool) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return Dump(w, a.a, hexdump)
+}
+
+// concurrentTypedArena is a concurrentArena wrapping an arena that needs to know
+// the type of the values it allocates.
+type concurrentTypedArena struct {
+	concurrentArena
+	ta typedAllocator
+}
+
+func (a *concurrentTypedArena) allocTyped(typ reflect.Type, n int) unsafe.Pointer {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return a.ta.allocTyped(typ, n)
 }
@@ -2,12 +2,16 @@
 
 package nuke
 
-import "context"
+import (
+	"context"
+	"sync"
+)
 
 type contextKey int
 
 const (
 	arenaContextKey contextKey = 0
+	scopeContextKey contextKey = 1
 )
 
 // InjectContextArena returns a new context with the Arena injected into it.
@@ -22,3 +26,87 @@ func ExtractContextArena(ctx context.Context) Arena {
 	}
 	return nil
 }
+
+// namedArenaKey is the context key of an arena injected under a name.
+type namedArenaKey string
+
+// InjectNamedArena returns a new context with the Arena injected into it under the given name,
+// so that a context may carry several arenas, such as one for long-lived data and one for scratch
+// data. Named arenas are independent from the one injected with InjectContextArena.
+func InjectNamedArena(ctx context.Context, name string, a Arena) context.Context {
+	return context.WithValue(ctx, namedArenaKey(name), a)
+}
+
+// ExtractNamedArena returns the Arena injected into the context under the given name.
+func ExtractNamedArena(ctx context.Context, name string) Arena {
+	if a, ok := ctx.Value(namedArenaKey(name)).(Arena); ok {
+		return a
+	}
+	return nil
+}
+
+// WithArenaScope returns a new context with an arena taken from the pool injected into it,
+// along with a function that resets the arena and returns it to the pool, which must be called
+// once the scope's work is done. Calling it more than once has no further effect.
+//
+// Scopes nest: a scope opened within another one gets an arena of its own, which may be reset
+// without affecting the parent's, and closing a scope closes any of its nested scopes left open.
+func WithArenaScope(ctx context.Context, pool *ArenaPool) (context.Context, func()) {
+	s := &arenaScope{pool: pool, a: pool.Get()}
+	if parent, ok := ctx.Value(scopeContextKey).(*arenaScope); ok && parent.add(s) {
+		s.parent = parent
+	}
+	ctx = context.WithValue(ctx, scopeContextKey, s)
+	return InjectContextArena(ctx, s.a), s.close
+}
+
+// arenaScope is the scope of an arena taken from a pool by WithArenaScope.
+type arenaScope struct {
+	pool   *ArenaPool
+	a      Arena
+	parent *arenaScope
+
+	mtx      sync.Mutex
+	children map[*arenaScope]struct{}
+	closed   bool
+}
+
+// add registers a nested scope, reporting whether the scope was still open.
+func (s *arenaScope) add(child *arenaScope) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.closed {
+		return false
+	}
+	if s.children == nil {
+		s.children = make(map[*arenaScope]struct{})
+	}
+	s.children[child] = struct{}{}
+	return true
+}
+
+func (s *arenaScope) remove(child *arenaScope) {
+	s.mtx.Lock()
+	delete(s.children, child)
+	s.mtx.Unlock()
+}
+
+func (s *arenaScope) close() {
+	s.mtx.Lock()
+	if s.closed {
+		s.mtx.Unlock()
+		return
+	}
+	s.closed = true
+	children := s.children
+	s.children = nil
+	s.mtx.Unlock()
+
+	for child := range children {
+		child.close()
+	}
+	if s.parent != nil {
+		s.parent.remove(s)
+	}
+	s.pool.Put(s.a)
+}
@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextArena(t *testing.T) {
+	arena := NewMonotonicArena(1024, 1)
+
+	ctx := InjectContextArena(context.Background(), arena)
+	require.Equal(t, arena, ExtractContextArena(ctx))
+	require.Nil(t, ExtractContextArena(context.Background()))
+}
+
+func TestNamedArena(t *testing.T) {
+	index := NewMonotonicArena(1024, 1)
+	scratch := NewMonotonicArena(1024, 1)
+
+	ctx := InjectNamedArena(context.Background(), "index", index)
+	ctx = InjectNamedArena(ctx, "scratch", scratch)
+	require.Same(t, index, ExtractNamedArena(ctx, "index"))
+	require.Same(t, scratch, ExtractNamedArena(ctx, "scratch"))
+	require.Nil(t, ExtractNamedArena(ctx, "other"))
+	require.Nil(t, ExtractContextArena(ctx))
+
+	// Named arenas don't clash with the unnamed one.
+	ctx = InjectContextArena(ctx, scratch)
+	require.Same(t, index, ExtractNamedArena(ctx, "index"))
+}
+
+func TestWithArenaScope(t *testing.T) {
+	pool := NewArenaPool(func() Arena {
+		return NewMonotonicArena(1024, 1)
+	})
+
+	ctx, closeScope := WithArenaScope(context.Background(), pool)
+	parent := ExtractContextArena(ctx)
+	require.NotNil(t, parent)
+
+	childCtx, closeChild := WithArenaScope(ctx, pool)
+	child := ExtractContextArena(childCtx)
+	require.NotSame(t, parent, child)
+
+	New[int](child)
+	closeChild()
+	require.Equal(t, uint64(1), arenaMetrics(child).Resets)
+	require.Zero(t, arenaMetrics(parent).Resets)
+
+	// Closing the parent scope closes the nested scopes left open.
+	grandchildCtx, _ := WithArenaScope(ctx, pool)
+	grandchild := ExtractContextArena(grandchildCtx)
+	resets := arenaMetrics(grandchild).Resets // the pool may hand out the child arena again
+	closeScope()
+	closeScope()
+	require.Equal(t, uint64(1), arenaMetrics(parent).Resets)
+	require.Equal(t, resets+1, arenaMetrics(grandchild).Resets)
+
+	// Scopes opened within closed ones are on their own.
+	_, closeOrphan := WithArenaScope(ctx, pool)
+	closeOrphan()
+}
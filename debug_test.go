@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build nuke_debug && !tinygo
+
+package nuke
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonotonicArenaDetectsConcurrentAccess(t *testing.T) {
+	arena := NewMonotonicArena(1024, 1).(*monotonicArena)
+
+	// Simulate another goroutine being in the middle of an allocation.
+	arena.guard.enter()
+	require.Panics(t, func() { _ = New[int](arena) })
+	require.Panics(t, func() { arena.Reset(false) })
+
+	arena.guard.exit()
+	require.NotPanics(t, func() { _ = New[int](arena) })
+}
+
+func TestConcurrentArenaSerializesAccess(t *testing.T) {
+	arena := NewConcurrentArena(NewMonotonicArena(1024*1024, 1))
+
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			for j := 0; j < 1_000; j++ {
+				_ = New[int](arena)
+			}
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+}
+
+func TestAllocSites(t *testing.T) {
+	arena := NewConcurrentArena(NewMonotonicArena(1024*1024, 1))
+
+	allocInts := func() {
+		for i := 0; i < 10; i++ {
+			_ = New[int](arena)
+		}
+	}
+	allocInts()
+	_ = MakeSlice[byte](arena, 0, 1024)
+
+	sites := AllocSites(arena, 10)
+	require.Len(t, sites, 2)
+
+	require.Equal(t, "github.com/ortuman/nuke.TestAllocSites", sites[0].Function)
+	require.Equal(t, uint64(1), sites[0].Allocs)
+	require.Equal(t, uint64(1024), sites[0].Bytes)
+
+	require.Equal(t, "github.com/ortuman/nuke.TestAllocSites.func1", sites[1].Function)
+	require.Equal(t, uint64(10), sites[1].Allocs)
+	require.Equal(t, uint64(80), sites[1].Bytes)
+
+	require.Len(t, AllocSites(arena, 1), 1)
+
+	arena.Reset(false)
+	require.Empty(t, AllocSites(arena, 10))
+}
+
+func TestPointerCheck(t *testing.T) {
+	arena := NewMonotonicArena(1024, 1)
+
+	require.NotPanics(t, func() { _ = New[noScanObject](arena) })
+	require.NotPanics(t, func() { _ = MakeSlice[[4]int](arena, 0, 10) })
+	require.NotPanics(t, func() { _ = New[string](nil) })
+
+	require.PanicsWithValue(t,
+		"nuke: string holds pointers and can't be allocated from an arena not scanned by the garbage collector (see NewGCArena)",
+		func() { _ = New[string](arena) },
+	)
+	require.Panics(t, func() { _ = MakeSlice[[]int](arena, 0, 10) })
+	require.Panics(t, func() { _ = SliceAppend[*int](arena, nil, nil) })
+	require.Panics(t, func() { _ = NewTypedArena[map[int]int](arena) })
+
+	// GC arenas are fine.
+	gc := NewConcurrentArena(NewGCArena(1024))
+	require.NotPanics(t, func() { _ = New[string](gc) })
+	require.NotPanics(t, func() { _ = NewTypedArena[map[int]int](gc).New() })
+}
+
+func TestPointerCheckThroughWrappers(t *testing.T) {
+	scavenging := NewScavengingArena(NewMonotonicArena(1024, 1), time.Hour)
+	defer scavenging.Close()
+	flip := NewFlipArena(NewMonotonicArena(1024, 1), NewMonotonicArena(1024, 1))
+
+	for _, arena := range []Arena{scavenging, flip} {
+		require.NotPanics(t, func() { _ = New[noScanObject](arena) })
+		require.Panics(t, func() { _ = New[*int](arena) })
+		require.Panics(t, func() { _ = MakeSlice[string](arena, 0, 10) })
+		require.Panics(t, func() { _ = NewTypedArena[map[int]int](arena).New() })
+	}
+
+	// Wrappers over GC arenas are fine.
+	gcScavenging := NewScavengingArena(NewGCArena(1024), time.Hour)
+	defer gcScavenging.Close()
+	gcFlip := NewFlipArena(NewGCArena(1024), NewGCArena(1024))
+	for _, arena := range []Arena{gcScavenging, gcFlip} {
+		require.NotPanics(t, func() { _ = New[*int](arena) })
+		require.NotPanics(t, func() { _ = NewTypedArena[map[int]int](arena).New() })
+	}
+}
+
+func TestMonotonicArenaPoisonsMemoryOnReset(t *testing.T) {
+	if asanEnabled {
+		t.Skip("reading reset memory is reported by the address sanitizer")
+	}
+	arena := NewMonotonicArena(1024, 1)
+
+	i := New[int](arena)
+	*i = 42
+	s := MakeSlice[int32](arena, 4, 4)
+
+	require.False(t, IsPoisoned(i))
+	require.NotPanics(t, func() { AssertNotPoisoned(i) })
+
+	arena.Reset(false)
+	require.True(t, IsPoisoned(i))
+	require.True(t, IsPoisoned((*[4]int32)(s)))
+	require.Panics(t, func() { AssertNotPoisoned(i) })
+
+	// Memory is cleared when reused.
+	i2 := New[int](arena)
+	require.Equal(t, 0, *i2)
+
+	// Released memory is poisoned as well.
+	arena.Reset(true)
+	require.True(t, IsPoisoned(i2))
+}
+
+func TestPtrPanicsAfterReset(t *testing.T) {
+	for name, arena := range map[string]Arena{
+		"monotonic":  NewMonotonicArena(1024, 1),
+		"concurrent": NewConcurrentArena(NewMonotonicArena(1024, 1)),
+		"gc":         NewGCArena(1024),
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := NewPtr[int](arena)
+			require.NotPanics(t, func() { _ = p.Get() })
+
+			arena.Reset(false)
+			require.Panics(t, func() { _ = p.Get() })
+		})
+	}
+}
+
+func TestMonotonicArenaCanaries(t *testing.T) {
+	if asanEnabled {
+		t.Skip("writes past the end of allocations are reported by the address sanitizer")
+	}
+	arena := NewConcurrentArena(NewMonotonicArena(1024, 1, WithCanaries()))
+
+	_ = New[int64](arena)
+	s := MakeSlice[byte](arena, 16, 16)
+	require.NoError(t, CheckCanaries(arena))
+
+	// Write one byte past the end of the slice.
+	unsafe.Slice(unsafe.SliceData(s), 17)[16] = 1
+
+	err := CheckCanaries(arena)
+	var cerr *CanaryError
+	require.ErrorAs(t, err, &cerr)
+	require.Equal(t, uintptr(16), cerr.Size)
+	require.Equal(t, "github.com/ortuman/nuke.TestMonotonicArenaCanaries", cerr.Site.Function)
+
+	require.Panics(t, func() { arena.Reset(false) })
+}
+
+func TestRefPanicsOnInvalidAccess(t *testing.T) {
+	arena := NewMonotonicArena(1024, 1)
+
+	var zero Ref[int]
+	require.Panics(t, func() { zero.Store(1) })
+
+	r := Alloc[int](arena)
+	r.Store(1)
+
+	arena.Reset(false)
+	require.Panics(t, func() { _ = r.Load() })
+}
+
+func TestFreeVerifiesOwnership(t *testing.T) {
+	arena := NewConcurrentArena(NewMonotonicArena(1024, 1))
+
+	x := New[[4]uint64](arena)
+	y := New[[4]uint64](arena)
+	require.False(t, Free(arena, x))
+	require.True(t, Free(arena, y))
+
+	// Freeing twice panics, whether the memory was reclaimed or not.
+	for _, p := range []*[4]uint64{x, y} {
+		require.Contains(t, panicValue(func() { Free(arena, p) }), "nuke: double free of the 32 byte allocation")
+		require.Contains(t, panicValue(func() { Free(arena, p) }), "TestFreeVerifiesOwnership")
+	}
+
+	// Reused memory can be freed again.
+	z := New[[4]uint64](arena)
+	require.Equal(t, y, z)
+	require.True(t, Free(arena, z))
+
+	// So does freeing pointers into the arena that aren't allocations of it, or with another size.
+	s := MakeSlice[uint64](arena, 4, 4)
+	require.Contains(t, panicValue(func() { Free(arena, &s[1]) }), "isn't an allocation of the arena")
+	require.Contains(t, panicValue(func() { FreeSlice(arena, s[:2:2]) }), "nuke: free of 16 bytes")
+
+	// Pointers outside of the arena may have been served from the heap, and aren't reclaimed.
+	require.False(t, Free(arena, new([4]uint64)))
+
+	// Nor do allocations made before a reset.
+	arena.Reset(false)
+	require.Contains(t, panicValue(func() { FreeSlice(arena, s) }), "isn't an allocation of the arena")
+}
+
+func panicValue(fn func()) (v string) {
+	defer func() { v = fmt.Sprint(recover()) }()
+	fn()
+	return ""
+}
+
+// misalignedArena is an Arena implementation returning memory that is never aligned.
+type misalignedArena struct{}
+
+func (misalignedArena) Alloc(size, alignment uintptr) unsafe.Pointer {
+	ptr := unsafe.Pointer(unsafe.SliceData(make([]byte, size+alignment)))
+	return unsafe.Add(alignPtr(ptr, alignment), 1)
+}
+
+func (misalignedArena) Reset(bool) {}
+
+func TestMisalignedArenaPanics(t *testing.T) {
+	require.Panics(t, func() { _ = New[int64](misalignedArena{}) })
+	require.Panics(t, func() { _ = MakeSlice[int32](misalignedArena{}, 1, 1) })
+	require.NotPanics(t, func() { _ = MakeSlice[byte](misalignedArena{}, 1, 1) })
+}
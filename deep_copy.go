@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// CopyIntoArena returns a deep copy of *src allocated from the arena. Strings, slices and pointers
+// reachable from src are copied recursively into the arena, through struct fields, exported or not,
+// and array and slice elements. Values referenced several times, cycles included, are copied only
+// once, so they keep being shared by the copies, and slices are copied up to their length.
+// Maps, channels, functions, interfaces and unsafe pointers are copied as they are, so the copy keeps
+// referencing the same values. A nil src is returned as is.
+//
+// The copy is allocated as with New, MakeSlice and MakeString, which send what the arena can't serve
+// to the heap, so types holding pointers must only be copied into arenas created with NewGCArena.
+func CopyIntoArena[T any](a Arena, src *T) *T {
+	if src == nil {
+		return nil
+	}
+	c := deepCopier{a: a, copied: make(map[copyKey]reflect.Value)}
+	return c.copyPointer(reflect.ValueOf(src)).Interface().(*T)
+}
+
+type deepCopier struct {
+	a      Arena
+	copied map[copyKey]reflect.Value
+}
+
+type copyKey struct {
+	relocKey
+	typ reflect.Type
+}
+
+// copyPointer returns a pointer to a copy of the value p points to.
+func (c *deepCopier) copyPointer(p reflect.Value) reflect.Value {
+	key := copyKey{relocKey: relocKey{ptr: p.UnsafePointer(), len: -1}, typ: p.Type()}
+	if q, ok := c.copied[key]; ok {
+		return q
+	}
+	q := NewOf(c.a, p.Type().Elem())
+	c.copied[key] = q
+	q.Elem().Set(p.Elem())
+	c.copyReferences(q.Elem())
+	return q
+}
+
+// copyReferences replaces the strings, slices and pointers reachable from v, which must be
+// addressable, with copies.
+func (c *deepCopier) copyReferences(v reflect.Value) {
+	if !hasPointers(v.Type()) {
+		return
+	}
+	if !v.CanSet() {
+		// Values obtained through unexported struct fields can't be set, nor copied.
+		v = reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() > 0 {
+			v.Set(c.copyString(v))
+		}
+
+	case reflect.Slice:
+		if !v.IsNil() {
+			v.Set(c.copySlice(v))
+		}
+
+	case reflect.Pointer:
+		if !v.IsNil() {
+			v.Set(c.copyPointer(v))
+		}
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.copyReferences(v.Index(i))
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			c.copyReferences(v.Field(i))
+		}
+	}
+}
+
+func (c *deepCopier) copyString(v reflect.Value) reflect.Value {
+	s := v.String()
+	key := copyKey{relocKey: relocKey{ptr: unsafe.Pointer(unsafe.StringData(s)), len: len(s)}, typ: v.Type()}
+	if q, ok := c.copied[key]; ok {
+		return q
+	}
+	q := reflect.New(v.Type()).Elem()
+	q.SetString(MakeString(c.a, unsafe.Slice(unsafe.StringData(s), len(s))))
+	c.copied[key] = q
+	return q
+}
+
+func (c *deepCopier) copySlice(v reflect.Value) reflect.Value {
+	key := copyKey{relocKey: relocKey{ptr: v.UnsafePointer(), len: v.Len()}, typ: v.Type()}
+	if q, ok := c.copied[key]; ok {
+		return q
+	}
+	q := MakeSliceOf(c.a, v.Type(), v.Len(), v.Len())
+	c.copied[key] = q
+	reflect.Copy(q, v)
+	for i := 0; i < q.Len(); i++ {
+		c.copyReferences(q.Index(i))
+	}
+	return q
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+type copyNode struct {
+	Name     string
+	tags     []string
+	Children []*copyNode
+	parent   *copyNode
+	Attrs    map[string]int
+	coords   [2][]float64
+}
+
+func TestCopyIntoArena(t *testing.T) {
+	root := &copyNode{Name: "root", tags: []string{"a", "b"}, Attrs: map[string]int{"x": 1}}
+	child := &copyNode{Name: "child", parent: root, coords: [2][]float64{{1, 2}, nil}}
+	root.Children = []*copyNode{child, child}
+
+	arena := NewGCArena(1024)
+	c := CopyIntoArena(arena, root)
+	require.Equal(t, root, c)
+	require.NotSame(t, root, c)
+
+	// Everything but the map has been copied, sharing the copies of shared values.
+	require.NotSame(t, unsafe.StringData(root.Name), unsafe.StringData(c.Name))
+	require.NotSame(t, &root.tags[0], &c.tags[0])
+	require.NotSame(t, unsafe.StringData(root.tags[1]), unsafe.StringData(c.tags[1]))
+	require.NotSame(t, child, c.Children[0])
+	require.Same(t, c.Children[0], c.Children[1])
+	require.Same(t, c, c.Children[0].parent)
+	require.NotSame(t, &child.coords[0][0], &c.Children[0].coords[0][0])
+	require.Nil(t, c.Children[0].coords[1])
+	c.Attrs["y"] = 2
+	require.Equal(t, 2, root.Attrs["y"])
+
+	// Mutating the copy leaves the original untouched.
+	c.Children[0].coords[0][0] = 3
+	require.Equal(t, 1.0, child.coords[0][0])
+
+	require.Nil(t, CopyIntoArena[copyNode](arena, nil))
+}
+
+func TestCopyIntoArenaPointerFree(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	type point struct{ X, Y int }
+	arena := NewMonotonicArena(1024, 1)
+	p := &point{X: 1, Y: 2}
+	c := CopyIntoArena(arena, p)
+	require.Equal(t, p, c)
+	require.Equal(t, uint64(1), arenaMetrics(arena).Allocs)
+}
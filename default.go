@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "sync/atomic"
+
+// defaultArena holds the process-wide default arena, if any.
+var defaultArena atomic.Pointer[Arena]
+
+// SetDefaultArena sets the process-wide arena NewDefault and MakeDefaultSlice allocate from,
+// for libraries that can't have an arena passed down to them. Since it may be used from any
+// goroutine, the arena must be safe to be accessed concurrently, like the ones returned by
+// NewConcurrentArena. Passing nil makes them allocate from the heap again.
+//
+// Whoever sets the default arena is in charge of resetting it, at a point where none of the
+// memory allocated from it is in use anymore.
+func SetDefaultArena(a Arena) {
+	if a == nil {
+		defaultArena.Store(nil)
+		return
+	}
+	defaultArena.Store(&a)
+}
+
+// Default returns the process-wide default arena, or nil if none has been set.
+func Default() Arena {
+	if a := defaultArena.Load(); a != nil {
+		return *a
+	}
+	return nil
+}
+
+// NewDefault allocates memory for a value of type T from the default arena,
+// or from the heap if none has been set (see SetDefaultArena).
+func NewDefault[T any]() *T {
+	return New[T](Default())
+}
+
+// MakeDefaultSlice creates a slice of type T with the given length and capacity from the
+// default arena, or from the heap if none has been set (see SetDefaultArena).
+func MakeDefaultSlice[T any](len, cap int) []T {
+	return MakeSlice[T](Default(), len, cap)
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultArena(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	require.Nil(t, Default())
+
+	a := NewConcurrentArena(NewMonotonicArena(1024, 1))
+	SetDefaultArena(a)
+	defer SetDefaultArena(nil)
+	require.Same(t, a, Default())
+
+	*NewDefault[int]() = 42
+	s := MakeDefaultSlice[byte](10, 20)
+	require.Len(t, s, 10)
+	require.Equal(t, uint64(2), arenaMetrics(a).Allocs)
+
+	// New with a nil arena still allocates from the heap.
+	New[int](nil)
+	require.Equal(t, uint64(2), arenaMetrics(a).Allocs)
+
+	SetDefaultArena(nil)
+	require.Nil(t, Default())
+	NewDefault[int]()
+	require.Equal(t, uint64(2), arenaMetrics(a).Allocs)
+}
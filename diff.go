@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "bytes"
+
+// Range is a range of offsets of arena memory, as returned by OffsetOf.
+type Range struct {
+	Off uint64 `json:"off"`
+	Len uint64 `json:"len"`
+}
+
+// Diff returns the ranges of cur that differ from prev, two snapshots of the same arena as
+// returned by Snapshot, compared in chunks of chunkSize bytes, so that only the changed chunks
+// are shipped to replicas holding prev. Adjacent changed chunks are merged into a single range,
+// and the bytes of cur beyond the length of prev are always part of the last range. Replicas
+// apply the ranges with Patch, truncating prev to the length of cur if it's shorter.
+//
+// Arenas only grow between resets, so diffing the snapshots of consecutive generations of a
+// FlipArena, or of an arena since a Mark, yields the ranges written to since.
+func Diff(prev, cur []byte, chunkSize int) []Range {
+	if chunkSize <= 0 {
+		chunkSize = 1
+	}
+	var ranges []Range
+	n := min(len(prev), len(cur))
+	for off := 0; off < n; off += chunkSize {
+		end := min(off+chunkSize, n)
+		if !bytes.Equal(prev[off:end], cur[off:end]) {
+			ranges = appendRange(ranges, uint64(off), uint64(end-off))
+		}
+	}
+	if len(cur) > n {
+		ranges = appendRange(ranges, uint64(n), uint64(len(cur)-n))
+	}
+	return ranges
+}
+
+// Patch copies the given ranges of cur into dst, resized to the length of cur, and returns it.
+func Patch(dst, cur []byte, ranges []Range) []byte {
+	if len(dst) > len(cur) {
+		dst = dst[:len(cur)]
+	} else {
+		dst = append(dst, make([]byte, len(cur)-len(dst))...)
+	}
+	for _, r := range ranges {
+		copy(dst[r.Off:r.Off+r.Len], cur[r.Off:r.Off+r.Len])
+	}
+	return dst
+}
+
+func appendRange(ranges []Range, off, n uint64) []Range {
+	if last := len(ranges) - 1; last >= 0 && ranges[last].Off+ranges[last].Len == off {
+		ranges[last].Len += n
+		return ranges
+	}
+	return append(ranges, Range{Off: off, Len: n})
+}
+
+// Mark keeps track of the memory of an arena written to since a point in time, in chunks of
+// a fixed size. It holds a copy of the memory of the arena as of the mark, so it's meant for
+// arenas whose contents are replicated, for which a copy is held by every replica anyway.
+type Mark struct {
+	a         Arena
+	chunkSize int
+	snap      []byte
+}
+
+// NewMark returns a Mark of the current state of the arena, which must implement Snapshotter.
+func NewMark(a Arena, chunkSize int) *Mark {
+	return &Mark{a: a, chunkSize: chunkSize, snap: Snapshot(a)}
+}
+
+// Dirty returns the ranges of the arena memory written to since the mark (see Diff).
+func (m *Mark) Dirty() []Range {
+	return Diff(m.snap, Snapshot(m.a), m.chunkSize)
+}
+
+// Advance returns the ranges of the arena memory written to since the mark, along with a copy
+// of the current memory of the arena they refer to, and moves the mark to the current state.
+func (m *Mark) Advance() ([]Range, []byte) {
+	cur := Snapshot(m.a)
+	ranges := Diff(m.snap, cur, m.chunkSize)
+	m.snap = Patch(m.snap, cur, ranges)
+	return ranges, cur
+}
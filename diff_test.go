@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	prev := []byte("aaaabbbbccccdddd")
+	cur := []byte("aaaaBbbbcccCddddeee")
+	ranges := Diff(prev, cur, 4)
+	require.Equal(t, []Range{{Off: 4, Len: 8}, {Off: 16, Len: 3}}, ranges) // adjacent chunks are merged
+	require.Equal(t, cur, Patch(append([]byte(nil), prev...), cur, ranges))
+
+	require.Empty(t, Diff(prev, prev, 4))
+	require.Equal(t, []byte("aaaa"), Patch(append([]byte(nil), prev...), cur[:4], Diff(prev, cur[:4], 4)))
+}
+
+func TestMark(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewMonotonicArena(1024, 1)
+	x := New[[8]uint64](a)
+	New[[8]uint64](a)
+
+	m := NewMark(a, 16)
+	require.Empty(t, m.Dirty())
+
+	x[3] = 1
+	New[uint64](a)
+	require.Equal(t, []Range{{Off: 16, Len: 16}, {Off: 128, Len: 64}}, m.Dirty())
+
+	ranges, cur := m.Advance()
+	require.Len(t, ranges, 2)
+	require.Equal(t, Snapshot(a), cur)
+	require.Empty(t, m.Dirty())
+
+	replica := Patch(nil, cur, []Range{{Off: 0, Len: uint64(len(cur))}})
+	x[7] = 2
+	ranges, cur = m.Advance()
+	require.Equal(t, []Range{{Off: 48, Len: 16}}, ranges)
+	require.Equal(t, Snapshot(a), Patch(replica, cur, ranges))
+}
@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+// drainer is implemented by arenas that can stop serving allocations while keeping
+// the memory they've handed out valid.
+type drainer interface {
+	drain()
+}
+
+// Drain puts the arena in drain mode, meant for graceful shutdowns: it stops serving allocations,
+// which New, MakeSlice and SliceAppend send to the heap instead, or panic for with ErrHeapFallback
+// if the arena was created with WithStrictMode, while the memory it has handed out remains valid
+// until the next reset, which releases it. Arenas stay in drain mode for good.
+//
+// Drain reports whether the arena supports drain mode, as the arenas of this package do.
+func Drain(a Arena) bool {
+	if d, ok := a.(drainer); ok {
+		d.drain()
+		return true
+	}
+	return false
+}
+
+func (a *monotonicArena) drain() {
+	if debugEnabled {
+		a.guard.enter()
+		defer a.guard.exit()
+	}
+	a.drained = true
+}
+
+func (a *gcArena) drain() {
+	a.drained = true
+	a.bytes.drain()
+}
+
+func (a *concurrentArena) drain() {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	Drain(a.a)
+}
+
+func (a *ScavengingArena) drain() {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	Drain(a.a)
+}
+
+func (a *FlipArena) drain() {
+	Drain(a.arenas[0])
+	Drain(a.arenas[1])
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewConcurrentArena(NewMonotonicArena(1024, 1))
+
+	x := New[int](a)
+	*x = 42
+	require.True(t, Drain(a))
+
+	// New allocations go to the heap, while the existing ones remain valid.
+	New[int](a)
+	require.Equal(t, uint64(1), arenaMetrics(a).HeapFallbacks)
+	require.Equal(t, 42, *x)
+
+	// The next reset releases the arena memory.
+	a.Reset(false)
+	require.Zero(t, heldBuffers(a.(*concurrentArena).a))
+	New[int](a)
+	require.Equal(t, uint64(2), arenaMetrics(a).HeapFallbacks)
+}
+
+func TestDrainStrict(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewMonotonicArena(1024, 1, WithStrictMode())
+	Drain(a)
+	require.PanicsWithValue(t, ErrHeapFallback, func() { New[int](a) })
+}
+
+func TestDrainGCArena(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewGCArena(1024)
+	require.True(t, Drain(a))
+
+	s := New[string](a)
+	*s = "heap"
+	MakeSlice[byte](a, 8, 8)
+	require.Equal(t, uint64(2), arenaMetrics(a).HeapFallbacks)
+	require.Zero(t, arenaMetrics(a).Allocs)
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+)
+
+// ErrDumpNotSupported is returned by Dump when the arena can't be dumped.
+var ErrDumpNotSupported = errors.New("nuke: arena doesn't support dumping")
+
+// Dump writes a human-readable description of the provided Arena layout to w, including
+// every buffer along with its usage since the last reset. If hexdump is true, the used
+// region of every buffer is hex dumped as well, except when built with the address sanitizer.
+// When the package is built with the nuke_debug build tag, the top allocation sites are included too.
+func Dump(w io.Writer, a Arena, hexdump bool) error {
+	d, ok := a.(dumper)
+	if !ok {
+		return ErrDumpNotSupported
+	}
+	return d.dump(w, hexdump)
+}
+
+// dumper is implemented by arenas that can be dumped.
+type dumper interface {
+	dump(w io.Writer, hexdump bool) error
+}
+
+func (a *monotonicArena) dump(w io.Writer, hexdump bool) error {
+	ew := &errWriter{w: w}
+
+	ew.printf("monotonic arena: %d buffers, cursor at %d\n", len(a.buffers), a.cursor)
+	for i, s := range a.buffers {
+		if s.ptr == nil {
+			ew.printf("buffer %d: size %d, not allocated\n", i, s.size)
+			continue
+		}
+		ew.printf("buffer %d: address %#x, size %d, used %d, padding %d, stranded %d\n",
+			i, uintptr(s.ptr), s.size, s.offset, s.padding, s.stranded,
+		)
+		if hexdump && asanEnabled {
+			// Padding bytes are poisoned, and reading them would be reported by the sanitizer.
+			ew.printf("hex dump not available in address sanitizer builds\n")
+		} else if hexdump && s.offset > 0 {
+			d := hex.Dumper(ew)
+			_, _ = d.Write(unsafe.Slice((*byte)(s.ptr), s.offset))
+			_ = d.Close()
+		}
+	}
+	if a.tiny != nil {
+		ew.printf("tiny block: address %#x, used %d\n", uintptr(a.tiny), a.tinyOffset)
+	}
+	if debugEnabled {
+		ew.printf("top allocation sites:\n")
+		for _, site := range AllocSites(a, 10) {
+			ew.printf("  %s (%s:%d): %d allocs, %d bytes\n", site.Function, site.File, site.Line, site.Allocs, site.Bytes)
+		}
+	}
+	return ew.err
+}
+
+// errWriter is an io.Writer that stops writing after the first error.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.w.Write(p)
+	ew.err = err
+	return n, err
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(ew, format, args...)
+}
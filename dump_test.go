@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewConcurrentArena(NewMonotonicArena(64, 2))
+
+	s := MakeSlice[byte](arena, 20, 20)
+	copy(s, "nuke dump test")
+
+	var buf bytes.Buffer
+	require.NoError(t, Dump(&buf, arena, true))
+
+	out := buf.String()
+	require.Contains(t, out, "monotonic arena: 2 buffers, cursor at 0")
+	require.Contains(t, out, "size 64, used 20, padding 0, stranded 0")
+	require.Contains(t, out, "buffer 1: size 64, not allocated")
+	if !asanEnabled {
+		require.Contains(t, out, "|nuke dump test")
+	}
+}
+
+func TestDumpNotSupported(t *testing.T) {
+	require.ErrorIs(t, Dump(&bytes.Buffer{}, &mockArena{}, false), ErrDumpNotSupported)
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// escapeGCCycles is the number of GC cycles a sampled allocation must survive after being
+// released by a reset before being reported as escaped.
+const escapeGCCycles = 3
+
+// escapeMinSize is the minimum size of the memory backing a sampled allocation,
+// as objects served by the runtime tiny allocator may never be finalized.
+const escapeMinSize = 16
+
+// Escape describes an arena allocation that is still referenced after the arena has been reset.
+type Escape struct {
+	// Size is the size of the escaped allocation.
+	Size uintptr
+
+	// Stack is the call stack the allocation was made from.
+	Stack string
+}
+
+// WithEscapeSampling makes the arena check whether one out of every rate allocations outlives
+// the next Reset, reporting to fn those that are still referenced a few GC cycles afterwards.
+// Sampled allocations are served from memory of their own, watched by a finalizer, which makes
+// the check cheap enough to be enabled in production with a low sampling rate.
+// The handler is run from a finalizer, so it must not block.
+func WithEscapeSampling(rate int, fn func(Escape)) Option {
+	return func(o *options) {
+		o.escapeSamplingRate = rate
+		o.escapeHandler = fn
+	}
+}
+
+// escapeSampler picks the allocations to be watched for escapes.
+type escapeSampler struct {
+	rate      int
+	countdown int
+	handler   func(Escape)
+	watched   []*escapeRecord
+}
+
+type escapeRecord struct {
+	size     uintptr
+	stack    allocStack
+	handler  func(Escape)
+	released uint64 // GC cycle the allocation was released at
+	freed    atomic.Bool
+}
+
+// sample reports whether the next allocation has to be watched.
+func (s *escapeSampler) sample() bool {
+	if s.rate <= 0 {
+		return false
+	}
+	if s.countdown--; s.countdown > 0 {
+		return false
+	}
+	s.countdown = s.rate
+	return true
+}
+
+// watch starts watching the allocation of the given size held by buf. The skip parameter
+// has the same meaning as in runtime.Callers, with 0 identifying the caller of watch.
+func (s *escapeSampler) watch(buf []byte, size uintptr, skip int) {
+	r := &escapeRecord{size: size, stack: callers(skip + 1), handler: s.handler}
+	runtime.SetFinalizer(unsafe.SliceData(buf), func(*byte) { r.freed.Store(true) })
+	s.watched = append(s.watched, r)
+}
+
+// release hands the watched allocations over to the escape detector, since the arena
+// no longer references them.
+func (s *escapeSampler) release() {
+	if len(s.watched) == 0 {
+		return
+	}
+	detector.add(s.watched)
+	clear(s.watched)
+	s.watched = s.watched[:0]
+}
+
+// escapeDetector keeps track of the released allocations of every arena,
+// checking them on every GC cycle.
+type escapeDetector struct {
+	mtx      sync.Mutex
+	cycle    uint64
+	pending  []*escapeRecord
+	sentinel bool
+}
+
+var detector escapeDetector
+
+func (d *escapeDetector) add(records []*escapeRecord) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	for _, r := range records {
+		r.released = d.cycle
+		d.pending = append(d.pending, r)
+	}
+	if !d.sentinel {
+		d.sentinel = true
+		d.armSentinel()
+	}
+}
+
+// gcSentinel is an object whose finalizer runs once per GC cycle.
+type gcSentinel struct {
+	_ *byte // keeps it out of the tiny allocator
+}
+
+func (d *escapeDetector) armSentinel() {
+	runtime.SetFinalizer(&gcSentinel{}, func(*gcSentinel) { d.onGC() })
+}
+
+func (d *escapeDetector) onGC() {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	d.cycle++
+	pending := d.pending[:0]
+	for _, r := range d.pending {
+		switch {
+		case r.freed.Load():
+		case d.cycle-r.released >= escapeGCCycles:
+			r.handler(Escape{Size: r.size, Stack: formatStack(r.stack)})
+		default:
+			pending = append(pending, r)
+		}
+	}
+	clear(d.pending[len(pending):])
+	d.pending = pending
+
+	if len(d.pending) > 0 {
+		d.armSentinel()
+	} else {
+		d.sentinel = false
+	}
+}
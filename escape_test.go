@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeSampling(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	if !finalizersEnabled {
+		t.Skip("finalizers aren't run")
+	}
+	var mtx sync.Mutex
+	var escapes []Escape
+
+	arena := NewMonotonicArena(1024, 1, WithEscapeSampling(1, func(e Escape) {
+		mtx.Lock()
+		escapes = append(escapes, e)
+		mtx.Unlock()
+	}))
+
+	escaped := New[int64](arena)
+	_ = MakeSlice[byte](arena, 32, 32) // doesn't outlive the reset
+	arena.Reset(false)
+
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		mtx.Lock()
+		defer mtx.Unlock()
+		return len(escapes) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Give the released allocation a chance to be reported too.
+	for i := 0; i < 2*escapeGCCycles; i++ {
+		runtime.GC()
+	}
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	require.Len(t, escapes, 1)
+	require.Equal(t, uintptr(8), escapes[0].Size)
+	require.Contains(t, escapes[0].Stack, "nuke.TestEscapeSampling")
+
+	runtime.KeepAlive(escaped)
+}
+
+func TestEscapeSamplingRate(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(1024, 1, WithEscapeSampling(4, func(Escape) {})).(*monotonicArena)
+
+	for i := 0; i < 8; i++ {
+		_ = New[int64](arena)
+	}
+	require.Len(t, arena.escapes.watched, 2)
+
+	arena.Reset(false)
+	require.Empty(t, arena.escapes.watched)
+}
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+
+package nuke
+
+import "expvar"
+
+// PublishExpvar exposes the metrics of the provided Arena as an expvar variable with the given name,
+// making them available under /debug/vars. Since metrics are read from the goroutine serving the
+// expvar request, the arena must be safe to be accessed concurrently.
+// Like expvar.Publish, it panics if the name is already in use.
+func PublishExpvar(name string, a Arena) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return arenaMetrics(a)
+	}))
+}
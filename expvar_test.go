@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+
+package nuke
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// expvarTestRuns numbers the runs of TestPublishExpvar, as variables can't be published twice.
+var expvarTestRuns atomic.Int64
+
+func TestPublishExpvar(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	name := fmt.Sprintf("%s_%d", t.Name(), expvarTestRuns.Add(1))
+	arena := NewConcurrentArena(NewMonotonicArena(1024, 1))
+	PublishExpvar(name, arena)
+
+	_ = New[int](arena)
+	_ = MakeSlice[byte](arena, 0, 2048) // sent to the heap
+
+	var m Metrics
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &m))
+	require.Equal(t, uint64(1), m.Allocs)
+	require.Equal(t, uint64(1), m.HeapFallbacks)
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// FlipArena is a double-buffered arena holding two inner arenas: allocations are served by the
+// active one, while the memory allocated from the other one before the last flip remains valid.
+// Flip swaps them, resetting the older one first, which suits aggregation windows and snapshots
+// that are published while the next ones are being built.
+//
+// FlipArena is as safe to be accessed concurrently as its inner arenas, while Flip must not be
+// called concurrently with allocations.
+type FlipArena struct {
+	arenas [2]Arena
+	active int
+}
+
+// NewFlipArena returns a FlipArena whose active arena is a, and b the one to flip to.
+func NewFlipArena(a, b Arena) *FlipArena {
+	return &FlipArena{arenas: [2]Arena{a, b}}
+}
+
+// Alloc satisfies the Arena interface, allocating from the active arena.
+func (a *FlipArena) Alloc(size, alignment uintptr) unsafe.Pointer {
+	return a.arenas[a.active].Alloc(size, alignment)
+}
+
+func (a *FlipArena) allocTyped(typ reflect.Type, n int) unsafe.Pointer {
+	active := a.arenas[a.active]
+	if ta, ok := active.(typedAllocator); ok {
+		return ta.allocTyped(typ, n)
+	}
+	if debugEnabled {
+		assertPointerFree(typ) // the type is only checked here, as the wrapper is a typedAllocator
+	}
+	return active.Alloc(typ.Size()*uintptr(n), uintptr(typ.Align()))
+}
+
+// Reset satisfies the Arena interface, resetting both arenas.
+func (a *FlipArena) Reset(release bool) {
+	a.arenas[0].Reset(release)
+	a.arenas[1].Reset(release)
+}
+
+// Flip resets the previous arena, invalidating the memory allocated from it,
+// and makes it the active one, while the active arena becomes the previous one.
+func (a *FlipArena) Flip() {
+	prev := 1 - a.active
+	a.arenas[prev].Reset(false)
+	a.active = prev
+}
+
+// Active returns the arena allocations are currently served by.
+func (a *FlipArena) Active() Arena {
+	return a.arenas[a.active]
+}
+
+// Previous returns the arena that was active before the last flip.
+func (a *FlipArena) Previous() Arena {
+	return a.arenas[1-a.active]
+}
+
+// Metrics returns the allocation metrics of the active arena.
+func (a *FlipArena) Metrics() Metrics {
+	return arenaMetrics(a.arenas[a.active])
+}
+
+func (a *FlipArena) recordHeapFallback() {
+	recordHeapFallback(a.arenas[a.active])
+}
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlipArena(t *testing.T) {
+	first, second := NewMonotonicArena(1024, 1), NewMonotonicArena(1024, 1)
+	a := NewFlipArena(first, second)
+	require.Same(t, first, a.Active())
+
+	x := New[int](a)
+	*x = 1
+	a.Flip()
+	require.Same(t, second, a.Active())
+	require.Same(t, first, a.Previous())
+
+	// Memory from before the flip remains valid while the next window is built.
+	y := New[int](a)
+	*y = 2
+	require.Equal(t, 1, *x)
+	require.Zero(t, arenaMetrics(first).Resets)
+
+	// Flipping again resets the oldest arena before reusing it.
+	a.Flip()
+	require.Same(t, first, a.Active())
+	require.Equal(t, uint64(1), arenaMetrics(first).Resets)
+	require.Equal(t, 2, *y)
+
+	a.Reset(false)
+	require.Equal(t, uint64(2), arenaMetrics(first).Resets)
+	require.Equal(t, uint64(2), arenaMetrics(second).Resets) // also reset by the first flip
+}
+
+func TestFlipArenaTyped(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewFlipArena(NewGCArena(1024), NewGCArena(1024))
+
+	s := New[string](a)
+	*s = "hello"
+	a.Flip()
+	require.Equal(t, "hello", *s)
+	require.Equal(t, uint64(1), a.Previous().(Stater).Metrics().Allocs)
+}
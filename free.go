@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Freer is implemented by arenas able to take back the memory of an allocation before being reset.
+type Freer interface {
+	// Free returns the memory of the allocation of the given size at ptr to the arena,
+	// reporting whether it could be reclaimed. Memory that couldn't be reclaimed stays
+	// allocated until the next reset, as any other.
+	Free(ptr unsafe.Pointer, size uintptr) bool
+}
+
+// Free returns the memory of a value allocated with New to the arena, if it implements Freer,
+// reporting whether it was reclaimed. The value must not be accessed afterwards either way.
+//
+// The arenas of this package only reclaim the memory of their most recent allocation, which makes
+// Free useful to undo a speculative allocation, such as a scratch value that turned out to be unneeded.
+//
+// Building with the nuke_debug tag makes them keep track of their allocations, and panic when
+// passed a pointer into their memory that isn't one of them, or one that has already been freed,
+// reporting where it was allocated and freed. Pointers outside of their memory aren't reported,
+// as New may have served them from the heap.
+func Free[T any](a Arena, ptr *T) bool {
+	var x T
+	return free(a, unsafe.Pointer(ptr), unsafe.Sizeof(x))
+}
+
+// FreeSlice returns the memory of the backing array of a slice allocated with MakeSlice to the arena,
+// if it implements Freer, reporting whether it was reclaimed. The slice must not be accessed afterwards
+// either way.
+func FreeSlice[T any](a Arena, s []T) bool {
+	var x T
+	return free(a, unsafe.Pointer(unsafe.SliceData(s)), unsafe.Sizeof(x)*uintptr(cap(s)))
+}
+
+func free(a Arena, ptr unsafe.Pointer, size uintptr) bool {
+	if ptr == nil || size == 0 {
+		return false
+	}
+	if f, ok := a.(Freer); ok {
+		return f.Free(ptr, size)
+	}
+	return false
+}
+
+// Free satisfies the Freer interface, reclaiming the memory of the most recent allocation
+// served from the arena buffers. Freeing any other allocation has no effect.
+func (a *monotonicArena) Free(ptr unsafe.Pointer, size uintptr) bool {
+	if debugEnabled {
+		a.guard.enter()
+		defer a.guard.exit()
+		if a.owns(ptr) {
+			a.frees.free(ptr, size, 1)
+		}
+	}
+	// Allocations followed by a canary can't be reclaimed without losing track of it.
+	if a.canaries.enabled || a.cursor >= len(a.buffers) {
+		return false
+	}
+	s := a.buffers[a.cursor]
+	if s.ptr == nil || uintptr(ptr) < uintptr(s.ptr) || uintptr(ptr)+size != uintptr(s.ptr)+s.offset {
+		return false
+	}
+	if debugEnabled {
+		poison(unsafe.Slice((*byte)(ptr), size))
+	}
+	asanPoison(ptr, size)
+	s.offset -= size
+	a.metrics.Frees++
+	a.metrics.FreedBytes += uint64(size)
+	if a.hooks != nil {
+		a.onFree(size)
+	}
+	return true
+}
+
+// owns reports whether ptr points into the memory of the arena, used or not.
+func (a *monotonicArena) owns(ptr unsafe.Pointer) bool {
+	for _, s := range a.buffers {
+		if s.ptr != nil && uintptr(ptr) >= uintptr(s.ptr) && uintptr(ptr)-uintptr(s.ptr) < s.size {
+			return true
+		}
+	}
+	for _, buf := range a.large {
+		if len(buf) > 0 && uintptr(ptr) >= uintptr(unsafe.Pointer(&buf[0])) && uintptr(ptr)-uintptr(unsafe.Pointer(&buf[0])) < uintptr(len(buf)) {
+			return true
+		}
+	}
+	return false
+}
+
+type liveAlloc struct {
+	size  uintptr
+	stack allocStack
+}
+
+type freedAlloc struct {
+	size         uintptr
+	alloc, stack allocStack
+}
+
+// freeTracker keeps track of the allocations served by an arena and of those freed since,
+// so that Free can detect the pointers that aren't allocations of the arena, or no longer are.
+// It's only used when the package is built with the nuke_debug build tag.
+type freeTracker struct {
+	live  map[unsafe.Pointer]liveAlloc
+	freed map[unsafe.Pointer]freedAlloc
+}
+
+// alloc records an allocation of the given size at ptr made from stk.
+func (t *freeTracker) alloc(ptr unsafe.Pointer, size uintptr, stk allocStack) {
+	if t.live == nil {
+		t.live = make(map[unsafe.Pointer]liveAlloc)
+	}
+	t.live[ptr] = liveAlloc{size: size, stack: stk}
+	delete(t.freed, ptr) // the memory of a freed allocation has been reused
+}
+
+// free records the allocation at ptr as freed, panicking if there's no allocation of the given size
+// at ptr. The skip parameter has the same meaning as in runtime.Callers, with 0 identifying the caller
+// of free.
+func (t *freeTracker) free(ptr unsafe.Pointer, size uintptr, skip int) {
+	l, ok := t.live[ptr]
+	if !ok {
+		if f, ok := t.freed[ptr]; ok {
+			panic(fmt.Sprintf("nuke: double free of the %d byte allocation at %p\n\nallocated at:\n%s\nfreed at:\n%s",
+				f.size, ptr, formatStack(f.alloc), formatStack(f.stack)))
+		}
+		panic(fmt.Sprintf("nuke: free of %p, which isn't an allocation of the arena", ptr))
+	}
+	if l.size != size {
+		panic(fmt.Sprintf("nuke: free of %d bytes at %p, which is a %d byte allocation\n\nallocated at:\n%s",
+			size, ptr, l.size, formatStack(l.stack)))
+	}
+	if t.freed == nil {
+		t.freed = make(map[unsafe.Pointer]freedAlloc)
+	}
+	delete(t.live, ptr)
+	t.freed[ptr] = freedAlloc{size: size, alloc: l.stack, stack: callers(skip + 1)}
+}
+
+func (t *freeTracker) reset() {
+	clear(t.live)
+	clear(t.freed)
+}
+
+// Free satisfies the Freer interface, forwarding to the underlying arena if it implements it.
+func (a *concurrentArena) Free(ptr unsafe.Pointer, size uintptr) bool {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return free(a.a, ptr, size)
+}
+
+// Free satisfies the Freer interface, forwarding to the underlying arena if it implements it.
+func (a *ScavengingArena) Free(ptr unsafe.Pointer, size uintptr) bool {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return free(a.a, ptr, size)
+}
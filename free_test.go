@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFree(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewMonotonicArena(1024, 1)
+
+	x := New[[4]uint64](a)
+	y := New[[4]uint64](a)
+
+	// Only the most recent allocation can be reclaimed.
+	require.False(t, Free(a, x))
+	require.True(t, Free(a, y))
+	d, _ := a.(*monotonicArena).demand()
+	require.Equal(t, uint64(32), d)
+
+	z := New[[4]uint64](a)
+	require.Equal(t, y, z)
+	require.Equal(t, [4]uint64{}, *z)
+
+	s := MakeSlice[byte](a, 10, 100)
+	require.True(t, FreeSlice(a, s))
+	require.False(t, FreeSlice[byte](a, nil))
+}
+
+func TestFreeMetrics(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	var freed []uintptr
+	a := NewMonotonicArena(1024, 1, WithHooks(Hooks{OnFree: func(size uintptr) { freed = append(freed, size) }}))
+
+	x := New[[4]uint64](a)
+	y := New[[2]uint64](a)
+	require.False(t, Free(a, x))
+	require.True(t, Free(a, y))
+
+	m := arenaMetrics(a)
+	require.Equal(t, uint64(1), m.Frees)
+	require.Equal(t, uint64(16), m.FreedBytes)
+	require.Equal(t, []uintptr{16}, freed)
+	require.Equal(t, uint64(32), a.(*monotonicArena).BufferUsage()[0].Used)
+}
+
+func TestFreeUnsupported(t *testing.T) {
+	a := NewGCArena(1024)
+	require.False(t, Free(a, New[uint64](a)))
+	require.False(t, Free(nil, &struct{}{}))
+}
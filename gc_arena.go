@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// typedAllocator is implemented by arenas that need to know the type of the values they allocate.
+// New, MakeSlice and SliceAppend use it in preference to Alloc.
+type typedAllocator interface {
+	allocTyped(typ reflect.Type, n int) unsafe.Pointer
+}
+
+type gcArena struct {
+	chunkSize int
+	chunks    map[reflect.Type]*gcChunk
+	large     []reflect.Value
+	bytes     *monotonicArena
+	metrics   Metrics
+	drained   bool
+}
+
+// gcChunk is a typed chunk of memory holding values of a single type.
+type gcChunk struct {
+	v    reflect.Value // slice of chunk elements, keeping the chunk reachable
+	used int
+}
+
+// NewGCArena returns an arena whose memory is visible to the garbage collector.
+// Values allocated through New, MakeSlice or SliceAppend are laid out in typed chunks of
+// about chunkSize bytes, one per type, that are scanned by the GC as any other heap memory.
+// This makes it safe to allocate types holding pointers to heap objects, such as strings,
+// slices, maps or pointers, which must never be stored in the memory of other arenas.
+//
+// Memory requested directly through Alloc carries no type information and is served
+// from untyped buffers, so it must only be used for pointer-free data.
+// The arena grows as needed and it's not safe to be accessed concurrently.
+func NewGCArena(chunkSize int) Arena {
+	return &gcArena{
+		chunkSize: chunkSize,
+		chunks:    make(map[reflect.Type]*gcChunk),
+		bytes:     &monotonicArena{},
+	}
+}
+
+// Alloc satisfies the Arena interface.
+func (a *gcArena) Alloc(size, alignment uintptr) unsafe.Pointer {
+	if arenasDisabled {
+		return nil
+	}
+	if a.drained {
+		return nil
+	}
+	if int(size) > a.chunkSize {
+		a.metrics.FailedAllocs++
+		return nil
+	}
+	ptr := a.bytes.Alloc(size, alignment)
+	if ptr == nil {
+		// Grow untyped memory one buffer at a time.
+		a.bytes.buffers = append(a.bytes.buffers, newMonotonicBuffer(a.chunkSize))
+		ptr = a.bytes.Alloc(size, alignment)
+	}
+	if ptr == nil {
+		a.metrics.FailedAllocs++
+		return nil
+	}
+	a.metrics.Allocs++
+	a.metrics.AllocatedBytes += uint64(size)
+	return ptr
+}
+
+func (a *gcArena) allocTyped(typ reflect.Type, n int) unsafe.Pointer {
+	if a.drained {
+		return nil
+	}
+	if !hasPointers(typ) {
+		return a.Alloc(typ.Size()*uintptr(n), uintptr(typ.Align()))
+	}
+	a.metrics.Allocs++
+	a.metrics.AllocatedBytes += uint64(typ.Size()) * uint64(n)
+
+	chunkLen := max(a.chunkSize/max(int(typ.Size()), 1), 1)
+	if n > chunkLen/4 {
+		// Big allocations get a chunk of their own, so they don't waste the space left in the current one.
+		v := reflect.MakeSlice(reflect.SliceOf(typ), n, n)
+		a.large = append(a.large, v)
+		return v.UnsafePointer()
+	}
+	c := a.chunks[typ]
+	if c == nil || c.used+n > c.v.Len() {
+		c = &gcChunk{v: reflect.MakeSlice(reflect.SliceOf(typ), chunkLen, chunkLen)}
+		a.chunks[typ] = c
+	}
+	ptr := c.v.Index(c.used).Addr().UnsafePointer()
+	c.used += n
+	return ptr
+}
+
+// Reset satisfies the Arena interface.
+// Typed chunks are always dropped, leaving the memory to the garbage collector,
+// as any pointer stored in them would otherwise keep its target alive.
+func (a *gcArena) Reset(release bool) {
+	clear(a.chunks)
+	clear(a.large)
+	a.large = a.large[:0]
+	a.bytes.Reset(release)
+	a.metrics.Resets++
+}
+
+// Metrics returns the allocation metrics of the arena.
+func (a *gcArena) Metrics() Metrics {
+	return a.metrics
+}
+
+func (a *gcArena) recordHeapFallback() {
+	a.metrics.HeapFallbacks++
+}
+
+func (a *gcArena) epoch() uint64 {
+	return a.metrics.Resets
+}
+
+// hasPointers reports whether values of the given type hold any pointer the garbage collector must be aware of.
+func hasPointers(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Map, reflect.Chan, reflect.Func,
+		reflect.Interface, reflect.Slice, reflect.String:
+		return true
+
+	case reflect.Array:
+		return typ.Len() > 0 && hasPointers(typ.Elem())
+
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if hasPointers(typ.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+type pointerObject struct {
+	name   string
+	values []int
+	attrs  map[string]string
+	next   *pointerObject
+}
+
+func TestGCArenaKeepsHeapReferencesAlive(t *testing.T) {
+	for _, arena := range []Arena{NewGCArena(1024), NewConcurrentArena(NewGCArena(1024))} {
+		var objs []*pointerObject
+		for i := 0; i < 100; i++ {
+			obj := New[pointerObject](arena)
+			obj.name = fmt.Sprintf("object-%d", i)
+			obj.values = []int{i, i + 1, i + 2}
+			obj.attrs = map[string]string{"index": fmt.Sprint(i)}
+			obj.next = &pointerObject{name: fmt.Sprintf("next-%d", i)}
+			objs = append(objs, obj)
+		}
+		var refs []*pointerObject
+		for i := 0; i < 10; i++ {
+			refs = SliceAppend(arena, refs, &pointerObject{name: fmt.Sprintf("ref-%d", i)})
+		}
+		for i := 0; i < 3; i++ {
+			runtime.GC()
+		}
+		// Churn the heap so that freed memory gets reused.
+		for i := 0; i < 10_000; i++ {
+			_ = fmt.Sprintf("garbage-%d", i)
+		}
+
+		for i, obj := range objs {
+			require.Equal(t, fmt.Sprintf("object-%d", i), obj.name)
+			require.Equal(t, []int{i, i + 1, i + 2}, obj.values)
+			require.Equal(t, fmt.Sprint(i), obj.attrs["index"])
+			require.Equal(t, fmt.Sprintf("next-%d", i), obj.next.name)
+		}
+		for i, ref := range refs {
+			require.Equal(t, fmt.Sprintf("ref-%d", i), ref.name)
+		}
+		arena.Reset(true)
+	}
+}
+
+func TestGCArenaPointerFreeTypes(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewGCArena(1024).(*gcArena)
+
+	_ = New[int](arena)
+	_ = MakeSlice[noScanObject](arena, 0, 4)
+
+	// Pointer-free values are served from untyped buffers.
+	require.Empty(t, arena.chunks)
+	require.Len(t, arena.bytes.buffers, 1)
+	require.Equal(t, uint64(2), arena.Metrics().Allocs)
+}
+
+func TestGCArenaLargeAllocations(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewGCArena(1024).(*gcArena)
+
+	s := MakeSlice[string](arena, 1000, 1000)
+	for i := range s {
+		s[i] = fmt.Sprint(i)
+	}
+	require.Len(t, arena.large, 1)
+	require.Empty(t, arena.chunks)
+
+	arena.Reset(false)
+	require.Empty(t, arena.large)
+}
+
+func TestGCArenaUntypedAlloc(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewGCArena(64)
+
+	require.NotNil(t, arena.Alloc(64, 8))
+	require.NotNil(t, arena.Alloc(64, 8))
+	require.Nil(t, arena.Alloc(65, 8))
+}
+
+func TestHasPointers(t *testing.T) {
+	for _, tc := range []struct {
+		v        any
+		expected bool
+	}{
+		{v: 0, expected: false},
+		{v: noScanObject{}, expected: false},
+		{v: [4]int{}, expected: false},
+		{v: [0]*int{}, expected: false},
+		{v: "", expected: true},
+		{v: []byte{}, expected: true},
+		{v: unsafe.Pointer(nil), expected: true},
+		{v: [2]*int{}, expected: true},
+		{v: pointerObject{}, expected: true},
+		{v: struct{ a any }{}, expected: true},
+	} {
+		require.Equal(t, tc.expected, hasPointers(reflect.TypeOf(tc.v)), "%T", tc.v)
+	}
+}
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "math/bits"
+
+// SizeHistogram is a histogram of allocation sizes using power-of-two buckets.
+// Bucket 0 counts zero-sized allocations, and bucket i the allocations whose size
+// lies in the range [2^(i-1), 2^i).
+type SizeHistogram [65]uint64
+
+// BucketBounds returns the inclusive range of sizes counted by the i-th bucket.
+func (h *SizeHistogram) BucketBounds(i int) (lo, hi uint64) {
+	if i == 0 {
+		return 0, 0
+	}
+	lo = 1 << (i - 1)
+	return lo, lo + (lo - 1)
+}
+
+func (h *SizeHistogram) observe(size uintptr) {
+	h[bits.Len64(uint64(size))]++
+}
+
+// sizeHistogramReporter is implemented by arenas keeping a histogram of allocation sizes.
+type sizeHistogramReporter interface {
+	SizeHistogram() (SizeHistogram, bool)
+}
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeHistogram(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewConcurrentArena(NewMonotonicArena(1024, 1, WithSizeHistogram()))
+
+	_ = New[struct{}](arena)
+	_ = New[byte](arena)
+	_ = New[int64](arena)
+	_ = MakeSlice[byte](arena, 0, 8)
+	_ = MakeSlice[byte](arena, 0, 4096) // sent to the heap
+
+	h, ok := arena.(sizeHistogramReporter).SizeHistogram()
+	require.True(t, ok)
+
+	var expected SizeHistogram
+	expected[0] = 1  // 0 bytes
+	expected[1] = 1  // 1 byte
+	expected[4] = 2  // 8 bytes
+	expected[13] = 1 // 4096 bytes
+	require.Equal(t, expected, h)
+}
+
+func TestSizeHistogramDisabled(t *testing.T) {
+	arena := NewMonotonicArena(1024, 1)
+
+	_, ok := arena.(sizeHistogramReporter).SizeHistogram()
+	require.False(t, ok)
+}
+
+func TestSizeHistogramBucketBounds(t *testing.T) {
+	var h SizeHistogram
+	for i, bounds := range [][2]uint64{{0, 0}, {1, 1}, {2, 3}, {4, 7}, {8, 15}} {
+		lo, hi := h.BucketBounds(i)
+		require.Equal(t, bounds, [2]uint64{lo, hi})
+	}
+	lo, hi := h.BucketBounds(64)
+	require.Equal(t, uint64(1<<63), lo)
+	require.Equal(t, ^uint64(0), hi)
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+// Hooks holds functions observing the lifecycle of an arena, allowing metrics, tracing or cleanup
+// to be layered onto arenas without decorating them. Nil functions are ignored. Hooks run
+// synchronously and, for arenas wrapped by NewConcurrentArena, while holding their lock, so they
+// must not access the arena.
+type Hooks struct {
+	// OnAlloc is called with the size and alignment of every allocation served by the arena.
+	OnAlloc func(size, alignment uintptr)
+
+	// OnOverflow is called with the size of every allocation the arena couldn't serve.
+	OnOverflow func(size uintptr)
+
+	// OnReset is called at the beginning of every reset.
+	OnReset func(release bool)
+
+	// OnRelease is called with the number of bytes of buffer memory released by a reset,
+	// or by the arena being trimmed, whenever any is.
+	OnRelease func(bytes uintptr)
+
+	// OnFree is called with the size of every allocation whose memory is taken back by Free.
+	OnFree func(size uintptr)
+}
+
+// WithHooks registers hooks observing the lifecycle of the arena. The option may be given
+// more than once, in which case hooks are called in the order they were registered.
+func WithHooks(h Hooks) Option {
+	return func(o *options) { o.hooks = append(o.hooks, h) }
+}
+
+func (a *monotonicArena) onAlloc(size, alignment uintptr) {
+	for _, h := range a.hooks {
+		if h.OnAlloc != nil {
+			h.OnAlloc(size, alignment)
+		}
+	}
+}
+
+func (a *monotonicArena) onOverflow(size uintptr) {
+	for _, h := range a.hooks {
+		if h.OnOverflow != nil {
+			h.OnOverflow(size)
+		}
+	}
+}
+
+func (a *monotonicArena) onFree(size uintptr) {
+	for _, h := range a.hooks {
+		if h.OnFree != nil {
+			h.OnFree(size)
+		}
+	}
+}
+
+func (a *monotonicArena) onReset(release bool) {
+	for _, h := range a.hooks {
+		if h.OnReset != nil {
+			h.OnReset(release)
+		}
+	}
+}
+
+// onRelease calls the OnRelease hooks if the arena holds less buffer memory than it held before.
+func (a *monotonicArena) onRelease(heldBefore uintptr) {
+	held := a.heldBytes()
+	if held >= heldBefore {
+		return
+	}
+	for _, h := range a.hooks {
+		if h.OnRelease != nil {
+			h.OnRelease(heldBefore - held)
+		}
+	}
+}
+
+// heldBytes returns the number of bytes of the buffers holding memory.
+func (a *monotonicArena) heldBytes() uintptr {
+	var n uintptr
+	for _, s := range a.buffers {
+		if s.ptr != nil {
+			n += s.size
+		}
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	var events []string
+	record := func(prefix string) Hooks {
+		return Hooks{
+			OnAlloc:    func(size, _ uintptr) { events = append(events, prefix+"alloc") },
+			OnOverflow: func(size uintptr) { events = append(events, prefix+"overflow") },
+			OnReset:    func(release bool) { events = append(events, prefix+"reset") },
+			OnRelease:  func(bytes uintptr) { events = append(events, prefix+"release") },
+		}
+	}
+	var released uintptr
+	a := NewMonotonicArena(1024, 2,
+		WithHooks(record("")),
+		WithHooks(Hooks{OnRelease: func(bytes uintptr) { released += bytes }}),
+	)
+
+	New[uint64](a)
+	MakeSlice[byte](a, 2048, 2048)
+	a.Reset(false)
+	require.Equal(t, []string{"alloc", "overflow", "reset"}, events)
+
+	events = nil
+	New[uint64](a)
+	a.Reset(true)
+	require.Equal(t, []string{"alloc", "reset", "release"}, events)
+	require.Equal(t, uintptr(1024), released) // only the first buffer held memory
+
+	events = nil
+	New[uint64](a)
+	a.Reset(false)
+	a.(Trimmer).Trim()
+	require.Equal(t, []string{"alloc", "reset", "release"}, events)
+}
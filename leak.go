@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "runtime"
+
+// Leak describes an arena that became unreachable while still holding memory,
+// that is, without having been reset with release set to true.
+type Leak struct {
+	// Committed is the number of bytes held by the arena buffers and large allocations.
+	Committed uint64
+
+	// Stack is the call stack the arena was created from.
+	// It's only recorded when the package is built with the nuke_debug build tag.
+	Stack string
+}
+
+// WithLeakHandler makes the arena report to fn if it becomes unreachable while
+// still holding memory, which usually means that some code path forgot to recycle it.
+// The handler is run from a finalizer, so it must not block.
+func WithLeakHandler(fn func(Leak)) Option {
+	return func(o *options) { o.leakHandler = fn }
+}
+
+// watchLeaks sets a finalizer on a that reports it to fn if it's still holding memory once unreachable.
+func watchLeaks(a *monotonicArena, fn func(Leak)) {
+	var stack string
+	if debugEnabled {
+		stack = formatStack(callers(2))
+	}
+	runtime.SetFinalizer(a, func(a *monotonicArena) {
+		var committed uint64
+		for _, s := range a.buffers {
+			if s.ptr != nil {
+				committed += uint64(s.size)
+			}
+		}
+		for _, buf := range a.large {
+			committed += uint64(len(buf))
+		}
+		if committed > 0 {
+			fn(Leak{Committed: committed, Stack: stack})
+		}
+	})
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeakHandler(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	if !finalizersEnabled {
+		t.Skip("finalizers aren't run")
+	}
+	leaks := make(chan Leak, 2)
+	handler := func(l Leak) { leaks <- l }
+
+	func() {
+		released := NewMonotonicArena(1024, 2, WithLeakHandler(handler))
+		_ = New[int](released)
+		released.Reset(true)
+
+		leaked := NewMonotonicArena(1024, 2, WithLeakHandler(handler))
+		_ = New[int](leaked)
+	}()
+
+	var leak Leak
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		select {
+		case leak = <-leaks:
+			return true
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.Equal(t, uint64(1024), leak.Committed)
+	if debugEnabled {
+		require.Contains(t, leak.Stack, "nuke.TestLeakHandler")
+	} else {
+		require.Empty(t, leak.Stack)
+	}
+
+	// The arena that released its memory is never reported.
+	runtime.GC()
+	runtime.GC()
+	require.Empty(t, leaks)
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+// Metrics contains the allocation counters of an arena.
+// Counters are cumulative and are not cleared on Reset.
+type Metrics struct {
+	// Allocs is the number of allocations served by the arena.
+	Allocs uint64 `json:"allocs"`
+
+	// AllocatedBytes is the number of bytes handed out by the arena, excluding alignment padding.
+	AllocatedBytes uint64 `json:"allocated_bytes"`
+
+	// FailedAllocs is the number of allocations the arena couldn't serve.
+	FailedAllocs uint64 `json:"failed_allocs"`
+
+	// HeapFallbacks is the number of allocations that New, MakeSlice and SliceAppend sent
+	// to the heap because the arena couldn't serve them.
+	HeapFallbacks uint64 `json:"heap_fallbacks"`
+
+	// Resets is the number of times the arena has been reset.
+	Resets uint64 `json:"resets"`
+
+	// GrownBuffers is the number of buffers added on Reset to cover the allocations
+	// the arena couldn't serve (see WithGrowth).
+	GrownBuffers uint64 `json:"grown_buffers"`
+
+	// Frees is the number of allocations whose memory has been taken back by Free,
+	// and FreedBytes their size in bytes.
+	Frees      uint64 `json:"frees"`
+	FreedBytes uint64 `json:"freed_bytes"`
+}
+
+// BufferUsage describes how the memory of an arena buffer has been used since the arena was last reset.
+type BufferUsage struct {
+	// Size is the capacity of the buffer in bytes.
+	Size uint64 `json:"size"`
+
+	// Used is the number of bytes consumed from the buffer, including alignment padding.
+	Used uint64 `json:"used"`
+
+	// Padding is the number of used bytes lost to alignment padding. This includes the space
+	// left unused in the blocks small objects are packed into.
+	Padding uint64 `json:"padding"`
+
+	// Stranded is the number of bytes left unused at the end of the buffer because an
+	// allocation didn't fit and the arena moved on to a later buffer.
+	Stranded uint64 `json:"stranded"`
+}
+
+// Stater is implemented by arenas exposing their allocation metrics,
+// as all the arenas of this package do.
+type Stater interface {
+	Metrics() Metrics
+}
+
+// bufferUsageReporter is implemented by arenas exposing the usage of their buffers.
+type bufferUsageReporter interface {
+	BufferUsage() []BufferUsage
+}
+
+// heapFallbackRecorder is implemented by arenas keeping track of the allocations
+// that have been sent to the heap on their behalf.
+type heapFallbackRecorder interface {
+	recordHeapFallback()
+}
+
+// arenaMetrics returns the metrics of the provided Arena,
+// or zero metrics if it doesn't expose them.
+func arenaMetrics(a Arena) Metrics {
+	if mr, ok := a.(Stater); ok {
+		return mr.Metrics()
+	}
+	return Metrics{}
+}
+
+// RecordHeapFallback records that an allocation the arena couldn't serve has been sent to the heap
+// on its behalf, as New, MakeSlice and SliceAppend do, which makes strict arenas panic with
+// ErrHeapFallback (see WithStrictMode). It's meant for code allocating through Alloc directly,
+// such as the constructors generated by nukegen. Packages built with the nuke_off build tag don't
+// record heap fallbacks, as allocations are meant to be sent to the heap.
+func RecordHeapFallback(a Arena) {
+	if !arenasDisabled {
+		recordHeapFallback(a)
+	}
+}
+
+func recordHeapFallback(a Arena) {
+	if r, ok := a.(heapFallbackRecorder); ok {
+		r.recordHeapFallback()
+	}
+}
@@ -3,17 +3,88 @@
 package nuke
 
 import (
+	"time"
 	"unsafe"
 )
 
+// bufferAlignment is the alignment of every monotonic buffer base address.
+// Allocations with an alignment up to this value only need their offset rounded up.
+const bufferAlignment = 64
+
+const (
+	// tinySize is the size below which allocations are served by the tiny allocator.
+	tinySize = 16
+
+	// tinyBlockSize is the size of the blocks the tiny allocator packs objects into.
+	tinyBlockSize = 64
+)
+
 type monotonicArena struct {
 	buffers []*monotonicBuffer
+
+	// tiny is the block tiny allocations are currently being packed into,
+	// and tinyOffset the offset of its first free byte.
+	tiny       unsafe.Pointer
+	tinyOffset uintptr
+	tinyBuffer int
+
+	guard     accessGuard
+	metrics   Metrics
+	histogram *SizeHistogram
+	sampler   allocSampler
+	sites     allocSiteTracker
+	canaries  canaryTracker
+	frees     freeTracker
+
+	// large holds the memory of allocations bigger than largeThreshold, if set.
+	large          [][]byte
+	largeThreshold uintptr
+
+	escapes escapeSampler
+	strict  bool
+
+	// overflowBytes is the number of bytes of the allocations not served since the last reset,
+	// and deficit the part of them that would have fit a buffer.
+	overflowBytes uint64
+	deficit       uint64
+
+	bufferSize int
+	maxBuffers int
+
+	releasePolicy ReleasePolicy
+	hooks         []Hooks
+
+	// drained makes the arena stop serving allocations and release its memory on reset.
+	drained bool
+
+	overflowHandler     func(size uintptr)
+	panicOnInvalidAlloc bool
+
+	// minAlignment is the alignment every allocation is rounded up to (see WithMinAlignment).
+	minAlignment uintptr
+
+	// cursor is the index of the first buffer allocations are attempted from.
+	// Buffers before it have been left behind because they couldn't serve an
+	// allocation that a later buffer could.
+	cursor int
 }
 
 type monotonicBuffer struct {
 	ptr    unsafe.Pointer
 	offset uintptr
 	size   uintptr
+
+	// watermark is the highest offset ever handed out since the backing memory
+	// was allocated. Everything beyond it is still zeroed by the runtime.
+	watermark uintptr
+
+	// padding and stranded keep track of the bytes wasted since the last reset.
+	padding  uintptr
+	stranded uintptr
+
+	// idleResets and usedAt keep track of how long the buffer has been idle for its release policy.
+	idleResets int
+	usedAt     time.Time
 }
 
 func newMonotonicBuffer(size int) *monotonicBuffer {
@@ -22,30 +93,50 @@ func newMonotonicBuffer(size int) *monotonicBuffer {
 
 func (s *monotonicBuffer) alloc(size, alignment uintptr) (unsafe.Pointer, bool) {
 	if s.ptr == nil {
-		buf := make([]byte, s.size) // allocate monotonic buffer lazily
-		s.ptr = unsafe.Pointer(unsafe.SliceData(buf))
+		r := traceRegion("nuke.allocBuffer")
+		buf := make([]byte, s.size+bufferAlignment-1) // allocate monotonic buffer lazily
+		s.ptr = alignPtr(unsafe.Pointer(unsafe.SliceData(buf)), bufferAlignment)
+		asanPoison(s.ptr, s.size)
+		r.End()
 	}
-	alignOffset := uintptr(0)
-	for alignedPtr := uintptr(s.ptr) + s.offset; alignedPtr%alignment != 0; alignedPtr++ {
-		alignOffset++
+	var alignOffset uintptr
+	if alignment <= bufferAlignment {
+		alignOffset = alignUp(s.offset, alignment) - s.offset
+	} else {
+		addr := uintptr(s.ptr) + s.offset
+		alignOffset = alignUp(addr, alignment) - addr
 	}
 	allocSize := size + alignOffset
 
 	if s.availableBytes() < allocSize {
 		return nil, false
 	}
-	ptr := unsafe.Pointer(uintptr(s.ptr) + s.offset + alignOffset)
+	begin := s.offset + alignOffset
+	end := begin + size
+	ptr := unsafe.Add(s.ptr, begin)
 	s.offset += allocSize
+	s.padding += alignOffset
+
+	asanUnpoison(ptr, size)
 
-	// This piece of code will be translated into a runtime.memclrNoHeapPointers
-	// invocation by the compiler, which is an assembler optimized implementation.
-	// Architecture specific code can be found at src/runtime/memclr_$GOARCH.s
-	// in Go source (since https://codereview.appspot.com/137880043).
-	b := unsafe.Slice((*byte)(ptr), size)
+	// Memory handed out after a reset happens after the reset that reclaimed it,
+	// even when the arena is passed between goroutines.
+	raceAcquire(unsafe.Pointer(s))
 
-	for i := range b {
-		b[i] = 0
+	// Only memory below the watermark may have been used since the buffer was
+	// allocated, anything beyond it is still zeroed and doesn't need clearing.
+	if begin < s.watermark {
+		// This piece of code will be translated into a runtime.memclrNoHeapPointers
+		// invocation by the compiler, which is an assembler optimized implementation.
+		// Architecture specific code can be found at src/runtime/memclr_$GOARCH.s
+		// in Go source (since https://codereview.appspot.com/137880043).
+		b := unsafe.Slice((*byte)(ptr), min(end, s.watermark)-begin)
+
+		for i := range b {
+			b[i] = 0
+		}
 	}
+	s.watermark = max(s.watermark, end)
 
 	return ptr, true
 }
@@ -54,31 +145,210 @@ func (s *monotonicBuffer) reset(release bool) {
 	if s.offset == 0 {
 		return
 	}
+	if debugEnabled {
+		asanUnpoison(s.ptr, s.offset) // padding bytes are poisoned already
+		poison(unsafe.Slice((*byte)(s.ptr), s.offset))
+	}
+	asanPoison(s.ptr, s.offset)
+
+	// Reclaiming memory counts as writing it, so that accesses through references
+	// that outlived the reset and aren't ordered before it are reported as races.
+	raceWriteRange(s.ptr, s.offset)
+	raceReleaseMerge(unsafe.Pointer(s))
+
 	s.offset = 0
+	s.padding = 0
+	s.stranded = 0
 
 	if release {
 		s.ptr = nil
+		s.watermark = 0
 	}
 }
 
+func (s *monotonicBuffer) trim() {
+	if s.offset != 0 {
+		return
+	}
+	s.ptr = nil
+	s.watermark = 0
+}
+
+// strand marks the remaining space of the buffer as unusable until the next reset.
+func (s *monotonicBuffer) strand() {
+	s.stranded = s.availableBytes()
+}
+
 func (s *monotonicBuffer) availableBytes() uintptr {
 	return s.size - s.offset
 }
 
 // NewMonotonicArena creates a new monotonic arena with a specified number of buffers and a buffer size.
-func NewMonotonicArena(bufferSize, bufferCount int) Arena {
-	a := &monotonicArena{}
+// The returned arena takes no locks, and its only atomic operation is a load of the rate set with
+// SetAllocProfileRate on every allocation, so that profiling can be enabled on running arenas.
+// It must not be accessed concurrently from multiple goroutines. Building with the nuke_debug tag
+// turns any such access into a panic.
+func NewMonotonicArena(bufferSize, bufferCount int, opts ...Option) Arena {
+	o := newOptions(opts)
+
+	a := &monotonicArena{bufferSize: bufferSize}
 	for i := 0; i < bufferCount; i++ {
 		a.buffers = append(a.buffers, newMonotonicBuffer(bufferSize))
 	}
+	if o.sizeHistogram {
+		a.histogram = &SizeHistogram{}
+	}
+	a.canaries.enabled = debugEnabled && o.canaries
+	a.overflowHandler = o.overflowHandler
+	a.strict = o.strict
+	a.maxBuffers = o.maxBuffers
+	a.releasePolicy = o.releasePolicy
+	a.hooks = o.hooks
+	if o.escapeHandler != nil {
+		a.escapes = escapeSampler{rate: o.escapeSamplingRate, handler: o.escapeHandler}
+	}
+	a.panicOnInvalidAlloc = debugEnabled || o.panicOnInvalidAlloc
+	if o.minAlignment > 0 {
+		if o.minAlignment&(o.minAlignment-1) != 0 {
+			panic("nuke: minimum alignment must be a power of two")
+		}
+		a.minAlignment = uintptr(o.minAlignment)
+	}
+	if o.largeAllocThreshold > 0 {
+		a.largeThreshold = uintptr(min(o.largeAllocThreshold, bufferSize))
+	}
+	if o.leakHandler != nil {
+		watchLeaks(a, o.leakHandler)
+	}
 	return a
 }
 
 // Alloc satisfies the Arena interface.
 func (a *monotonicArena) Alloc(size, alignment uintptr) unsafe.Pointer {
-	for i := 0; i < len(a.buffers); i++ {
+	if arenasDisabled {
+		return nil
+	}
+	if debugEnabled {
+		a.guard.enter()
+		defer a.guard.exit()
+	}
+	if a.drained {
+		return nil
+	}
+	if !validAlloc(size, alignment) {
+		if a.panicOnInvalidAlloc {
+			panic(invalidAllocError(size, alignment))
+		}
+		a.metrics.FailedAllocs++
+		return nil
+	}
+	alignment = max(alignment, a.minAlignment)
+	if a.histogram != nil {
+		a.histogram.observe(size)
+	}
+	allocSize := size
+	if debugEnabled && a.canaries.enabled {
+		allocSize += canarySize
+	}
+	var ptr unsafe.Pointer
+	switch {
+	case a.largeThreshold > 0 && size > a.largeThreshold:
+		ptr = a.allocLarge(allocSize, alignment)
+
+	case a.escapes.sample():
+		// Sampled allocations need memory of their own, so that they can be watched for escapes.
+		ptr = a.allocLarge(max(allocSize, escapeMinSize), alignment)
+		a.escapes.watch(a.large[len(a.large)-1], size, 1)
+
+	case allocSize != size:
+		// Every allocation is followed by a canary word, so tiny ones can't be packed together.
+		ptr = a.alloc(allocSize, alignment)
+
+	default:
+		if size < tinySize && alignment < tinySize {
+			ptr = a.allocTiny(size, alignment)
+		}
+		if ptr == nil {
+			ptr = a.alloc(size, alignment)
+		}
+	}
+	if ptr == nil {
+		a.metrics.FailedAllocs++
+		a.overflowBytes += uint64(size)
+		if size <= uintptr(a.bufferSize) {
+			a.deficit += uint64(size)
+		}
+		traceOverflow(size)
+		if a.overflowHandler != nil {
+			a.overflowHandler(size)
+		}
+		if a.hooks != nil {
+			a.onOverflow(size)
+		}
+		return nil
+	}
+	a.metrics.Allocs++
+	a.metrics.AllocatedBytes += uint64(size)
+	if a.hooks != nil {
+		a.onAlloc(size, alignment)
+	}
+
+	if debugEnabled {
+		stk := a.sites.record(size, 1)
+		a.frees.alloc(ptr, size, stk)
+		if a.canaries.enabled {
+			a.canaries.add(unsafe.Add(ptr, size), size, stk)
+		}
+	}
+	if rate := allocProfileRate.Load(); rate > 0 {
+		a.sampler.sample(size, rate, 2)
+	}
+	return ptr
+}
+
+// allocTiny packs small objects together into cache line sized blocks, so that
+// they don't get interleaved with the alignment padding of bigger allocations.
+func (a *monotonicArena) allocTiny(size, alignment uintptr) unsafe.Pointer {
+	if a.tiny != nil {
+		if off := alignUp(a.tinyOffset, alignment); off+size <= tinyBlockSize {
+			a.buffers[a.tinyBuffer].padding += off - a.tinyOffset
+			a.tinyOffset = off + size
+			ptr := unsafe.Add(a.tiny, off)
+			asanUnpoison(ptr, size)
+			return ptr
+		}
+	}
+	ptr := a.alloc(tinyBlockSize, tinySize)
+	if ptr == nil {
+		return nil
+	}
+	if a.tiny != nil {
+		a.buffers[a.tinyBuffer].padding += tinyBlockSize - a.tinyOffset
+	}
+	a.tiny = ptr
+	a.tinyOffset = size
+	a.tinyBuffer = a.cursor
+
+	// Only the bytes handed out from the tiny block are addressable.
+	asanPoison(ptr, tinyBlockSize)
+	asanUnpoison(ptr, size)
+	return ptr
+}
+
+// allocLarge serves an allocation from memory of its own, which is kept until the next reset.
+func (a *monotonicArena) allocLarge(size, alignment uintptr) unsafe.Pointer {
+	buf := make([]byte, size+alignment-1)
+	a.large = append(a.large, buf)
+	return alignPtr(unsafe.Pointer(unsafe.SliceData(buf)), alignment)
+}
+
+func (a *monotonicArena) alloc(size, alignment uintptr) unsafe.Pointer {
+	for i := a.cursor; i < len(a.buffers); i++ {
 		ptr, ok := a.buffers[i].alloc(size, alignment)
 		if ok {
+			for ; a.cursor < i; a.cursor++ {
+				a.buffers[a.cursor].strand()
+			}
 			return ptr
 		}
 	}
@@ -87,7 +357,147 @@ func (a *monotonicArena) Alloc(size, alignment uintptr) unsafe.Pointer {
 
 // Reset satisfies the Arena interface.
 func (a *monotonicArena) Reset(release bool) {
+	if debugEnabled {
+		a.guard.enter()
+		defer a.guard.exit()
+	}
+	defer traceRegion("nuke.Reset").End()
+
+	release = release || a.drained
+	if a.hooks != nil {
+		a.onReset(release)
+		defer a.onRelease(a.heldBytes())
+	}
+	if debugEnabled && a.canaries.enabled {
+		if err := a.canaries.check(); err != nil {
+			panic(err)
+		}
+		a.canaries.reset()
+	}
+	if release || a.releasePolicy == (ReleasePolicy{}) {
+		for _, s := range a.buffers {
+			s.reset(release)
+		}
+	} else {
+		var now time.Time
+		if a.releasePolicy.IdleTime > 0 {
+			now = time.Now()
+		}
+		for _, s := range a.buffers {
+			if !a.releasePolicy.releasable(s, now) {
+				s.reset(false)
+			} else if s.offset > 0 {
+				s.reset(true)
+			} else {
+				s.trim()
+			}
+		}
+	}
+	a.escapes.release()
+	for i, buf := range a.large {
+		if debugEnabled {
+			poison(buf)
+		}
+		a.large[i] = nil
+	}
+	a.large = a.large[:0]
+	a.metrics.Resets++
+	a.grow()
+	a.overflowBytes = 0
+	a.deficit = 0
+	a.sampler.reset()
+	a.sites.reset()
+	a.frees.reset()
+	a.cursor = 0
+	a.tiny = nil
+	a.tinyOffset = 0
+}
+
+// Metrics returns the allocation metrics of the arena.
+func (a *monotonicArena) Metrics() Metrics {
+	return a.metrics
+}
+
+// SizeHistogram returns the histogram of allocation sizes, if enabled.
+func (a *monotonicArena) SizeHistogram() (SizeHistogram, bool) {
+	if a.histogram == nil {
+		return SizeHistogram{}, false
+	}
+	return *a.histogram, true
+}
+
+// BufferUsage returns the usage of every arena buffer since the arena was last reset.
+func (a *monotonicArena) BufferUsage() []BufferUsage {
+	usage := make([]BufferUsage, len(a.buffers))
+	for i, s := range a.buffers {
+		usage[i] = BufferUsage{
+			Size:     uint64(s.size),
+			Used:     uint64(s.offset),
+			Padding:  uint64(s.padding),
+			Stranded: uint64(s.stranded),
+		}
+	}
+	return usage
+}
+
+func (a *monotonicArena) recordHeapFallback() {
+	a.metrics.HeapFallbacks++
+	if a.strict {
+		panic(ErrHeapFallback)
+	}
+}
+
+func (a *monotonicArena) allocSites() []AllocSite {
+	return a.sites.sites()
+}
+
+func (a *monotonicArena) checkCanaries() error {
+	return a.canaries.check()
+}
+
+func (a *monotonicArena) epoch() uint64 {
+	return a.metrics.Resets
+}
+
+// grow adds the buffers needed to cover the deficit of the last cycle, if growth is enabled.
+func (a *monotonicArena) grow() {
+	if a.deficit == 0 || a.bufferSize <= 0 || len(a.buffers) >= a.maxBuffers {
+		return
+	}
+	n := (a.deficit + uint64(a.bufferSize) - 1) / uint64(a.bufferSize)
+	n = min(n, uint64(a.maxBuffers-len(a.buffers)))
+	for i := uint64(0); i < n; i++ {
+		a.buffers = append(a.buffers, newMonotonicBuffer(a.bufferSize))
+	}
+	a.metrics.GrownBuffers += n
+}
+
+// demand returns the number of bytes requested since the last reset, including
+// alignment padding and the allocations that couldn't be served.
+func (a *monotonicArena) demand() (uint64, bool) {
+	d := a.overflowBytes
+	for _, s := range a.buffers {
+		d += uint64(s.offset)
+	}
+	return d, true
+}
+
+// Trim satisfies the Trimmer interface, releasing the memory of every buffer holding no allocation.
+func (a *monotonicArena) Trim() {
+	if a.hooks != nil {
+		defer a.onRelease(a.heldBytes())
+	}
 	for _, s := range a.buffers {
-		s.reset(release)
+		s.trim()
 	}
 }
+
+// alignUp rounds n up to a multiple of alignment, which must be a power of two.
+func alignUp(n, alignment uintptr) uintptr {
+	return (n + alignment - 1) &^ (alignment - 1)
+}
+
+// alignPtr rounds ptr up to a multiple of alignment, which must be a power of two.
+func alignPtr(ptr unsafe.Pointer, alignment uintptr) unsafe.Pointer {
+	return unsafe.Add(ptr, alignUp(uintptr(ptr), alignment)-uintptr(ptr))
+}
@@ -13,6 +13,9 @@ import (
 )
 
 func TestMonotonicArenaAllocateObject(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
 	arena := NewMonotonicArena(8182, 1) // 8KB
 
 	var refs []*int
@@ -26,6 +29,9 @@ func TestMonotonicArenaAllocateObject(t *testing.T) {
 }
 
 func TestMonotonicArenaAllocateSlice(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
 	arena := NewMonotonicArena(1024*1024, 1) // 8KB
 
 	var refs [][]int
@@ -41,6 +47,9 @@ func TestMonotonicArenaAllocateSlice(t *testing.T) {
 }
 
 func TestMonotonicArenaSendObjectToHeap(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
 	var x int
 	arena := NewMonotonicArena(2*int(unsafe.Sizeof(x)), 1) // 2 ints room
 
@@ -53,6 +62,12 @@ func TestMonotonicArenaSendObjectToHeap(t *testing.T) {
 }
 
 func TestMonotonicArenaReset(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	if !finalizersEnabled {
+		t.Skip("finalizers aren't run")
+	}
 	arena := NewMonotonicArena(1024, 1).(*monotonicArena) // one monotonic buffer of 1KB
 
 	// Allocate monotonic buffer
@@ -100,12 +115,272 @@ func TestMonotonicArenaMultipleTypes(t *testing.T) {
 	arena := NewMonotonicArena(8182, 1) // 8KB
 
 	var b = New[byte](arena)
-	var p = New[*int](arena)
-
 	require.Equal(t, *b, byte(0))
+
+	if debugEnabled {
+		// Debug builds refuse to allocate pointers from arena memory.
+		require.Panics(t, func() { _ = New[*int](arena) })
+		return
+	}
+	var p = New[*int](arena)
 	require.True(t, *p == nil)
 }
 
+func TestMonotonicArenaReuseAfterReset(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(1024, 1).(*monotonicArena) // one monotonic buffer of 1KB
+
+	s := MakeSlice[byte](arena, 64, 64)
+	for i := range s {
+		s[i] = 0xff
+	}
+	arena.Reset(false)
+
+	// Reused memory must be cleared, and the pristine tail left untouched.
+	s = MakeSlice[byte](arena, 128, 128)
+	require.Equal(t, make([]byte, 128), s)
+	require.Equal(t, uintptr(128), arena.buffers[0].watermark)
+
+	arena.Reset(true)
+	require.Equal(t, uintptr(0), arena.buffers[0].watermark)
+}
+
+func TestMonotonicArenaCursor(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(16, 2).(*monotonicArena) // two monotonic buffers of 16 bytes
+
+	_ = MakeSlice[byte](arena, 12, 12)
+	require.Equal(t, 0, arena.cursor)
+
+	// An allocation that can't be served by any buffer leaves the cursor untouched.
+	_ = MakeSlice[byte](arena, 32, 32)
+	require.Equal(t, 0, arena.cursor)
+
+	// Moving on to the second buffer leaves the first one behind...
+	_ = MakeSlice[byte](arena, 8, 8)
+	require.Equal(t, 1, arena.cursor)
+
+	// ...even if it could still hold a smaller allocation.
+	b := New[byte](arena)
+	require.Equal(t, uintptr(arena.buffers[1].ptr)+8, uintptr(unsafe.Pointer(b)))
+
+	arena.Reset(false)
+	require.Equal(t, 0, arena.cursor)
+}
+
+func TestMonotonicArenaAlignment(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(1024, 1).(*monotonicArena) // one monotonic buffer of 1KB
+
+	_ = New[byte](arena)
+	require.Zero(t, uintptr(arena.buffers[0].ptr)%bufferAlignment)
+
+	for _, alignment := range []uintptr{1, 2, 4, 8, 16, 64, 128, 256} {
+		ptr := arena.Alloc(1, alignment)
+		require.NotNil(t, ptr)
+		require.Zero(t, uintptr(ptr)%alignment)
+	}
+}
+
+func TestMonotonicArenaTinyAllocations(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(1024, 1).(*monotonicArena) // one monotonic buffer of 1KB
+
+	b0 := New[byte](arena)
+	_ = arena.Alloc(32, 32)
+	b1 := New[byte](arena)
+	i0 := New[int32](arena)
+
+	// Tiny objects are packed together regardless of the allocations in between.
+	require.Equal(t, uintptr(unsafe.Pointer(b0))+1, uintptr(unsafe.Pointer(b1)))
+	require.Equal(t, uintptr(unsafe.Pointer(b0))+4, uintptr(unsafe.Pointer(i0)))
+
+	// Once the tiny block is exhausted a new one is taken from the buffer.
+	var last *int64
+	for i := 0; i < tinyBlockSize/8; i++ {
+		last = New[int64](arena)
+	}
+	require.Equal(t, arena.tiny, unsafe.Pointer(last))
+	require.Equal(t, uintptr(8), arena.tinyOffset)
+
+	arena.Reset(false)
+	require.Nil(t, arena.tiny)
+}
+
+func TestMonotonicArenaLargeAllocations(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(1024, 1, WithLargeAllocThreshold(256)).(*monotonicArena)
+
+	// Allocations up to the threshold are served from the buffers.
+	_ = MakeSlice[byte](arena, 256, 256)
+	require.Empty(t, arena.large)
+
+	// Bigger ones, including those that wouldn't fit a buffer, get memory of their own.
+	s := MakeSlice[int64](arena, 64, 64)
+	require.Len(t, arena.large, 1)
+	require.Zero(t, uintptr(unsafe.Pointer(unsafe.SliceData(s)))%unsafe.Alignof(int64(0)))
+
+	_ = MakeSlice[byte](arena, 4096, 4096)
+	require.Len(t, arena.large, 2)
+
+	require.Equal(t, uint64(256), arena.BufferUsage()[0].Used)
+	require.Equal(t, uint64(3), arena.Metrics().Allocs)
+	require.Zero(t, arena.Metrics().HeapFallbacks)
+
+	arena.Reset(false)
+	require.Empty(t, arena.large)
+}
+
+func TestMonotonicArenaOverflowHandler(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	var overflows []uintptr
+	arena := NewConcurrentArena(NewMonotonicArena(64, 1, WithOverflowHandler(func(size uintptr) {
+		overflows = append(overflows, size)
+	})))
+
+	_ = MakeSlice[byte](arena, 48, 48)
+	require.Empty(t, overflows)
+
+	_ = MakeSlice[byte](arena, 32, 32) // sent to the heap
+	_ = MakeSlice[byte](arena, 0, 128) // sent to the heap
+	require.Equal(t, []uintptr{32, 128}, overflows)
+}
+
+func TestMonotonicArenaInvalidAlloc(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(1024, 1)
+	if debugEnabled {
+		require.Panics(t, func() { _ = arena.Alloc(8, 3) })
+	} else {
+		require.Nil(t, arena.Alloc(8, 3))
+		require.Nil(t, arena.Alloc(8, 0))
+		require.Nil(t, arena.Alloc(^uintptr(0), 8))
+		require.Equal(t, uint64(3), arena.(Stater).Metrics().FailedAllocs)
+	}
+
+	arena = NewMonotonicArena(1024, 1, WithPanicOnInvalidAlloc())
+	require.PanicsWithError(t, "nuke: invalid allocation: size 8, alignment 3", func() { _ = arena.Alloc(8, 3) })
+}
+
+func TestConcurrentArenaRecoversFromInvalidAlloc(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewConcurrentArena(NewMonotonicArena(1024, 1, WithPanicOnInvalidAlloc()))
+	require.Panics(t, func() { _ = arena.Alloc(8, 3) })
+
+	// The arena isn't left locked by the panic.
+	require.NotNil(t, arena.Alloc(8, 8))
+	arena.Reset(false)
+}
+
+func TestMonotonicArenaStrictMode(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewConcurrentArena(NewMonotonicArena(64, 1, WithStrictMode()))
+
+	_ = MakeSlice[byte](arena, 64, 64)
+	require.PanicsWithValue(t, ErrHeapFallback, func() { _ = New[int](arena) })
+	require.PanicsWithValue(t, ErrHeapFallback, func() { _ = SliceAppend[byte](arena, nil, 1) })
+
+	// The arena remains usable after panicking.
+	arena.Reset(false)
+	require.NotPanics(t, func() { _ = New[int](arena) })
+	require.Equal(t, uint64(2), arena.(Stater).Metrics().HeapFallbacks)
+}
+
+func TestMonotonicArenaMetrics(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	var x int
+	arena := NewConcurrentArena(NewMonotonicArena(2*int(unsafe.Sizeof(x)), 1)) // 2 ints room
+
+	_ = New[int](arena)
+	_ = New[int](arena)
+	_ = New[int](arena)             // sent to the heap
+	_ = MakeSlice[int](arena, 0, 4) // sent to the heap
+
+	require.Equal(t, Metrics{
+		Allocs:         2,
+		AllocatedBytes: 2 * uint64(unsafe.Sizeof(x)),
+		FailedAllocs:   2,
+		HeapFallbacks:  2,
+	}, arena.(Stater).Metrics())
+
+	// Counters are not cleared on Reset.
+	arena.Reset(true)
+	require.Equal(t, uint64(2), arena.(Stater).Metrics().Allocs)
+	require.Equal(t, uint64(1), arena.(Stater).Metrics().Resets)
+}
+
+func TestMonotonicArenaBufferUsage(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(64, 2).(*monotonicArena) // two monotonic buffers of 64 bytes
+
+	_ = arena.Alloc(17, 1)
+	_ = arena.Alloc(16, 16) // 15 bytes of padding, 16 bytes left
+	_ = arena.Alloc(24, 1)  // doesn't fit, strands the first buffer
+
+	require.Equal(t, []BufferUsage{
+		{Size: 64, Used: 48, Padding: 15, Stranded: 16},
+		{Size: 64, Used: 24},
+	}, arena.BufferUsage())
+
+	arena.Reset(false)
+	require.Equal(t, []BufferUsage{{Size: 64}, {Size: 64}}, arena.BufferUsage())
+
+	_ = New[byte](arena)
+	_ = New[int64](arena)  // padded to its alignment within the tiny block
+	_ = arena.Alloc(32, 1) // tiny block takes the whole first buffer
+
+	require.Equal(t, []BufferUsage{
+		{Size: 64, Used: 64, Padding: uint64(unsafe.Alignof(int64(0)) - 1)}, // 3 bytes on 32-bit platforms
+		{Size: 64, Used: 32},
+	}, arena.BufferUsage())
+}
+
+func TestMonotonicArenaGrowth(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewMonotonicArena(1024, 1, WithGrowth(3))
+
+	for i := 0; i < 4; i++ {
+		MakeSlice[byte](a, 1000, 1000)
+	}
+	MakeSlice[byte](a, 2000, 2000) // never fits a buffer
+	a.Reset(false)
+
+	// The deficit of 3000 bytes is covered by adding up to the maximum number of buffers.
+	require.Len(t, a.(bufferUsageReporter).BufferUsage(), 3)
+	require.Equal(t, uint64(2), arenaMetrics(a).GrownBuffers)
+
+	for i := 0; i < 4; i++ {
+		MakeSlice[byte](a, 1000, 1000)
+	}
+	a.Reset(false)
+	require.Len(t, a.(bufferUsageReporter).BufferUsage(), 3)
+	require.Equal(t, uint64(2), arenaMetrics(a).GrownBuffers)
+}
+
 func isMonotonicArenaPtr(a Arena, ptr unsafe.Pointer) bool {
 	ma := a.(*monotonicArena)
 	for _, s := range ma.buffers {
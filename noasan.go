@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !asan
+
+package nuke
+
+import "unsafe"
+
+// asanEnabled reports whether the package has been built with address sanitizer support.
+const asanEnabled = false
+
+// asanPoison marks the given memory region as unaddressable for the address sanitizer.
+func asanPoison(unsafe.Pointer, uintptr) {}
+
+// asanUnpoison marks the given memory region as addressable for the address sanitizer.
+func asanUnpoison(unsafe.Pointer, uintptr) {}
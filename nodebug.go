@@ -0,0 +1,9 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !nuke_debug
+
+package nuke
+
+// debugEnabled reports whether the package has been built with the nuke_debug build tag,
+// which enables runtime checks that are too expensive to be run in production.
+const debugEnabled = false
@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !race
+
+package nuke
+
+import "unsafe"
+
+// raceEnabled reports whether the package has been built with the race detector.
+const raceEnabled = false
+
+// raceAcquire establishes a happens-before relation with the last raceReleaseMerge on addr.
+func raceAcquire(unsafe.Pointer) {}
+
+// raceReleaseMerge makes everything done so far visible to subsequent raceAcquire calls on addr.
+func raceReleaseMerge(unsafe.Pointer) {}
+
+// raceWriteRange reports a write of the given memory region to the race detector.
+func raceWriteRange(unsafe.Pointer, uintptr) {}
@@ -0,0 +1,8 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+
+package nuke
+
+// finalizersEnabled reports whether the runtime runs finalizers, which TinyGo doesn't.
+const finalizersEnabled = true
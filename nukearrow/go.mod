@@ -0,0 +1,25 @@
+module github.com/ortuman/nuke/nukearrow
+
+go 1.25.0
+
+replace github.com/ortuman/nuke => ../
+
+require (
+	github.com/apache/arrow-go/v18 v18.1.0
+	github.com/ortuman/nuke v0.0.0
+	github.com/stretchr/testify v1.12.1
+)
+
+require (
+	github.com/goccy/go-json v0.10.4 // indirect
+	github.com/google/flatbuffers v24.12.23+incompatible // indirect
+	github.com/klauspost/cpuid/v2 v2.2.9 // indirect
+	github.com/zeebo/xxh3 v1.0.2 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+	golang.org/x/exp v0.0.0-20240909161429-701f63a606c0 // indirect
+	golang.org/x/mod v0.22.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	golang.org/x/tools v0.29.0 // indirect
+	golang.org/x/xerrors v0.0.0-20240903120638-7835f813f4da // indirect
+)
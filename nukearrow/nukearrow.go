@@ -0,0 +1,289 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukearrow provides Apache Arrow integration, sourcing the buffers of arrays
+// and record batches from arena memory.
+package nukearrow
+
+import (
+	"unsafe"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/bitutil"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/ortuman/nuke"
+)
+
+// alignment is the alignment of the buffers handed out to Arrow, as required by its format.
+const alignment = 64
+
+// Allocator is a memory.Allocator allocating the buffers of Arrow arrays, builders and
+// record batches from an arena, so that the columnar data built to answer a query can be
+// discarded at once by resetting the arena.
+//
+// Buffers remain valid until the arena is reset, regardless of the reference counts of the
+// Arrow values holding them, so everything built with the allocator must be released or
+// dropped before then. Like the arena, the allocator is only safe to be used concurrently
+// if the arena is.
+type Allocator struct {
+	a nuke.Arena
+}
+
+var _ memory.Allocator = (*Allocator)(nil)
+
+// NewAllocator returns an Allocator allocating from the arena.
+func NewAllocator(a nuke.Arena) *Allocator {
+	return &Allocator{a: a}
+}
+
+// Allocate satisfies the memory.Allocator interface, returning a zeroed buffer aligned to 64 bytes.
+func (al *Allocator) Allocate(size int) []byte {
+	if size == 0 {
+		return nil
+	}
+	// Like Arrow's Go allocator, over-allocate and align within the buffer, which keeps
+	// the heap fallback and strict mode of the arena in play.
+	buf := nuke.MakeSlice[byte](al.a, 0, size+alignment-1)
+	addr := uintptr(unsafe.Pointer(unsafe.SliceData(buf)))
+	off := int((alignment - addr%alignment) % alignment)
+	return buf[off : off+size]
+}
+
+// Reallocate satisfies the memory.Allocator interface, growing the buffer within its
+// capacity if possible, or copying it into a new one otherwise.
+func (al *Allocator) Reallocate(size int, b []byte) []byte {
+	if size <= cap(b) {
+		return b[:size]
+	}
+	nb := al.Allocate(size)
+	copy(nb, b)
+	al.Free(b)
+	return nb
+}
+
+// Free satisfies the memory.Allocator interface. The memory of the buffer is only taken
+// back by the arena if it's the last one allocated from it (see nuke.Freer), otherwise
+// it's kept until the arena is reset.
+func (al *Allocator) Free(b []byte) {
+	if cap(b) > 0 {
+		nuke.FreeSlice(al.a, b[:0])
+	}
+}
+
+// FixedWidth is the set of element types of the arrays built with a FixedWidthBuilder.
+type FixedWidth interface {
+	int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64
+}
+
+// FixedWidthBuilder builds an Arrow array of fixed-width values, laying out its validity bitmap
+// and data buffers directly in arena memory. Unlike the builders of the array package, it takes
+// no reference counts and its buffers need no release, as they're reclaimed by resetting the arena.
+type FixedWidthBuilder[T FixedWidth] struct {
+	validity buffer
+	data     buffer
+	len      int
+	nulls    int
+}
+
+// NewFixedWidthBuilder returns a FixedWidthBuilder allocating from the arena.
+func NewFixedWidthBuilder[T FixedWidth](a nuke.Arena) *FixedWidthBuilder[T] {
+	al := NewAllocator(a)
+	return &FixedWidthBuilder[T]{validity: buffer{al: al}, data: buffer{al: al}}
+}
+
+// Append appends a value to the array.
+func (b *FixedWidthBuilder[T]) Append(v T) {
+	var x T
+	size := int(unsafe.Sizeof(x))
+	b.data.resize((b.len + 1) * size)
+	*(*T)(unsafe.Pointer(&b.data.b[b.len*size])) = v
+	b.appendValidity(true)
+}
+
+// AppendNull appends a null to the array.
+func (b *FixedWidthBuilder[T]) AppendNull() {
+	var x T
+	b.data.resize((b.len + 1) * int(unsafe.Sizeof(x)))
+	b.appendValidity(false)
+}
+
+// Len returns the number of values and nulls appended to the array.
+func (b *FixedWidthBuilder[T]) Len() int {
+	return b.len
+}
+
+// NewArray returns the array built so far and resets the builder, so that it can be used
+// to build a new one. The array is only valid until the arena is reset.
+func (b *FixedWidthBuilder[T]) NewArray() arrow.Array {
+	var x T
+	data := array.NewData(dataType(x), b.len, []*memory.Buffer{b.validity.buffer(), b.data.buffer()}, nil, b.nulls, 0)
+	defer data.Release()
+
+	b.validity.reset()
+	b.data.reset()
+	b.len, b.nulls = 0, 0
+	return array.MakeFromData(data)
+}
+
+func (b *FixedWidthBuilder[T]) appendValidity(valid bool) {
+	appendValidity(&b.validity, b.len, b.nulls, valid)
+	if !valid {
+		b.nulls++
+	}
+	b.len++
+}
+
+// StringBuilder builds an Arrow array of strings, laying out its validity bitmap, offsets and
+// data buffers directly in arena memory. Unlike the builders of the array package, it takes no
+// reference counts and its buffers need no release, as they're reclaimed by resetting the arena.
+type StringBuilder struct {
+	validity buffer
+	offsets  buffer
+	data     buffer
+	len      int
+	nulls    int
+}
+
+// NewStringBuilder returns a StringBuilder allocating from the arena.
+func NewStringBuilder(a nuke.Arena) *StringBuilder {
+	al := NewAllocator(a)
+	return &StringBuilder{validity: buffer{al: al}, offsets: buffer{al: al}, data: buffer{al: al}}
+}
+
+// Append appends a string to the array.
+func (b *StringBuilder) Append(s string) {
+	n := len(b.data.b)
+	b.data.resize(n + len(s))
+	copy(b.data.b[n:], s)
+	b.appendOffset()
+	b.appendValidity(true)
+}
+
+// AppendBytes appends the bytes of a string to the array.
+func (b *StringBuilder) AppendBytes(s []byte) {
+	b.Append(unsafe.String(unsafe.SliceData(s), len(s)))
+}
+
+// AppendNull appends a null to the array.
+func (b *StringBuilder) AppendNull() {
+	b.appendOffset()
+	b.appendValidity(false)
+}
+
+// Len returns the number of strings and nulls appended to the array.
+func (b *StringBuilder) Len() int {
+	return b.len
+}
+
+// NewArray returns the array built so far and resets the builder, so that it can be used
+// to build a new one. The array is only valid until the arena is reset.
+func (b *StringBuilder) NewArray() *array.String {
+	if b.len == 0 {
+		b.offsets.resize(4) // the offsets buffer always holds the start of the first string
+	}
+	bufs := []*memory.Buffer{b.validity.buffer(), b.offsets.buffer(), b.data.buffer()}
+	data := array.NewData(arrow.BinaryTypes.String, b.len, bufs, nil, b.nulls, 0)
+	defer data.Release()
+
+	b.validity.reset()
+	b.offsets.reset()
+	b.data.reset()
+	b.len, b.nulls = 0, 0
+	return array.NewStringData(data)
+}
+
+func (b *StringBuilder) appendOffset() {
+	if b.len == 0 {
+		b.offsets.resize(4)
+	}
+	n := (b.len + 1) * 4
+	b.offsets.resize(n + 4)
+	*(*int32)(unsafe.Pointer(&b.offsets.b[n])) = int32(len(b.data.b))
+}
+
+func (b *StringBuilder) appendValidity(valid bool) {
+	appendValidity(&b.validity, b.len, b.nulls, valid)
+	if !valid {
+		b.nulls++
+	}
+	b.len++
+}
+
+// appendValidity sets the validity bit of the i-th value of an array holding the given number
+// of nulls. The bitmap is only allocated once the first null is appended, as Arrow allows
+// leaving it out of arrays with no nulls.
+func appendValidity(validity *buffer, i, nulls int, valid bool) {
+	if valid && nulls == 0 {
+		return
+	}
+	n := int(bitutil.BytesForBits(int64(i + 1)))
+	if nulls == 0 {
+		// First null, so every value appended before it is valid.
+		validity.resize(n)
+		for j := 0; j < i; j++ {
+			bitutil.SetBit(validity.b, j)
+		}
+	} else {
+		validity.resize(n)
+	}
+	if valid {
+		bitutil.SetBit(validity.b, i)
+	}
+}
+
+// buffer is a growable Arrow buffer allocated from an arena.
+type buffer struct {
+	al *Allocator
+	b  []byte
+}
+
+// resize sets the length of the buffer, growing its capacity if needed.
+// Bytes past the previous length are zeroed.
+func (b *buffer) resize(n int) {
+	old := len(b.b)
+	if n > cap(b.b) {
+		b.b = b.al.Reallocate(max(n, 2*cap(b.b), alignment), b.b)
+	}
+	b.b = b.b[:n]
+	if n > old {
+		clear(b.b[old:])
+	}
+}
+
+// buffer returns an Arrow buffer wrapping the bytes of the buffer, or nil if it's empty.
+func (b *buffer) buffer() *memory.Buffer {
+	if len(b.b) == 0 {
+		return nil
+	}
+	return memory.NewBufferBytes(b.b)
+}
+
+func (b *buffer) reset() {
+	b.b = nil
+}
+
+func dataType(x any) arrow.DataType {
+	switch x.(type) {
+	case int8:
+		return arrow.PrimitiveTypes.Int8
+	case int16:
+		return arrow.PrimitiveTypes.Int16
+	case int32:
+		return arrow.PrimitiveTypes.Int32
+	case int64:
+		return arrow.PrimitiveTypes.Int64
+	case uint8:
+		return arrow.PrimitiveTypes.Uint8
+	case uint16:
+		return arrow.PrimitiveTypes.Uint16
+	case uint32:
+		return arrow.PrimitiveTypes.Uint32
+	case uint64:
+		return arrow.PrimitiveTypes.Uint64
+	case float32:
+		return arrow.PrimitiveTypes.Float32
+	default:
+		return arrow.PrimitiveTypes.Float64
+	}
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukearrow
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+	"github.com/ortuman/nuke/nuketest"
+)
+
+func TestAllocator(t *testing.T) {
+	a := nuke.NewMonotonicArena(64*1024, 1)
+	al := NewAllocator(a)
+
+	b := al.Allocate(10)
+	require.Len(t, b, 10)
+	require.Zero(t, uintptr(unsafe.Pointer(unsafe.SliceData(b)))%alignment)
+
+	copy(b, "0123456789")
+	b = al.Reallocate(100, b)
+	require.Len(t, b, 100)
+	require.Equal(t, "0123456789", string(b[:10]))
+	require.Zero(t, uintptr(unsafe.Pointer(unsafe.SliceData(b)))%alignment)
+
+	require.Nil(t, al.Allocate(0))
+	al.Free(nil)
+}
+
+func TestAllocatorBuilders(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	a := nuke.NewMonotonicArena(64*1024, 1)
+	al := NewAllocator(a)
+
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	rb := array.NewRecordBuilder(al, schema)
+	defer rb.Release()
+
+	for i := 0; i < 100; i++ {
+		rb.Field(0).(*array.Int64Builder).Append(int64(i))
+		if i%10 == 0 {
+			rb.Field(1).(*array.StringBuilder).AppendNull()
+		} else {
+			rb.Field(1).(*array.StringBuilder).Append("row")
+		}
+	}
+	rec := rb.NewRecord()
+	defer rec.Release()
+
+	require.Equal(t, int64(100), rec.NumRows())
+	require.Equal(t, int64(42), rec.Column(0).(*array.Int64).Value(42))
+	require.True(t, rec.Column(1).IsNull(50))
+	require.Equal(t, "row", rec.Column(1).(*array.String).Value(51))
+
+	m := a.(nuke.Stater).Metrics()
+	require.NotZero(t, m.Allocs)
+	require.Zero(t, m.HeapFallbacks)
+}
+
+func TestFixedWidthBuilder(t *testing.T) {
+	a := nuke.NewMonotonicArena(64*1024, 1)
+	b := NewFixedWidthBuilder[float64](a)
+
+	for i := 0; i < 100; i++ {
+		b.Append(float64(i) / 2)
+	}
+	arr := b.NewArray().(*array.Float64)
+	require.Equal(t, 100, arr.Len())
+	require.Zero(t, arr.NullN())
+	require.Equal(t, 21.0, arr.Value(42))
+	require.Zero(t, b.Len())
+
+	ib := NewFixedWidthBuilder[int32](a)
+	ib.Append(1)
+	ib.Append(2)
+	ib.AppendNull()
+	ib.Append(4)
+	ia := ib.NewArray().(*array.Int32)
+	require.Equal(t, arrow.PrimitiveTypes.Int32, ia.DataType())
+	require.Equal(t, 1, ia.NullN())
+	require.True(t, ia.IsValid(1))
+	require.True(t, ia.IsNull(2))
+	require.Equal(t, []int32{1, 2, 0, 4}, ia.Int32Values())
+
+	require.Zero(t, NewFixedWidthBuilder[int64](a).NewArray().Len())
+	require.Zero(t, a.(nuke.Stater).Metrics().HeapFallbacks)
+}
+
+func TestStringBuilder(t *testing.T) {
+	a := nuke.NewMonotonicArena(64*1024, 1)
+	b := NewStringBuilder(a)
+
+	b.Append("foo")
+	b.AppendNull()
+	b.AppendBytes([]byte("barbaz"))
+	b.Append("")
+	for i := 0; i < 100; i++ {
+		b.Append("x")
+	}
+	arr := b.NewArray()
+	require.Equal(t, 104, arr.Len())
+	require.Equal(t, 1, arr.NullN())
+	require.Equal(t, "foo", arr.Value(0))
+	require.True(t, arr.IsNull(1))
+	require.Equal(t, "barbaz", arr.Value(2))
+	require.Equal(t, "", arr.Value(3))
+	require.True(t, arr.IsValid(3))
+	require.Equal(t, "x", arr.Value(103))
+	require.Equal(t, []int32{0, 3, 3, 9}, arr.ValueOffsets()[:4])
+
+	require.Zero(t, NewStringBuilder(a).NewArray().Len())
+	require.Zero(t, a.(nuke.Stater).Metrics().HeapFallbacks)
+}
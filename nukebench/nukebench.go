@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukebench provides parametrized benchmarks to help choosing an arena
+// configuration for a given set of types.
+//
+// Benchmarks are meant to be invoked from a regular benchmark function:
+//
+//	func BenchmarkFoo(b *testing.B) {
+//		nukebench.New[Foo](b, nukebench.Config{
+//			Arenas: nukebench.MonotonicArenas([]int{64 * 1024, 1024 * 1024}, 16),
+//		})
+//	}
+package nukebench
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ortuman/nuke"
+)
+
+// ArenaConfig describes an arena to be benchmarked.
+type ArenaConfig struct {
+	// Name identifies the arena in the benchmark name.
+	Name string
+
+	// New returns a new instance of the arena.
+	New func() nuke.Arena
+}
+
+// Config describes the combinations to be benchmarked.
+type Config struct {
+	// ObjectCounts are the number of allocations performed between arena resets.
+	// Defaults to 100, 1000 and 10000.
+	ObjectCounts []int
+
+	// Concurrency are the number of goroutines allocating from the arena at the same time.
+	// Arenas are wrapped with nuke.NewConcurrentArena when greater than one. Defaults to 1.
+	Concurrency []int
+
+	// Arenas are the arenas to benchmark. Go's runtime allocator is always benchmarked as a baseline.
+	Arenas []ArenaConfig
+}
+
+// MonotonicArenas returns a monotonic arena configuration for each of the given buffer sizes.
+func MonotonicArenas(bufferSizes []int, bufferCount int) []ArenaConfig {
+	var configs []ArenaConfig
+	for _, bufferSize := range bufferSizes {
+		bufferSize := bufferSize
+		configs = append(configs, ArenaConfig{
+			Name: fmt.Sprintf("monotonic-%dx%d", bufferCount, bufferSize),
+			New: func() nuke.Arena {
+				return nuke.NewMonotonicArena(bufferSize, bufferCount)
+			},
+		})
+	}
+	return configs
+}
+
+// New benchmarks the allocation of values of type T with nuke.New.
+func New[T any](b *testing.B, cfg Config) {
+	run(b, cfg, func(a nuke.Arena) { _ = nuke.New[T](a) })
+}
+
+// MakeSlice benchmarks the allocation of slices of type T with nuke.MakeSlice.
+func MakeSlice[T any](b *testing.B, cfg Config, len, cap int) {
+	run(b, cfg, func(a nuke.Arena) { _ = nuke.MakeSlice[T](a, len, cap) })
+}
+
+func run(b *testing.B, cfg Config, alloc func(a nuke.Arena)) {
+	objectCounts := cfg.ObjectCounts
+	if len(objectCounts) == 0 {
+		objectCounts = []int{100, 1_000, 10_000}
+	}
+	concurrency := cfg.Concurrency
+	if len(concurrency) == 0 {
+		concurrency = []int{1}
+	}
+	arenas := append([]ArenaConfig{{Name: "runtime"}}, cfg.Arenas...)
+
+	for _, ac := range arenas {
+		for _, c := range concurrency {
+			for _, objectCount := range objectCounts {
+				b.Run(fmt.Sprintf("%s/goroutines=%d/objects=%d", ac.Name, c, objectCount), func(b *testing.B) {
+					var a nuke.Arena
+					if ac.New != nil {
+						a = ac.New()
+						if c > 1 {
+							a = nuke.NewConcurrentArena(a)
+						}
+					}
+					b.ReportAllocs()
+					b.ResetTimer()
+
+					for i := 0; i < b.N; i++ {
+						allocN(a, c, objectCount, alloc)
+						if a != nil {
+							a.Reset(false)
+						}
+					}
+				})
+			}
+		}
+	}
+}
+
+func allocN(a nuke.Arena, concurrency, n int, alloc func(a nuke.Arena)) {
+	if concurrency == 1 {
+		for i := 0; i < n; i++ {
+			alloc(a)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for g := 0; g < concurrency; g++ {
+		wg.Add(1)
+		go func(count int) {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				alloc(a)
+			}
+		}(n/concurrency + boolToInt(g < n%concurrency))
+	}
+	wg.Wait()
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukebench
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+)
+
+type object struct {
+	a byte
+	b int
+	c uint64
+	d complex128
+}
+
+func TestAllocNSplitsWork(t *testing.T) {
+	for _, concurrency := range []int{1, 3, 4} {
+		var count atomic.Int64
+		allocN(nil, concurrency, 10, func(nuke.Arena) { count.Add(1) })
+		require.Equal(t, int64(10), count.Load())
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	New[object](b, Config{
+		Arenas: MonotonicArenas([]int{64 * 1024, 1024 * 1024}, 16),
+	})
+}
+
+func BenchmarkMakeSlice(b *testing.B) {
+	MakeSlice[object](b, Config{
+		Arenas: MonotonicArenas([]int{1024 * 1024}, 16),
+	}, 0, 256)
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukebinary runs the encoding/binary and encoding/gob encoders over buffers allocated
+// from an arena, and provides fixed-layout writers copying pointer-free values as they are laid
+// out in memory, so snapshotting state doesn't double its memory with heap encode buffers.
+package nukebinary
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"reflect"
+	"unsafe"
+
+	"github.com/ortuman/nuke"
+)
+
+// ErrShortBuffer is the error returned when reading a value from fewer bytes than it takes.
+var ErrShortBuffer = errors.New("nukebinary: short buffer")
+
+// Write returns the binary representation of data, as encoded by binary.Write with the given
+// byte order, in memory allocated from the arena.
+func Write(a nuke.Arena, order binary.ByteOrder, data any) ([]byte, error) {
+	buf := nuke.NewBuffer(a, max(binary.Size(data), 0))
+	if err := binary.Write(buf, order, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobEncoder writes a stream of gob-encoded values to a buffer allocated from an arena.
+// Like with gob.Encoder, type information is only sent the first time a type is encoded,
+// so the stream must be decoded by a single gob.Decoder.
+type GobEncoder struct {
+	buf *nuke.Buffer
+	enc *gob.Encoder
+}
+
+// NewGobEncoder returns a GobEncoder writing to a buffer allocated from the arena,
+// with room for size bytes.
+func NewGobEncoder(a nuke.Arena, size int) *GobEncoder {
+	buf := nuke.NewBuffer(a, size)
+	return &GobEncoder{buf: buf, enc: gob.NewEncoder(buf)}
+}
+
+// Encode appends the gob encoding of v to the buffer.
+func (e *GobEncoder) Encode(v any) error {
+	return e.enc.Encode(v)
+}
+
+// Bytes returns the stream encoded so far, which remains valid until the next call
+// to Encode or Reset, or until the arena is reset.
+func (e *GobEncoder) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// Reset discards the stream encoded so far, keeping the buffer for future values.
+// Since the stream is restarted, type information is sent again.
+func (e *GobEncoder) Reset() {
+	e.buf.Reset()
+	e.enc = gob.NewEncoder(e.buf)
+}
+
+// EncodeGob returns the gob encoding of v, including its type information,
+// in memory allocated from the arena.
+func EncodeGob(a nuke.Arena, v any) ([]byte, error) {
+	e := NewGobEncoder(a, 0)
+	if err := e.Encode(v); err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
+
+// PutStruct appends the bytes of v, as laid out in memory, to the buffer. The layout, padding
+// and byte order are those of the platform, so the output is only meant to be read back
+// with Struct by a program built for the same architecture.
+// PutStruct panics if T holds pointers, which would be meaningless once written.
+func PutStruct[T any](b *nuke.Buffer, v *T) {
+	_, _ = b.Write(structBytes(v))
+}
+
+// AppendStruct is like PutStruct, but appends the bytes of v to a slice allocated from the arena.
+func AppendStruct[T any](a nuke.Arena, s []byte, v *T) []byte {
+	return nuke.SliceAppend(a, s, structBytes(v)...)
+}
+
+// Struct reads a value of type T written by PutStruct from the beginning of p, returning
+// the number of bytes read. It returns ErrShortBuffer if p is shorter than the value.
+// Struct panics if T holds pointers.
+func Struct[T any](p []byte) (T, int, error) {
+	var v T
+	vb := structBytes(&v)
+	if len(p) < len(vb) {
+		return v, 0, ErrShortBuffer
+	}
+	return v, copy(vb, p), nil
+}
+
+// structBytes returns the bytes of the value pointed to by v.
+func structBytes[T any](v *T) []byte {
+	if typ := reflect.TypeOf(v).Elem(); !nuke.PointerFree(typ) {
+		panic("nukebinary: type " + typ.String() + " holds pointers")
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(v)), unsafe.Sizeof(*v))
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukebinary
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+)
+
+type header struct {
+	Version uint16
+	Flags   uint16
+	Length  uint32
+	Sums    [2]uint64
+}
+
+func TestWrite(t *testing.T) {
+	arena := nuke.NewMonotonicArena(4096, 1)
+
+	h := header{Version: 1, Flags: 2, Length: 3, Sums: [2]uint64{4, 5}}
+	var want bytes.Buffer
+	require.NoError(t, binary.Write(&want, binary.BigEndian, &h))
+
+	got, err := Write(arena, binary.BigEndian, &h)
+	require.NoError(t, err)
+	require.Equal(t, want.Bytes(), got)
+
+	_, err = Write(arena, binary.BigEndian, "not fixed-size")
+	require.Error(t, err)
+}
+
+type state struct {
+	Name  string
+	Items map[string]int
+}
+
+func TestGob(t *testing.T) {
+	arena := nuke.NewMonotonicArena(64*1024, 1)
+
+	e := NewGobEncoder(arena, 256)
+	in := []state{{Name: "a", Items: map[string]int{"x": 1}}, {Name: "b"}}
+	for i := range in {
+		require.NoError(t, e.Encode(&in[i]))
+	}
+
+	dec := gob.NewDecoder(bytes.NewReader(e.Bytes()))
+	for i := range in {
+		var out state
+		require.NoError(t, dec.Decode(&out))
+		require.Equal(t, in[i], out)
+	}
+
+	e.Reset()
+	require.NoError(t, e.Encode(&in[1]))
+	var out state
+	require.NoError(t, gob.NewDecoder(bytes.NewReader(e.Bytes())).Decode(&out))
+	require.Equal(t, in[1], out)
+
+	b, err := EncodeGob(arena, &in[0])
+	require.NoError(t, err)
+	out = state{}
+	require.NoError(t, gob.NewDecoder(bytes.NewReader(b)).Decode(&out))
+	require.Equal(t, in[0], out)
+}
+
+func TestStruct(t *testing.T) {
+	arena := nuke.NewMonotonicArena(4096, 1)
+
+	h1 := header{Version: 1, Length: 10, Sums: [2]uint64{1, 2}}
+	h2 := header{Version: 2, Flags: 1}
+
+	buf := nuke.NewBuffer(arena, 0)
+	PutStruct(buf, &h1)
+	s := AppendStruct(arena, buf.Bytes(), &h2)
+
+	got1, n, err := Struct[header](s)
+	require.NoError(t, err)
+	require.Equal(t, h1, got1)
+
+	got2, _, err := Struct[header](s[n:])
+	require.NoError(t, err)
+	require.Equal(t, h2, got2)
+
+	_, _, err = Struct[header](s[n+1:])
+	require.ErrorIs(t, err, ErrShortBuffer)
+
+	require.Panics(t, func() { PutStruct(buf, &state{}) })
+}
@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Command nukecheck reports arena allocations of types holding pointers.
+//
+// It is meant to be run through go vet:
+//
+//	go install github.com/ortuman/nuke/nukecheck/cmd/nukecheck@latest
+//	go vet -vettool=$(which nukecheck) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/ortuman/nuke/nukecheck"
+)
+
+func main() {
+	unitchecker.Main(nukecheck.Analyzer)
+}
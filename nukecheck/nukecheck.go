@@ -0,0 +1,313 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukecheck defines an analyzer reporting arena allocations of types holding
+// Go pointers, which the garbage collector can't see when stored in arena memory.
+package nukecheck
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const nukePath = "github.com/ortuman/nuke"
+
+const doc = `report arena allocations of types holding pointers
+
+Arena memory is not scanned by the garbage collector, so values allocated with
+nuke.New, nuke.NewPtr, nuke.MakeSlice, nuke.SliceAppend or nuke.NewTypedArena
+must not hold pointers, strings, slices, maps, channels, functions or
+interfaces, unless the arena has been created with nuke.NewGCArena. The default
+arena nuke.NewDefault and nuke.MakeDefaultSlice allocate from is assumed not to
+be a GC arena. nuke.NewOf and nuke.MakeSliceOf are checked when their
+reflect.Type argument is built from a static type, while nuke.AllocAligned must
+never allocate pointer-holding types, as its memory carries no type information.`
+
+// Analyzer reports arena allocations of pointer-holding types.
+var Analyzer = &analysis.Analyzer{
+	Name:     "nukecheck",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// defaultArena stands for the arena parameter of the functions allocating from the default arena.
+const defaultArena = -1
+
+// allocFuncs are the generic functions allocating their type argument from an arena,
+// mapped to the index of their arena parameter.
+var allocFuncs = map[string]int{
+	"New":              0,
+	"NewPtr":           0,
+	"MakeSlice":        0,
+	"SliceAppend":      0,
+	"NewTypedArena":    0,
+	"NewDefault":       defaultArena,
+	"MakeDefaultSlice": defaultArena,
+	"AllocAligned":     0,
+	"NewOf":            0,
+	"MakeSliceOf":      0,
+}
+
+// untypedFuncs are the allocFuncs whose memory carries no type information,
+// so that it isn't scanned even when allocated from a GC arena or the heap.
+var untypedFuncs = map[string]bool{
+	"AllocAligned": true,
+}
+
+// reflectFuncs are the allocFuncs taking the type to allocate as their second argument,
+// a reflect.Type, mapped to whether it's a slice type whose elements get allocated.
+var reflectFuncs = map[string]bool{
+	"NewOf":       false,
+	"MakeSliceOf": true,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	gcArenas := collectGCArenas(pass, insp)
+
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != nukePath {
+			return
+		}
+		arenaIdx, ok := allocFuncs[fn.Name()]
+		if !ok || arenaIdx >= len(call.Args) {
+			return
+		}
+		typ := allocatedType(pass, call, fn.Name())
+		if typ == nil || !hasPointers(typ, nil) {
+			return
+		}
+		if untypedFuncs[fn.Name()] {
+			pass.Reportf(call.Pos(), "nuke.%s allocates %s, which holds pointers, into memory carrying no type information",
+				fn.Name(), types.TypeString(typ, types.RelativeTo(pass.Pkg)),
+			)
+			return
+		}
+		if arenaIdx != defaultArena {
+			arena := ast.Unparen(call.Args[arenaIdx])
+			if isNil(pass, arena) || isGCArena(pass, arena, gcArenas) {
+				return
+			}
+		}
+		pass.Reportf(call.Pos(), "nuke.%s allocates %s, which holds pointers, from an arena the garbage collector doesn't scan (use nuke.NewGCArena)",
+			fn.Name(), types.TypeString(typ, types.RelativeTo(pass.Pkg)),
+		)
+	})
+	return nil, nil
+}
+
+// allocatedType returns the type of the values the call to the allocation function fn allocates,
+// or nil if it can't be determined statically.
+func allocatedType(pass *analysis.Pass, call *ast.CallExpr, fn string) types.Type {
+	slice, ok := reflectFuncs[fn]
+	if !ok {
+		return typeArgument(pass, call)
+	}
+	if len(call.Args) < 2 {
+		return nil
+	}
+	typ := reflectedType(pass, call.Args[1])
+	if typ == nil || !slice {
+		return typ
+	}
+	if s, ok := typ.Underlying().(*types.Slice); ok {
+		return s.Elem()
+	}
+	return nil
+}
+
+// reflectedType returns the type represented by the reflect.Type expression expr, as built by
+// reflect.TypeFor, reflect.TypeOf, reflect.PointerTo, reflect.SliceOf or the Elem method,
+// or nil if it can't be determined statically.
+func reflectedType(pass *analysis.Pass, expr ast.Expr) types.Type {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "reflect" {
+		return nil
+	}
+	switch fn.Name() {
+	case "TypeFor":
+		return typeArgument(pass, call)
+	case "TypeOf":
+		if len(call.Args) != 1 {
+			return nil
+		}
+		typ := pass.TypesInfo.TypeOf(call.Args[0])
+		if typ == nil || types.IsInterface(typ) {
+			return nil // the dynamic type is only known at run time
+		}
+		return typ
+	case "PointerTo", "PtrTo":
+		if elem := reflectedType(pass, call.Args[0]); elem != nil {
+			return types.NewPointer(elem)
+		}
+	case "SliceOf":
+		if elem := reflectedType(pass, call.Args[0]); elem != nil {
+			return types.NewSlice(elem)
+		}
+	case "Elem":
+		sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+		if !ok {
+			return nil
+		}
+		typ := reflectedType(pass, sel.X)
+		if typ == nil {
+			return nil
+		}
+		switch t := typ.Underlying().(type) {
+		case *types.Pointer:
+			return t.Elem()
+		case *types.Slice:
+			return t.Elem()
+		}
+	}
+	return nil
+}
+
+// typeArgument returns the type the call instantiates its callee with.
+func typeArgument(pass *analysis.Pass, call *ast.CallExpr) types.Type {
+	fun := ast.Unparen(call.Fun)
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+	var id *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return nil
+	}
+	inst, ok := pass.TypesInfo.Instances[id]
+	if !ok || inst.TypeArgs.Len() == 0 {
+		return nil
+	}
+	typ := inst.TypeArgs.At(0)
+	if _, ok := typ.(*types.TypeParam); ok {
+		return nil // can't tell until instantiated
+	}
+	return typ
+}
+
+// collectGCArenas returns the variables that are only ever assigned GC arenas.
+func collectGCArenas(pass *analysis.Pass, insp *inspector.Inspector) map[types.Object]bool {
+	assigned := make(map[types.Object]bool)
+
+	record := func(lhs ast.Expr, rhs ast.Expr) {
+		id, ok := ast.Unparen(lhs).(*ast.Ident)
+		if !ok {
+			return
+		}
+		obj := pass.TypesInfo.ObjectOf(id)
+		if obj == nil {
+			return
+		}
+		isGC := rhs != nil && isGCArenaConstructor(pass, rhs)
+		if prev, ok := assigned[obj]; ok {
+			isGC = isGC && prev
+		}
+		assigned[obj] = isGC
+	}
+
+	insp.Preorder([]ast.Node{(*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)}, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				var rhs ast.Expr
+				if len(n.Lhs) == len(n.Rhs) {
+					rhs = n.Rhs[i]
+				}
+				record(lhs, rhs)
+			}
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				var rhs ast.Expr
+				if len(n.Names) == len(n.Values) {
+					rhs = n.Values[i]
+				}
+				record(name, rhs)
+			}
+		}
+	})
+	return assigned
+}
+
+func isGCArena(pass *analysis.Pass, arena ast.Expr, gcArenas map[types.Object]bool) bool {
+	if id, ok := arena.(*ast.Ident); ok {
+		return gcArenas[pass.TypesInfo.ObjectOf(id)]
+	}
+	return isGCArenaConstructor(pass, arena)
+}
+
+// isGCArenaConstructor reports whether expr creates a GC arena, possibly wrapped into a concurrent one.
+func isGCArenaConstructor(pass *analysis.Pass, expr ast.Expr) bool {
+	expr = ast.Unparen(expr)
+	if ta, ok := expr.(*ast.TypeAssertExpr); ok {
+		expr = ast.Unparen(ta.X)
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != nukePath {
+		return false
+	}
+	switch fn.Name() {
+	case "NewGCArena":
+		return true
+	case "NewConcurrentArena":
+		return len(call.Args) == 1 && isGCArenaConstructor(pass, call.Args[0])
+	default:
+		return false
+	}
+}
+
+func isNil(pass *analysis.Pass, expr ast.Expr) bool {
+	tv, ok := pass.TypesInfo.Types[expr]
+	return ok && tv.IsNil()
+}
+
+// hasPointers reports whether values of type typ hold any pointer the garbage collector must be aware of.
+func hasPointers(typ types.Type, seen map[types.Type]bool) bool {
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		return t.Kind() == types.String || t.Kind() == types.UnsafePointer || t.Kind() == types.UntypedNil
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return true
+	case *types.Array:
+		return t.Len() > 0 && hasPointers(t.Elem(), seen)
+	case *types.Struct:
+		if seen == nil {
+			seen = make(map[types.Type]bool)
+		}
+		if seen[typ] {
+			return false
+		}
+		seen[typ] = true
+		for i := 0; i < t.NumFields(); i++ {
+			if hasPointers(t.Field(i).Type(), seen) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
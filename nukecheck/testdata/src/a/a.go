@@ -0,0 +1,107 @@
+package a
+
+import (
+	"reflect"
+
+	"github.com/ortuman/nuke"
+)
+
+type plain struct {
+	a int
+	b [4]float64
+}
+
+type withString struct {
+	id   int
+	name string
+}
+
+type node struct {
+	next *node
+}
+
+type nested struct {
+	inner withString
+}
+
+func allocations() {
+	arena := nuke.NewMonotonicArena(1024, 1)
+
+	_ = nuke.New[int](arena)
+	_ = nuke.New[plain](arena)
+	_ = nuke.NewPtr[plain](arena)
+	_ = nuke.MakeSlice[plain](arena, 0, 10)
+
+	_ = nuke.New[withString](arena)            // want `nuke.New allocates withString, which holds pointers`
+	_ = nuke.New[node](arena)                  // want `nuke.New allocates node, which holds pointers`
+	_ = nuke.New[nested](arena)                // want `nuke.New allocates nested, which holds pointers`
+	_ = nuke.NewPtr[node](arena)               // want `nuke.NewPtr allocates node, which holds pointers`
+	_ = nuke.MakeSlice[string](arena, 0, 10)   // want `nuke.MakeSlice allocates string, which holds pointers`
+	_ = nuke.SliceAppend(arena, []*int{}, nil) // want `nuke.SliceAppend allocates \*int, which holds pointers`
+	_ = nuke.NewTypedArena[map[int]int](arena) // want `nuke.NewTypedArena allocates map\[int\]int, which holds pointers`
+
+	// Heap allocations are fine.
+	_ = nuke.New[withString](nil)
+}
+
+func defaultArena() {
+	_ = nuke.NewDefault[plain]()
+	_ = nuke.MakeDefaultSlice[int](0, 10)
+
+	_ = nuke.NewDefault[node]()                  // want `nuke.NewDefault allocates node, which holds pointers`
+	_ = nuke.MakeDefaultSlice[withString](0, 10) // want `nuke.MakeDefaultSlice allocates withString, which holds pointers`
+}
+
+func alignedAllocations() {
+	arena := nuke.NewMonotonicArena(1024, 1)
+
+	_ = nuke.AllocAligned[plain](arena, 64)
+
+	// The memory isn't typed, so GC arenas and the heap don't help.
+	_ = nuke.AllocAligned[node](arena, 64)                 // want `nuke.AllocAligned allocates node, which holds pointers, into memory carrying no type information`
+	_ = nuke.AllocAligned[node](nuke.NewGCArena(1024), 64) // want `nuke.AllocAligned allocates node, which holds pointers`
+	_ = nuke.AllocAligned[withString](nil, 64)             // want `nuke.AllocAligned allocates withString, which holds pointers`
+}
+
+func reflectAllocations(v any) {
+	arena := nuke.NewMonotonicArena(1024, 1)
+
+	_ = nuke.NewOf(arena, reflect.TypeFor[plain]())
+	_ = nuke.MakeSliceOf(arena, reflect.TypeFor[[]int](), 0, 10)
+	_ = nuke.MakeSliceOf(arena, reflect.SliceOf(reflect.TypeOf(plain{})), 0, 10)
+
+	_ = nuke.NewOf(arena, reflect.TypeFor[node]())                               // want `nuke.NewOf allocates node, which holds pointers`
+	_ = nuke.NewOf(arena, reflect.TypeOf(withString{}))                          // want `nuke.NewOf allocates withString, which holds pointers`
+	_ = nuke.NewOf(arena, reflect.TypeOf((*nested)(nil)).Elem())                 // want `nuke.NewOf allocates nested, which holds pointers`
+	_ = nuke.MakeSliceOf(arena, reflect.TypeFor[[]string](), 0, 10)              // want `nuke.MakeSliceOf allocates string, which holds pointers`
+	_ = nuke.MakeSliceOf(arena, reflect.SliceOf(reflect.TypeFor[*int]()), 0, 10) // want `nuke.MakeSliceOf allocates \*int, which holds pointers`
+
+	// Types only known at run time can't be checked.
+	_ = nuke.NewOf(arena, reflect.TypeOf(v))
+
+	gc := nuke.NewGCArena(1024)
+	_ = nuke.NewOf(gc, reflect.TypeFor[node]())
+	_ = nuke.MakeSliceOf(nil, reflect.TypeFor[[]string](), 0, 10)
+}
+
+func gcArenas() {
+	gc := nuke.NewGCArena(1024)
+	_ = nuke.New[withString](gc)
+
+	var cgc = nuke.NewConcurrentArena(nuke.NewGCArena(1024))
+	_ = nuke.New[node](cgc)
+	_ = nuke.NewPtr[node](cgc)
+
+	_ = nuke.MakeSlice[string](nuke.NewGCArena(1024), 0, 10)
+
+	asserted := nuke.NewGCArena(1024).(nuke.Arena)
+	_ = nuke.New[node](asserted)
+
+	reassigned := nuke.NewGCArena(1024)
+	reassigned = nuke.NewMonotonicArena(1024, 1)
+	_ = nuke.New[node](reassigned) // want `nuke.New allocates node, which holds pointers`
+}
+
+func generic[T any](a nuke.Arena) *T {
+	return nuke.New[T](a)
+}
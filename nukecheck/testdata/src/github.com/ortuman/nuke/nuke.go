@@ -0,0 +1,32 @@
+package nuke
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+type Arena interface {
+	Alloc(size, alignment uintptr) unsafe.Pointer
+	Reset(release bool)
+}
+
+type TypedArena[T any] struct{}
+
+type Ptr[T any] struct{ p *T }
+
+func New[T any](a Arena) *T                               { return new(T) }
+func NewPtr[T any](a Arena) Ptr[T]                        { return Ptr[T]{new(T)} }
+func MakeSlice[T any](a Arena, len, cap int) []T          { return make([]T, len, cap) }
+func SliceAppend[T any](a Arena, s []T, data ...T) []T    { return append(s, data...) }
+func NewTypedArena[T any](a Arena) *TypedArena[T]         { return nil }
+func NewDefault[T any]() *T                               { return new(T) }
+func MakeDefaultSlice[T any](len, cap int) []T            { return make([]T, len, cap) }
+func NewMonotonicArena(bufferSize, bufferCount int) Arena { return nil }
+func NewGCArena(chunkSize int) Arena                      { return nil }
+func NewConcurrentArena(a Arena) Arena                    { return a }
+func AllocAligned[T any](a Arena, alignment uintptr) *T   { return new(T) }
+func NewOf(a Arena, typ reflect.Type) reflect.Value       { return reflect.New(typ) }
+
+func MakeSliceOf(a Arena, typ reflect.Type, len, cap int) reflect.Value {
+	return reflect.MakeSlice(typ, len, cap)
+}
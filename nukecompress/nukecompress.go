@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukecompress provides compression helpers whose output is allocated from an arena.
+//
+// The compressors of the standard library keep large internal state, such as the 32KB window of
+// flate, which their APIs don't allow to be supplied from outside. Gzip and flate writers and readers
+// are thus pooled and reused through their Reset methods, so that only the compressed or decompressed
+// output is allocated, from the arena, on every call.
+package nukecompress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/ortuman/nuke"
+)
+
+// ErrTooLarge is the error returned when decompressed data exceeds the size limit.
+var ErrTooLarge = errors.New("nukecompress: decompressed data too large")
+
+// ErrInvalidLevel is the error returned when compressing with an unknown compression level.
+var ErrInvalidLevel = errors.New("nukecompress: invalid compression level")
+
+// BlockEncoder is implemented by encoders compressing whole blocks of data in memory,
+// such as zstd.Encoder from github.com/klauspost/compress/zstd.
+type BlockEncoder interface {
+	EncodeAll(src, dst []byte) []byte
+	MaxEncodedSize(size int) int
+}
+
+// BlockDecoder is implemented by decoders decompressing whole blocks of data in memory,
+// such as zstd.Decoder from github.com/klauspost/compress/zstd.
+type BlockDecoder interface {
+	DecodeAll(input, dst []byte) ([]byte, error)
+}
+
+// gzipWriters and flateWriters hold pools of writers for every compression level,
+// from HuffmanOnly (-2) to BestCompression (9).
+var (
+	gzipWriters  [12]sync.Pool
+	flateWriters [12]sync.Pool
+	gzipReaders  sync.Pool
+	flateReaders sync.Pool
+)
+
+// Gzip compresses src in gzip format with the given level, returning the output in memory
+// allocated from the arena.
+func Gzip(a nuke.Arena, src []byte, level int) ([]byte, error) {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		return nil, ErrInvalidLevel
+	}
+	buf := nuke.NewBuffer(a, len(src)/2+64)
+	pool := &gzipWriters[level-flate.HuffmanOnly]
+	w, _ := pool.Get().(*gzip.Writer)
+	if w == nil {
+		w, _ = gzip.NewWriterLevel(buf, level)
+	} else {
+		w.Reset(buf)
+	}
+	defer pool.Put(w)
+
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Gunzip decompresses the gzip data read from r, returning the output in memory allocated from the
+// arena, or ErrTooLarge as soon as it's found to exceed limit bytes.
+func Gunzip(a nuke.Arena, r io.Reader, limit int64) ([]byte, error) {
+	zr, _ := gzipReaders.Get().(*gzip.Reader)
+	var err error
+	if zr == nil {
+		zr, err = gzip.NewReader(r)
+	} else {
+		err = zr.Reset(r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReaders.Put(zr)
+	return readAll(a, zr, limit)
+}
+
+// Deflate compresses src in raw flate format with the given level, returning the output in memory
+// allocated from the arena.
+func Deflate(a nuke.Arena, src []byte, level int) ([]byte, error) {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		return nil, ErrInvalidLevel
+	}
+	buf := nuke.NewBuffer(a, len(src)/2+64)
+	pool := &flateWriters[level-flate.HuffmanOnly]
+	w, _ := pool.Get().(*flate.Writer)
+	if w == nil {
+		w, _ = flate.NewWriter(buf, level)
+	} else {
+		w.Reset(buf)
+	}
+	defer pool.Put(w)
+
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Inflate decompresses the raw flate data read from r, returning the output in memory allocated from
+// the arena, or ErrTooLarge as soon as it's found to exceed limit bytes.
+func Inflate(a nuke.Arena, r io.Reader, limit int64) ([]byte, error) {
+	// Readers not implementing io.ByteReader would be wrapped by a new bufio.Reader on every Reset.
+	if _, ok := r.(io.ByteReader); !ok {
+		r = bufio.NewReader(r)
+	}
+	fr, _ := flateReaders.Get().(io.ReadCloser)
+	if fr == nil {
+		fr = flate.NewReader(r)
+	} else if err := fr.(flate.Resetter).Reset(r, nil); err != nil {
+		return nil, err
+	}
+	defer flateReaders.Put(fr)
+	return readAll(a, fr, limit)
+}
+
+// EncodeAll compresses src with the encoder into memory allocated from the arena,
+// sized after the maximum size the encoder reports for the output.
+func EncodeAll(a nuke.Arena, enc BlockEncoder, src []byte) []byte {
+	return enc.EncodeAll(src, nuke.MakeSlice[byte](a, 0, enc.MaxEncodedSize(len(src))))
+}
+
+// DecodeAll decompresses src with the decoder into memory allocated from the arena, with room
+// for sizeHint bytes. Output exceeding it is allocated from the heap by the decoder itself.
+func DecodeAll(a nuke.Arena, dec BlockDecoder, src []byte, sizeHint int) ([]byte, error) {
+	return dec.DecodeAll(src, nuke.MakeSlice[byte](a, 0, sizeHint))
+}
+
+func readAll(a nuke.Arena, r io.Reader, limit int64) ([]byte, error) {
+	// Reading one byte more than the limit tells apart the inputs exceeding it, unless there's no limit.
+	n := limit
+	if n < math.MaxInt64 {
+		n++
+	}
+	buf := nuke.NewBuffer(a, 0)
+	if _, err := buf.ReadFrom(io.LimitReader(r, n)); err != nil {
+		return nil, err
+	}
+	if int64(buf.Len()) > limit {
+		return nil, ErrTooLarge
+	}
+	return buf.Bytes(), nil
+}
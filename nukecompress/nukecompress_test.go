@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukecompress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+)
+
+var data = []byte(strings.Repeat("nuke arena compression ", 1000))
+
+func TestGzip(t *testing.T) {
+	arena := nuke.NewMonotonicArena(256*1024, 1)
+
+	for i := 0; i < 2; i++ { // the second round reuses the pooled writer and reader
+		compressed, err := Gzip(arena, data, gzip.BestSpeed)
+		require.NoError(t, err)
+
+		zr, err := gzip.NewReader(bytes.NewReader(compressed))
+		require.NoError(t, err)
+		plain, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		require.Equal(t, data, plain)
+
+		got, err := Gunzip(arena, bytes.NewReader(compressed), int64(len(data)))
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+
+		_, err = Gunzip(arena, bytes.NewReader(compressed), int64(len(data)-1))
+		require.ErrorIs(t, err, ErrTooLarge)
+
+		got, err = Gunzip(arena, bytes.NewReader(compressed), math.MaxInt64)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+	}
+	_, err := Gzip(arena, data, 42)
+	require.ErrorIs(t, err, ErrInvalidLevel)
+
+	_, err = Gunzip(arena, strings.NewReader("not gzip"), 1024)
+	require.Error(t, err)
+}
+
+func TestDeflate(t *testing.T) {
+	arena := nuke.NewMonotonicArena(256*1024, 1)
+
+	for i := 0; i < 2; i++ {
+		compressed, err := Deflate(arena, data, flate.DefaultCompression)
+		require.NoError(t, err)
+
+		plain, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+		require.NoError(t, err)
+		require.Equal(t, data, plain)
+
+		got, err := Inflate(arena, bytes.NewReader(compressed), int64(len(data)))
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+
+		got, err = Inflate(arena, bytes.NewReader(compressed), math.MaxInt64)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+	}
+	_, err := Deflate(arena, data, -3)
+	require.ErrorIs(t, err, ErrInvalidLevel)
+}
+
+type copyCodec struct{}
+
+func (copyCodec) EncodeAll(src, dst []byte) []byte          { return append(dst, src...) }
+func (copyCodec) MaxEncodedSize(size int) int               { return size }
+func (copyCodec) DecodeAll(src, dst []byte) ([]byte, error) { return append(dst, src...), nil }
+
+func TestBlockCodec(t *testing.T) {
+	arena := nuke.NewMonotonicArena(64*1024, 1)
+
+	encoded := EncodeAll(arena, copyCodec{}, data)
+	require.Equal(t, data, encoded)
+
+	decoded, err := DecodeAll(arena, copyCodec{}, encoded, len(data))
+	require.NoError(t, err)
+	require.Equal(t, data, decoded)
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukecsv provides a CSV reader returning records allocated from an arena.
+package nukecsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"unsafe"
+
+	"github.com/ortuman/nuke"
+)
+
+// Reader reads records from a CSV-encoded file like csv.Reader does, which it embeds so that
+// it can be configured the same way, except that the records it returns, along with their
+// fields, are allocated from an arena. Records are thus only valid until the arena is reset,
+// which makes it possible to read a large file in batches, resetting the arena once each batch
+// has been processed, without leaving any garbage behind.
+//
+// Records are slices of strings, which hold pointers, so the arena must be created with
+// nuke.NewGCArena for them to be safe, as with any other type holding pointers.
+type Reader struct {
+	*csv.Reader
+	a nuke.Arena
+}
+
+// NewReader returns a Reader reading from r and allocating records from the arena.
+func NewReader(a nuke.Arena, r io.Reader) *Reader {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	return &Reader{Reader: cr, a: a}
+}
+
+// Read reads one record from r, allocating it from the arena.
+// Errors are reported like csv.Reader.Read does.
+func (r *Reader) Read() ([]string, error) {
+	record, err := r.Reader.Read()
+	if record == nil {
+		return nil, err
+	}
+	return r.copyRecord(record), err
+}
+
+// ReadAll reads all the remaining records from r, allocating them from the arena.
+// A successful call returns err == nil, not err == io.EOF.
+func (r *Reader) ReadAll() ([][]string, error) {
+	records := nuke.MakeSlice[[]string](r.a, 0, 16)
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = nuke.SliceAppend(r.a, records, record)
+	}
+}
+
+// copyRecord copies the fields of record into a single arena allocation.
+func (r *Reader) copyRecord(record []string) []string {
+	size := 0
+	for _, f := range record {
+		size += len(f)
+	}
+	buf := nuke.MakeSlice[byte](r.a, 0, size)
+	out := nuke.MakeSlice[string](r.a, len(record), len(record))
+	for i, f := range record {
+		off := len(buf)
+		buf = append(buf, f...)
+		out[i] = unsafe.String(unsafe.SliceData(buf[off:]), len(f))
+	}
+	return out
+}
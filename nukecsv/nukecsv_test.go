@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukecsv
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+)
+
+const data = `name,city
+"Smith, John",Madrid
+Jane,"New
+York"
+`
+
+func TestReader(t *testing.T) {
+	arena := nuke.NewGCArena(4096)
+
+	r := NewReader(arena, strings.NewReader(data))
+	first, err := r.Read()
+	require.NoError(t, err)
+
+	// Records are not reused across reads.
+	second, err := r.Read()
+	require.NoError(t, err)
+	require.Equal(t, []string{"name", "city"}, first)
+	require.Equal(t, []string{"Smith, John", "Madrid"}, second)
+
+	rest, err := r.ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"Jane", "New\nYork"}}, rest)
+}
+
+func TestReaderErrors(t *testing.T) {
+	r := NewReader(nuke.NewGCArena(4096), strings.NewReader("a,b\nc\n"))
+	_, err := r.ReadAll()
+
+	var perr *csv.ParseError
+	require.ErrorAs(t, err, &perr)
+	require.ErrorIs(t, err, csv.ErrFieldCount)
+}
+
+func TestReaderConfig(t *testing.T) {
+	r := NewReader(nuke.NewGCArena(4096), strings.NewReader("a;b\n# comment\nc;d\n"))
+	r.Comma = ';'
+	r.Comment = '#'
+
+	records, err := r.ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"a", "b"}, {"c", "d"}}, records)
+}
@@ -0,0 +1,20 @@
+module github.com/ortuman/nuke/nukefasthttp
+
+go 1.25.0
+
+replace github.com/ortuman/nuke => ../
+
+require (
+	github.com/ortuman/nuke v0.0.0
+	github.com/stretchr/testify v1.8.4
+	github.com/valyala/fasthttp v1.74.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/klauspost/compress v1.20.0 // indirect
+	github.com/molecule-man/go-brrr v1.0.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,40 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukefasthttp provides fasthttp integration, serving every request with an arena of its own.
+package nukefasthttp
+
+import (
+	"github.com/valyala/fasthttp"
+
+	"github.com/ortuman/nuke"
+)
+
+// UserValueKey is the RequestCtx user value key the request arena is stored under.
+const UserValueKey = "github.com/ortuman/nuke.arena"
+
+// Handler returns a request handler that takes an arena from the pool for every request, attaching
+// it to the RequestCtx (see Arena), and returns it to the pool once next returns, panics included.
+//
+// Since the arena is reset right after next returns, nothing allocated from it may be retained
+// afterwards. In particular, the response body must not be set with SetBodyRaw or SetBodyStream
+// from arena memory, as it's only written once the handler has returned, and next must not be
+// wrapped by fasthttp.TimeoutHandler, which lets it run past the response.
+// Writes through RequestCtx.Write and SetBody are safe, as they copy the data.
+func Handler(pool *nuke.ArenaPool, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		a := pool.Get()
+		defer pool.Put(a)
+
+		ctx.SetUserValue(UserValueKey, a)
+		defer ctx.RemoveUserValue(UserValueKey)
+
+		next(ctx)
+	}
+}
+
+// Arena returns the arena attached to the request by Handler,
+// or nil if there is none, which makes allocations go to the heap.
+func Arena(ctx *fasthttp.RequestCtx) nuke.Arena {
+	a, _ := ctx.UserValue(UserValueKey).(nuke.Arena)
+	return a
+}
@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukefasthttp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"github.com/ortuman/nuke"
+)
+
+func TestHandler(t *testing.T) {
+	arena := nuke.NewMonotonicArena(64, 1)
+	pool := nuke.NewArenaPool(func() nuke.Arena { return arena })
+
+	h := Handler(pool, func(ctx *fasthttp.RequestCtx) {
+		a := Arena(ctx)
+		require.Same(t, arena, a)
+
+		b := nuke.MakeSlice[byte](a, 0, 16)
+		b = append(b, "hello"...)
+		_, _ = ctx.Write(b)
+	})
+
+	var ctx fasthttp.RequestCtx
+	h(&ctx)
+
+	require.Equal(t, "hello", string(ctx.Response.Body()))
+	require.Nil(t, Arena(&ctx))
+	require.Equal(t, uint64(1), arena.(interface{ Metrics() nuke.Metrics }).Metrics().Resets)
+}
+
+func TestHandlerPanic(t *testing.T) {
+	arena := nuke.NewMonotonicArena(64, 1)
+	pool := nuke.NewArenaPool(func() nuke.Arena { return arena })
+
+	h := Handler(pool, func(*fasthttp.RequestCtx) { panic("boom") })
+
+	require.Panics(t, func() { h(&fasthttp.RequestCtx{}) })
+	require.Equal(t, uint64(1), arena.(interface{ Metrics() nuke.Metrics }).Metrics().Resets)
+}
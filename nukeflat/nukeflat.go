@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukeflat lays out length-prefixed, offset-linked records directly in arena memory,
+// so that messages are built in their wire format and sent without an encoding copy.
+//
+// A message starts with the little-endian uint32 offset of its root record, followed by blobs
+// made of a little-endian uint32 length and that many bytes. A record is a blob of 8-byte slots,
+// each holding either a little-endian uint64 value or the Offset of another blob. Messages are
+// read in place, without decoding them first, with Message.
+package nukeflat
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"unsafe"
+
+	"github.com/ortuman/nuke"
+)
+
+const (
+	headerSize = 4
+	prefixSize = 4
+	slotSize   = 8
+)
+
+// ErrMalformed is the error returned when reading a blob out of the bounds of a message.
+var ErrMalformed = errors.New("nukeflat: malformed message")
+
+// Offset is the position of a blob within a message. The zero Offset refers to no blob.
+type Offset uint32
+
+// Builder builds a message in memory allocated from an arena, which is only valid
+// until the arena is reset.
+type Builder struct {
+	buf *nuke.Buffer
+}
+
+// NewBuilder returns a Builder allocating from the arena, with room for size bytes.
+func NewBuilder(a nuke.Arena, size int) *Builder {
+	b := &Builder{buf: nuke.NewBuffer(a, max(size, headerSize))}
+	b.Reset()
+	return b
+}
+
+// Bytes appends a blob holding a copy of p and returns its offset.
+func (b *Builder) Bytes(p []byte) Offset {
+	off := b.prefix(len(p))
+	_, _ = b.buf.Write(p)
+	return off
+}
+
+// String appends a blob holding a copy of s and returns its offset.
+func (b *Builder) String(s string) Offset {
+	off := b.prefix(len(s))
+	_, _ = b.buf.WriteString(s)
+	return off
+}
+
+// Record appends a record of n zeroed slots and returns a RecordBuilder to fill them in.
+func (b *Builder) Record(n int) RecordBuilder {
+	if n < 0 || n > math.MaxUint32/slotSize {
+		panic("nukeflat: invalid number of record slots")
+	}
+	off := b.prefix(n * slotSize)
+	var zero [slotSize]byte
+	for i := 0; i < n; i++ {
+		_, _ = b.buf.Write(zero[:])
+	}
+	return RecordBuilder{b: b, off: off, n: n}
+}
+
+// Finish sets the root record of the message and returns it. The returned bytes remain
+// valid until the next call to Reset or until the arena is reset.
+func (b *Builder) Finish(root Offset) []byte {
+	msg := b.buf.Bytes()
+	binary.LittleEndian.PutUint32(msg, uint32(root))
+	return msg
+}
+
+// Len returns the number of bytes of the message built so far.
+func (b *Builder) Len() int {
+	return b.buf.Len()
+}
+
+// Reset discards the message built so far, keeping its memory for the next one.
+func (b *Builder) Reset() {
+	b.buf.Reset()
+	var header [headerSize]byte
+	_, _ = b.buf.Write(header[:])
+}
+
+// prefix appends the length prefix of a blob of n bytes, returning the blob offset.
+func (b *Builder) prefix(n int) Offset {
+	off := b.buf.Len()
+	if uint64(off)+prefixSize+uint64(n) > math.MaxUint32 {
+		panic("nukeflat: message too large")
+	}
+	var p [prefixSize]byte
+	binary.LittleEndian.PutUint32(p[:], uint32(n))
+	_, _ = b.buf.Write(p[:])
+	return Offset(off)
+}
+
+// RecordBuilder fills in the slots of a record appended to a Builder.
+type RecordBuilder struct {
+	b   *Builder
+	off Offset
+	n   int
+}
+
+// Offset returns the offset of the record.
+func (r RecordBuilder) Offset() Offset {
+	return r.off
+}
+
+// SetUint64 stores v in slot i of the record.
+func (r RecordBuilder) SetUint64(i int, v uint64) {
+	binary.LittleEndian.PutUint64(r.slot(i), v)
+}
+
+// SetOffset stores the offset of a blob in slot i of the record.
+func (r RecordBuilder) SetOffset(i int, off Offset) {
+	r.SetUint64(i, uint64(off))
+}
+
+func (r RecordBuilder) slot(i int) []byte {
+	if uint(i) >= uint(r.n) {
+		panic("nukeflat: record slot index out of range")
+	}
+	start := int(r.off) + prefixSize + i*slotSize
+	return r.b.buf.Bytes()[start : start+slotSize]
+}
+
+// Message is a message built with a Builder, which is read in place.
+type Message []byte
+
+// Root returns the root record of the message.
+func (m Message) Root() (Record, error) {
+	if len(m) < headerSize {
+		return Record{}, ErrMalformed
+	}
+	return m.Record(Offset(binary.LittleEndian.Uint32(m)))
+}
+
+// Bytes returns the contents of the blob at the given offset, sharing the message memory.
+func (m Message) Bytes(off Offset) ([]byte, error) {
+	if off < headerSize || uint64(off)+prefixSize > uint64(len(m)) {
+		return nil, ErrMalformed
+	}
+	start := uint64(off) + prefixSize
+	end := start + uint64(binary.LittleEndian.Uint32(m[off:]))
+	if end > uint64(len(m)) {
+		return nil, ErrMalformed
+	}
+	return m[start:end:end], nil
+}
+
+// String returns the contents of the blob at the given offset as a string sharing
+// the message memory, so it's only valid as long as the message is.
+func (m Message) String(off Offset) (string, error) {
+	p, err := m.Bytes(off)
+	if err != nil {
+		return "", err
+	}
+	return unsafe.String(unsafe.SliceData(p), len(p)), nil
+}
+
+// Record returns the record at the given offset.
+func (m Message) Record(off Offset) (Record, error) {
+	p, err := m.Bytes(off)
+	if err != nil {
+		return Record{}, err
+	}
+	if len(p)%slotSize != 0 {
+		return Record{}, ErrMalformed
+	}
+	return Record{m: m, slots: p}, nil
+}
+
+// Record is a record of a Message.
+type Record struct {
+	m     Message
+	slots []byte
+}
+
+// NumSlots returns the number of slots of the record.
+func (r Record) NumSlots() int {
+	return len(r.slots) / slotSize
+}
+
+// Uint64 returns the value stored in slot i of the record.
+func (r Record) Uint64(i int) uint64 {
+	if uint(i) >= uint(r.NumSlots()) {
+		panic("nukeflat: record slot index out of range")
+	}
+	return binary.LittleEndian.Uint64(r.slots[i*slotSize:])
+}
+
+// Offset returns the offset stored in slot i of the record.
+func (r Record) Offset(i int) Offset {
+	return Offset(r.Uint64(i))
+}
+
+// Bytes returns the contents of the blob referred to by slot i of the record.
+func (r Record) Bytes(i int) ([]byte, error) {
+	off, err := r.blobOffset(i)
+	if err != nil {
+		return nil, err
+	}
+	return r.m.Bytes(off)
+}
+
+// String returns the contents of the blob referred to by slot i of the record as a string.
+func (r Record) String(i int) (string, error) {
+	off, err := r.blobOffset(i)
+	if err != nil {
+		return "", err
+	}
+	return r.m.String(off)
+}
+
+// Record returns the record referred to by slot i of the record.
+func (r Record) Record(i int) (Record, error) {
+	off, err := r.blobOffset(i)
+	if err != nil {
+		return Record{}, err
+	}
+	return r.m.Record(off)
+}
+
+// blobOffset returns the offset stored in slot i of the record, checking it's a valid one.
+func (r Record) blobOffset(i int) (Offset, error) {
+	v := r.Uint64(i)
+	if v > math.MaxUint32 {
+		return 0, ErrMalformed
+	}
+	return Offset(v), nil
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukeflat
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+)
+
+func buildUser(b *Builder) []byte {
+	name := b.String("gopher")
+	avatar := b.Bytes([]byte{0xca, 0xfe})
+
+	tags := b.Record(2)
+	tags.SetOffset(0, b.String("admin"))
+	tags.SetOffset(1, b.String("ops"))
+
+	user := b.Record(4)
+	user.SetUint64(0, 42)
+	user.SetOffset(1, name)
+	user.SetOffset(2, avatar)
+	user.SetOffset(3, tags.Offset())
+	return b.Finish(user.Offset())
+}
+
+func TestBuilder(t *testing.T) {
+	arena := nuke.NewMonotonicArena(4096, 1)
+
+	b := NewBuilder(arena, 256)
+	msg := Message(buildUser(b))
+	require.Equal(t, b.Len(), len(msg))
+
+	user, err := msg.Root()
+	require.NoError(t, err)
+	require.Equal(t, 4, user.NumSlots())
+	require.Equal(t, uint64(42), user.Uint64(0))
+
+	name, err := user.String(1)
+	require.NoError(t, err)
+	require.Equal(t, "gopher", name)
+
+	avatar, err := user.Bytes(2)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xca, 0xfe}, avatar)
+
+	tags, err := user.Record(3)
+	require.NoError(t, err)
+	require.Equal(t, 2, tags.NumSlots())
+	tag, err := tags.String(1)
+	require.NoError(t, err)
+	require.Equal(t, "ops", tag)
+
+	require.Panics(t, func() { user.Uint64(4) })
+
+	// Rebuilding after a reset yields the same message, from the same memory.
+	first := string(msg)
+	b.Reset()
+	require.Equal(t, first, string(buildUser(b)))
+}
+
+func TestBuilderGrowth(t *testing.T) {
+	arena := nuke.NewMonotonicArena(64*1024, 1)
+
+	b := NewBuilder(arena, 0)
+	r := b.Record(1)
+	for i := 0; i < 100; i++ {
+		b.String("padding to grow the buffer")
+	}
+	r.SetUint64(0, math.MaxUint64) // must land in the grown buffer
+
+	rec, err := Message(b.Finish(r.Offset())).Root()
+	require.NoError(t, err)
+	require.Equal(t, uint64(math.MaxUint64), rec.Uint64(0))
+
+	_, err = rec.Bytes(0)
+	require.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestMessageMalformed(t *testing.T) {
+	for _, msg := range []Message{
+		nil,
+		{0, 0, 0, 0},
+		{4, 0, 0, 0},
+		{4, 0, 0, 0, 9, 0, 0, 0, 1},
+		{4, 0, 0, 0, 3, 0, 0, 0, 1, 2, 3},
+	} {
+		_, err := msg.Root()
+		require.ErrorIs(t, err, ErrMalformed, msg)
+	}
+}
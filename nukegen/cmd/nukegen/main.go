@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Command nukegen generates arena constructors for struct types not holding pointers.
+//
+// For every type named by the -type flag, it writes to a file of its package a constructor
+// allocating a value from an arena, one making a slice of them, and functions copying values
+// and slices into an arena. It is meant to be run by go generate:
+//
+//	//go:generate nukegen -type Point,Rect
+//
+// The file is named after the first type, as in point_nuke.go, unless -output is given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/ortuman/nuke/nukegen"
+)
+
+func main() {
+	log.SetFlags(0)
+
+	typeNames := flag.String("type", "", "comma-separated list of type names; must be set")
+	output := flag.String("output", "", "output file name; default <dir>/<type>_nuke.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: nukegen -type T[,T...] [-output file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	types := strings.Split(*typeNames, ",")
+
+	// Unexported types are only known when type checking from source.
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		log.Fatalf("nukegen: %v", err)
+	}
+	if len(pkgs) != 1 {
+		log.Fatalf("nukegen: %d packages found in %s", len(pkgs), dir)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		os.Exit(1)
+	}
+	src, err := nukegen.Generate(pkgs[0].Types, types)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *output == "" {
+		*output = filepath.Join(dir, strings.ToLower(types[0])+"_nuke.go")
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		log.Fatalf("nukegen: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Code generated by nukegen. DO NOT EDIT.
+
+package shapes
+
+import (
+	"math"
+	"unsafe"
+
+	"github.com/ortuman/nuke"
+)
+
+const (
+	sizeofPoint  = unsafe.Sizeof(Point{})
+	alignofPoint = unsafe.Alignof(Point{})
+)
+
+// NewPoint allocates a Point from the arena, or from the heap if the arena is nil or can't serve it.
+func NewPoint(a nuke.Arena) *Point {
+	if a != nil {
+		if ptr := a.Alloc(sizeofPoint, alignofPoint); ptr != nil {
+			return (*Point)(ptr)
+		}
+		nuke.RecordHeapFallback(a)
+	}
+	return new(Point)
+}
+
+// MakePointSlice makes a slice of Point with the given length and capacity from the arena,
+// or from the heap if the arena is nil or can't serve it.
+func MakePointSlice(a nuke.Arena, len, cap int) []Point {
+	if a != nil && 0 <= len && len <= cap && uintptr(cap) <= math.MaxInt/sizeofPoint {
+		if ptr := a.Alloc(sizeofPoint*uintptr(cap), alignofPoint); ptr != nil {
+			return unsafe.Slice((*Point)(ptr), cap)[:len]
+		}
+		nuke.RecordHeapFallback(a)
+	}
+	return make([]Point, len, cap)
+}
+
+// CopyPoint returns a copy of v allocated from the arena. Point holds no pointers, so the copy is deep.
+func CopyPoint(a nuke.Arena, v *Point) *Point {
+	c := NewPoint(a)
+	*c = *v
+	return c
+}
+
+// CopyPointSlice returns a copy of s allocated from the arena.
+func CopyPointSlice(a nuke.Arena, s []Point) []Point {
+	c := MakePointSlice(a, len(s), len(s))
+	copy(c, s)
+	return c
+}
+
+const (
+	sizeofRect  = unsafe.Sizeof(rect{})
+	alignofRect = unsafe.Alignof(rect{})
+)
+
+// newRect allocates a rect from the arena, or from the heap if the arena is nil or can't serve it.
+func newRect(a nuke.Arena) *rect {
+	if a != nil {
+		if ptr := a.Alloc(sizeofRect, alignofRect); ptr != nil {
+			return (*rect)(ptr)
+		}
+		nuke.RecordHeapFallback(a)
+	}
+	return new(rect)
+}
+
+// makeRectSlice makes a slice of rect with the given length and capacity from the arena,
+// or from the heap if the arena is nil or can't serve it.
+func makeRectSlice(a nuke.Arena, len, cap int) []rect {
+	if a != nil && 0 <= len && len <= cap && uintptr(cap) <= math.MaxInt/sizeofRect {
+		if ptr := a.Alloc(sizeofRect*uintptr(cap), alignofRect); ptr != nil {
+			return unsafe.Slice((*rect)(ptr), cap)[:len]
+		}
+		nuke.RecordHeapFallback(a)
+	}
+	return make([]rect, len, cap)
+}
+
+// copyRect returns a copy of v allocated from the arena. rect holds no pointers, so the copy is deep.
+func copyRect(a nuke.Arena, v *rect) *rect {
+	c := newRect(a)
+	*c = *v
+	return c
+}
+
+// copyRectSlice returns a copy of s allocated from the arena.
+func copyRectSlice(a nuke.Arena, s []rect) []rect {
+	c := makeRectSlice(a, len(s), len(s))
+	copy(c, s)
+	return c
+}
@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package shapes holds the types nukegen generates arena constructors for in its tests.
+package shapes
+
+//go:generate go run ../../cmd/nukegen -type Point,rect
+
+// Point is a point in the plane.
+type Point struct {
+	X, Y float64
+}
+
+type rect struct {
+	Min, Max Point
+	Tags     [4]uint8
+}
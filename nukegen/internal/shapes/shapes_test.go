@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package shapes
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+	"github.com/ortuman/nuke/nuketest"
+)
+
+func TestGeneratedConstructors(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	arena := nuke.NewMonotonicArena(1024, 1, nuke.WithStrictMode())
+
+	p := NewPoint(arena)
+	p.X = 1
+	r := newRect(arena)
+	require.Zero(t, uintptr(unsafe.Pointer(r))%unsafe.Alignof(*r))
+
+	points := MakePointSlice(arena, 2, 4)
+	require.Len(t, points, 2)
+	require.Equal(t, 4, cap(points))
+
+	c := CopyPoint(arena, p)
+	require.NotSame(t, p, c)
+	require.Equal(t, *p, *c)
+	require.Equal(t, points, CopyPointSlice(arena, points))
+	require.Equal(t, uint64(5), arena.(nuke.Stater).Metrics().Allocs)
+
+	// Allocations the arena can't serve are sent to the heap, which strict arenas don't allow.
+	require.PanicsWithValue(t, nuke.ErrHeapFallback, func() { makeRectSlice(arena, 0, 1024) })
+	require.NotNil(t, NewPoint(nil))
+	require.Panics(t, func() { MakePointSlice(arena, 2, 1) })
+}
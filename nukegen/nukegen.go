@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukegen generates arena constructors specialized for struct types, which allocate through
+// Arena.Alloc with compile-time size and alignment constants rather than through the generic helpers
+// of the nuke package.
+package nukegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Generate returns the source of a file of package pkg declaring, for every one of the named struct
+// types, a constructor allocating a value from an arena, one making a slice of them, and functions
+// copying values and slices into an arena. Like the rest of the memory of non-GC arenas, the generated
+// constructors are only safe for types not holding pointers, so Generate fails for any other type,
+// naming the field holding them.
+func Generate(pkg *types.Package, typeNames []string) ([]byte, error) {
+	var decls []decl
+	for _, name := range typeNames {
+		d, err := newDecl(pkg, name)
+		if err != nil {
+			return nil, err
+		}
+		decls = append(decls, d)
+	}
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, file{Package: pkg.Name(), Decls: decls}); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+type file struct {
+	Package string
+	Decls   []decl
+}
+
+// decl holds the names of the declarations generated for a type.
+type decl struct {
+	Type      string
+	Size      string
+	Align     string
+	New       string
+	MakeSlice string
+	Copy      string
+	CopySlice string
+}
+
+func newDecl(pkg *types.Package, name string) (decl, error) {
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return decl{}, fmt.Errorf("nukegen: type %s not found in package %s", name, pkg.Path())
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok || obj.IsAlias() {
+		return decl{}, fmt.Errorf("nukegen: %s isn't a defined type", name)
+	}
+	if named.TypeParams().Len() > 0 {
+		return decl{}, fmt.Errorf("nukegen: %s is generic", name)
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return decl{}, fmt.Errorf("nukegen: %s isn't a struct type", name)
+	}
+	if path, ok := pointerPath(st, name); ok {
+		return decl{}, fmt.Errorf("nukegen: %s holds pointers, which can't be stored in arena memory not scanned by the garbage collector (use nuke.New with nuke.NewGCArena)", path)
+	}
+	if types.SizesFor("gc", "amd64").Sizeof(st) == 0 {
+		return decl{}, fmt.Errorf("nukegen: %s has no size", name)
+	}
+	prefix := func(p string) string {
+		if obj.Exported() {
+			return upperFirst(p) + name
+		}
+		return p + upperFirst(name)
+	}
+	return decl{
+		Type:      name,
+		Size:      "sizeof" + upperFirst(name),
+		Align:     "alignof" + upperFirst(name),
+		New:       prefix("new"),
+		MakeSlice: prefix("make") + "Slice",
+		Copy:      prefix("copy"),
+		CopySlice: prefix("copy") + "Slice",
+	}, nil
+}
+
+// pointerPath returns the path to the first field of typ holding pointers,
+// reporting false if values of typ don't hold any.
+func pointerPath(typ types.Type, path string) (string, bool) {
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		return path, t.Kind() == types.String || t.Kind() == types.UnsafePointer
+	case *types.Array:
+		if t.Len() == 0 {
+			return "", false
+		}
+		return pointerPath(t.Elem(), path+"[]")
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			f := t.Field(i)
+			if p, ok := pointerPath(f.Type(), path+"."+f.Name()); ok {
+				return p, true
+			}
+		}
+		return "", false
+	default:
+		// Pointers, slices, maps, channels, functions, interfaces and type parameters.
+		return path, true
+	}
+}
+
+func upperFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by nukegen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"math"
+	"unsafe"
+
+	"github.com/ortuman/nuke"
+)
+{{range .Decls}}
+const (
+	{{.Size}}  = unsafe.Sizeof({{.Type}}{})
+	{{.Align}} = unsafe.Alignof({{.Type}}{})
+)
+
+// {{.New}} allocates a {{.Type}} from the arena, or from the heap if the arena is nil or can't serve it.
+func {{.New}}(a nuke.Arena) *{{.Type}} {
+	if a != nil {
+		if ptr := a.Alloc({{.Size}}, {{.Align}}); ptr != nil {
+			return (*{{.Type}})(ptr)
+		}
+		nuke.RecordHeapFallback(a)
+	}
+	return new({{.Type}})
+}
+
+// {{.MakeSlice}} makes a slice of {{.Type}} with the given length and capacity from the arena,
+// or from the heap if the arena is nil or can't serve it.
+func {{.MakeSlice}}(a nuke.Arena, len, cap int) []{{.Type}} {
+	if a != nil && 0 <= len && len <= cap && uintptr(cap) <= math.MaxInt/{{.Size}} {
+		if ptr := a.Alloc({{.Size}}*uintptr(cap), {{.Align}}); ptr != nil {
+			return unsafe.Slice((*{{.Type}})(ptr), cap)[:len]
+		}
+		nuke.RecordHeapFallback(a)
+	}
+	return make([]{{.Type}}, len, cap)
+}
+
+// {{.Copy}} returns a copy of v allocated from the arena. {{.Type}} holds no pointers, so the copy is deep.
+func {{.Copy}}(a nuke.Arena, v *{{.Type}}) *{{.Type}} {
+	c := {{.New}}(a)
+	*c = *v
+	return c
+}
+
+// {{.CopySlice}} returns a copy of s allocated from the arena.
+func {{.CopySlice}}(a nuke.Arena, s []{{.Type}}) []{{.Type}} {
+	c := {{.MakeSlice}}(a, len(s), len(s))
+	copy(c, s)
+	return c
+}
+{{end}}`))
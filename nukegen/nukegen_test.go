@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukegen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestGenerate(t *testing.T) {
+	// The constructors of the shapes package must be up to date with the generator.
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax,
+		Dir:  "internal/shapes",
+	}, ".")
+	require.NoError(t, err)
+	require.Len(t, pkgs, 1)
+	require.Empty(t, pkgs[0].Errors)
+
+	src, err := Generate(pkgs[0].Types, []string{"Point", "rect"})
+	require.NoError(t, err)
+	want, err := os.ReadFile("internal/shapes/point_nuke.go")
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(src), "run go generate ./...")
+}
+
+func TestGenerateInvalidTypes(t *testing.T) {
+	const src = `package p
+
+import "sync"
+
+type node struct {
+	id   int
+	next *node
+}
+
+type named struct {
+	pos  [2]int
+	tags [2]struct{ name string }
+}
+
+type locked struct {
+	mu sync.Mutex
+	n  int
+}
+
+type empty struct{}
+
+type list[T any] struct{ v T }
+
+type ints []int
+
+type alias = empty
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	require.NoError(t, err)
+	pkg, err := (&types.Config{Importer: importer.Default()}).Check("p", fset, []*ast.File{f}, nil)
+	require.NoError(t, err)
+
+	for name, msg := range map[string]string{
+		"node":    "nukegen: node.next holds pointers",
+		"named":   "nukegen: named.tags[].name holds pointers",
+		"empty":   "nukegen: empty has no size",
+		"list":    "nukegen: list is generic",
+		"ints":    "nukegen: ints isn't a struct type",
+		"alias":   "nukegen: alias isn't a defined type",
+		"missing": "nukegen: type missing not found in package p",
+	} {
+		_, err := Generate(pkg, []string{name})
+		require.ErrorContains(t, err, msg, name)
+	}
+
+	// Pointer-free types from other packages are fine.
+	_, err = Generate(pkg, []string{"locked"})
+	require.NoError(t, err)
+}
@@ -0,0 +1,20 @@
+module github.com/ortuman/nuke/nukegrpc
+
+go 1.25.0
+
+replace github.com/ortuman/nuke => ../
+
+require (
+	github.com/ortuman/nuke v0.0.0
+	github.com/stretchr/testify v1.12.1
+	google.golang.org/grpc v1.84.0
+)
+
+require (
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+	golang.org/x/net v0.57.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+)
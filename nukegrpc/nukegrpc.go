@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukegrpc provides gRPC integration, serving every call with an arena of its own.
+package nukegrpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/ortuman/nuke"
+)
+
+// Usage describes how a call made use of its arena.
+type Usage struct {
+	// Allocs and AllocatedBytes account for the allocations served by the arena.
+	Allocs         uint64
+	AllocatedBytes uint64
+
+	// HeapFallbacks is the number of allocations that didn't fit the arena and were sent to the heap.
+	HeapFallbacks uint64
+}
+
+// Option configures an interceptor.
+type Option func(*options)
+
+type options struct {
+	usageHandler func(ctx context.Context, method string, u Usage)
+}
+
+// WithUsageHandler makes the interceptor report the arena usage of every call to fn, along with
+// the full method name of the call. Usage is only reported for arenas exposing their metrics,
+// such as the ones provided by package nuke.
+func WithUsageHandler(fn func(ctx context.Context, method string, u Usage)) Option {
+	return func(o *options) { o.usageHandler = fn }
+}
+
+// UnaryServerInterceptor returns a server interceptor that takes an arena from the pool for every
+// unary call, injecting it into the call context (see nuke.ExtractContextArena), and returns it to
+// the pool once the handler returns, panics included.
+//
+// Since the response is marshalled after the interceptor returns, it must not reference memory
+// allocated from the arena, nor may anything allocated from it be retained by goroutines started
+// by the handler.
+func UnaryServerInterceptor(pool *nuke.ArenaPool, opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		a := pool.Get()
+		defer pool.Put(a)
+
+		if o.usageHandler != nil {
+			if mr, ok := a.(nuke.Stater); ok {
+				before := mr.Metrics()
+				defer func() { o.usageHandler(ctx, info.FullMethod, usage(before, mr.Metrics())) }()
+			}
+		}
+		return handler(nuke.InjectContextArena(ctx, a), req)
+	}
+}
+
+// StreamServerInterceptor returns a server interceptor that takes an arena from the pool for every
+// streaming call, injecting it into the stream context, and returns it to the pool once the handler
+// returns, panics included.
+//
+// As streams may be long-lived, allocating from the same arena for their whole duration would make
+// it grow without bounds. Handlers should instead process every message within a MessageScope,
+// which resets the arena before the next one.
+func StreamServerInterceptor(pool *nuke.ArenaPool, opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		a := pool.Get()
+		defer pool.Put(a)
+
+		ctx := ss.Context()
+		if o.usageHandler != nil {
+			if mr, ok := a.(nuke.Stater); ok {
+				before := mr.Metrics()
+				defer func() { o.usageHandler(ctx, info.FullMethod, usage(before, mr.Metrics())) }()
+			}
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: nuke.InjectContextArena(ctx, a)})
+	}
+}
+
+// MessageScope calls fn with the arena of the streaming call ctx belongs to, and resets the arena
+// once fn returns, so that the memory allocated while handling a message is reused for the next.
+// Nothing allocated from the arena within fn may be retained afterwards.
+// If ctx carries no arena, fn is passed a nil one, which makes allocations go to the heap.
+func MessageScope(ctx context.Context, fn func(a nuke.Arena) error) error {
+	a := nuke.ExtractContextArena(ctx)
+	if a != nil {
+		defer a.Reset(false)
+	}
+	return fn(a)
+}
+
+// serverStream overrides the context of a grpc.ServerStream.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func usage(before, after nuke.Metrics) Usage {
+	return Usage{
+		Allocs:         after.Allocs - before.Allocs,
+		AllocatedBytes: after.AllocatedBytes - before.AllocatedBytes,
+		HeapFallbacks:  after.HeapFallbacks - before.HeapFallbacks,
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
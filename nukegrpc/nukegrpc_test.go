@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukegrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/ortuman/nuke"
+	"github.com/ortuman/nuke/nuketest"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	arena := nuke.NewMonotonicArena(64, 1)
+	pool := nuke.NewArenaPool(func() nuke.Arena { return arena })
+
+	var method string
+	var u Usage
+	interceptor := UnaryServerInterceptor(pool, WithUsageHandler(func(_ context.Context, m string, usage Usage) {
+		method, u = m, usage
+	}))
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	resp, err := interceptor(context.Background(), "req", info, func(ctx context.Context, req any) (any, error) {
+		a := nuke.ExtractContextArena(ctx)
+		require.Same(t, arena, a)
+
+		_ = nuke.MakeSlice[byte](a, 0, 48)
+		_ = nuke.MakeSlice[byte](a, 0, 32) // sent to the heap
+		return "resp", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "resp", resp)
+
+	require.Equal(t, "/test.Service/Method", method)
+	require.Equal(t, Usage{Allocs: 1, AllocatedBytes: 48, HeapFallbacks: 1}, u)
+
+	// The arena was reset and returned to the pool.
+	require.Equal(t, uint64(1), arena.(interface{ Metrics() nuke.Metrics }).Metrics().Resets)
+}
+
+func TestUnaryServerInterceptorError(t *testing.T) {
+	arena := nuke.NewMonotonicArena(64, 1)
+	pool := nuke.NewArenaPool(func() nuke.Arena { return arena })
+
+	errFailed := errors.New("failed")
+	interceptor := UnaryServerInterceptor(pool)
+
+	_, err := interceptor(context.Background(), "req", &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return nil, errFailed
+	})
+	require.ErrorIs(t, err, errFailed)
+	require.Equal(t, uint64(1), arena.(interface{ Metrics() nuke.Metrics }).Metrics().Resets)
+}
+
+// mockServerStream is a grpc.ServerStream receiving a fixed set of messages.
+type mockServerStream struct {
+	grpc.ServerStream
+	msgs []string
+}
+
+func (s *mockServerStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *mockServerStream) RecvMsg(m any) error {
+	if len(s.msgs) == 0 {
+		return io.EOF
+	}
+	*m.(*string), s.msgs = s.msgs[0], s.msgs[1:]
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	arena := nuke.NewMonotonicArena(64, 1)
+	pool := nuke.NewArenaPool(func() nuke.Arena { return arena })
+
+	var u Usage
+	interceptor := StreamServerInterceptor(pool, WithUsageHandler(func(_ context.Context, _ string, usage Usage) {
+		u = usage
+	}))
+
+	ss := &mockServerStream{msgs: []string{"first message", "second message", "third message"}}
+	err := interceptor(nil, ss, &grpc.StreamServerInfo{}, func(_ any, stream grpc.ServerStream) error {
+		for {
+			var msg string
+			if err := stream.RecvMsg(&msg); err == io.EOF {
+				return nil
+			}
+			err := MessageScope(stream.Context(), func(a nuke.Arena) error {
+				require.Same(t, arena, a)
+
+				// Every message fits the arena on its own, but not all of them together.
+				b := nuke.MakeSlice[byte](a, 0, 48)
+				b = append(b, msg...)
+				require.Equal(t, msg, string(b))
+				return nil
+			})
+			require.NoError(t, err)
+		}
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, Usage{Allocs: 3, AllocatedBytes: 3 * 48}, u)
+	require.Equal(t, uint64(4), arena.(interface{ Metrics() nuke.Metrics }).Metrics().Resets)
+}
@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukehttp provides net/http integration, serving every request with an arena of its own
+// and reading response bodies into arena memory.
+package nukehttp
+
+import (
+	"errors"
+	"io"
+	"math"
+	"net/http"
+
+	"github.com/ortuman/nuke"
+)
+
+// Middleware returns an HTTP middleware that takes an arena from the pool for every request,
+// injecting it into the request context (see nuke.ExtractContextArena), and returns it to the
+// pool once the handler returns, panics included.
+//
+// Since the arena is reset right after the handler returns, nothing allocated from it may be
+// retained afterwards: not by goroutines started by the handler, nor by the response body when
+// writes are deferred, as with hijacked connections. Writes to the ResponseWriter are safe, as
+// they copy the data before returning. If the handler itself accesses the arena from multiple
+// goroutines, the pool must hand out arenas wrapped by nuke.NewConcurrentArena.
+func Middleware(pool *nuke.ArenaPool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a := pool.Get()
+			defer pool.Put(a)
+
+			next.ServeHTTP(w, r.WithContext(nuke.InjectContextArena(r.Context(), a)))
+		})
+	}
+}
+
+// ErrBodyTooLarge is the error ReadBody returns when a body exceeds the size limit.
+var ErrBodyTooLarge = errors.New("nukehttp: body too large")
+
+// ReadBody reads the body of the response until EOF into memory allocated from the arena,
+// returning ErrBodyTooLarge as soon as it's found to exceed limit bytes. Bodies announcing
+// their length are read into a single allocation of that size, while the rest are read into
+// a growing buffer. The body isn't closed, and the returned bytes are only valid until the
+// arena is reset.
+func ReadBody(a nuke.Arena, resp *http.Response, limit int64) ([]byte, error) {
+	switch {
+	case resp.ContentLength > limit:
+		return nil, ErrBodyTooLarge
+
+	case resp.ContentLength == 0:
+		return []byte{}, nil
+
+	case resp.ContentLength > 0:
+		b := nuke.MakeSlice[byte](a, int(resp.ContentLength), int(resp.ContentLength))
+		if _, err := io.ReadFull(resp.Body, b); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+	// Reading one byte more than the limit tells apart the inputs exceeding it, unless there's no limit.
+	n := limit
+	if n < math.MaxInt64 {
+		n++
+	}
+	buf := nuke.NewBuffer(a, 0)
+	if _, err := buf.ReadFrom(io.LimitReader(resp.Body, n)); err != nil {
+		return nil, err
+	}
+	if int64(buf.Len()) > limit {
+		return nil, ErrBodyTooLarge
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukehttp
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+	"github.com/ortuman/nuke/nuketest"
+)
+
+func TestMiddleware(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	arena := &nuketest.MockArena{}
+	pool := nuke.NewArenaPool(func() nuke.Arena { return arena })
+
+	h := Middleware(pool)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a := nuke.ExtractContextArena(r.Context())
+		require.Same(t, arena, a)
+
+		b := nuke.MakeSlice[byte](a, 0, 16)
+		b = append(b, "hello"...)
+		_, _ = w.Write(b)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, "hello", rec.Body.String())
+	require.Len(t, arena.Requests(), 1)
+	require.Equal(t, 1, arena.Resets())
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	arena := &nuketest.MockArena{}
+	pool := nuke.NewArenaPool(func() nuke.Arena { return arena })
+
+	h := Middleware(pool)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	require.Panics(t, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+	require.Equal(t, 1, arena.Resets())
+}
+
+func TestReadBody(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	arena := &nuketest.MockArena{}
+
+	resp := &http.Response{Body: io.NopCloser(strings.NewReader("hello")), ContentLength: 5}
+	b, err := ReadBody(arena, resp, 5)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(b))
+	require.Len(t, arena.Requests(), 1)
+
+	// Bodies of unknown length are read up to the limit.
+	resp = &http.Response{Body: io.NopCloser(strings.NewReader("hello")), ContentLength: -1}
+	b, err = ReadBody(arena, resp, 5)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(b))
+
+	resp = &http.Response{Body: io.NopCloser(strings.NewReader("hello")), ContentLength: -1}
+	_, err = ReadBody(arena, resp, 4)
+	require.ErrorIs(t, err, ErrBodyTooLarge)
+
+	// As many bytes as possible are read without a limit.
+	resp = &http.Response{Body: io.NopCloser(strings.NewReader("hello")), ContentLength: -1}
+	b, err = ReadBody(arena, resp, math.MaxInt64)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(b))
+
+	resp = &http.Response{Body: io.NopCloser(strings.NewReader("hello")), ContentLength: 5}
+	_, err = ReadBody(arena, resp, 4)
+	require.ErrorIs(t, err, ErrBodyTooLarge)
+
+	// Truncated bodies are reported.
+	resp = &http.Response{Body: io.NopCloser(strings.NewReader("hel")), ContentLength: 5}
+	_, err = ReadBody(arena, resp, 5)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestReadBodyFromServer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush() // forces a chunked response of unknown length
+		_, _ = io.WriteString(w, strings.Repeat("x", 4096))
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	b, err := ReadBody(nuke.NewMonotonicArena(64*1024, 1), resp, 1<<20)
+	require.NoError(t, err)
+	require.Len(t, b, 4096)
+}
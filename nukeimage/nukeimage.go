@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukeimage provides constructors of the image types of the standard library whose
+// pixel buffers are allocated from an arena.
+//
+// Pixel buffers are pointer-free, so any arena can serve them, while the image values themselves,
+// which are small, are still allocated from the heap. Images are only valid until the arena is reset.
+package nukeimage
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+
+	"github.com/ortuman/nuke"
+)
+
+// NewRGBA returns a new image.RGBA with the given bounds, whose pixels are allocated from the arena.
+func NewRGBA(a nuke.Arena, r image.Rectangle) *image.RGBA {
+	return &image.RGBA{
+		Pix:    makePix(a, 4, r, "NewRGBA"),
+		Stride: 4 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// NewRGBA64 returns a new image.RGBA64 with the given bounds, whose pixels are allocated from the arena.
+func NewRGBA64(a nuke.Arena, r image.Rectangle) *image.RGBA64 {
+	return &image.RGBA64{
+		Pix:    makePix(a, 8, r, "NewRGBA64"),
+		Stride: 8 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// NewNRGBA returns a new image.NRGBA with the given bounds, whose pixels are allocated from the arena.
+func NewNRGBA(a nuke.Arena, r image.Rectangle) *image.NRGBA {
+	return &image.NRGBA{
+		Pix:    makePix(a, 4, r, "NewNRGBA"),
+		Stride: 4 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// NewNRGBA64 returns a new image.NRGBA64 with the given bounds, whose pixels are allocated from the arena.
+func NewNRGBA64(a nuke.Arena, r image.Rectangle) *image.NRGBA64 {
+	return &image.NRGBA64{
+		Pix:    makePix(a, 8, r, "NewNRGBA64"),
+		Stride: 8 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// NewGray returns a new image.Gray with the given bounds, whose pixels are allocated from the arena.
+func NewGray(a nuke.Arena, r image.Rectangle) *image.Gray {
+	return &image.Gray{
+		Pix:    makePix(a, 1, r, "NewGray"),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// NewGray16 returns a new image.Gray16 with the given bounds, whose pixels are allocated from the arena.
+func NewGray16(a nuke.Arena, r image.Rectangle) *image.Gray16 {
+	return &image.Gray16{
+		Pix:    makePix(a, 2, r, "NewGray16"),
+		Stride: 2 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// NewAlpha returns a new image.Alpha with the given bounds, whose pixels are allocated from the arena.
+func NewAlpha(a nuke.Arena, r image.Rectangle) *image.Alpha {
+	return &image.Alpha{
+		Pix:    makePix(a, 1, r, "NewAlpha"),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// NewCMYK returns a new image.CMYK with the given bounds, whose pixels are allocated from the arena.
+func NewCMYK(a nuke.Arena, r image.Rectangle) *image.CMYK {
+	return &image.CMYK{
+		Pix:    makePix(a, 4, r, "NewCMYK"),
+		Stride: 4 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// NewPaletted returns a new image.Paletted with the given bounds and palette, whose pixels
+// are allocated from the arena. The palette itself is not copied.
+func NewPaletted(a nuke.Arena, r image.Rectangle, p color.Palette) *image.Paletted {
+	return &image.Paletted{
+		Pix:     makePix(a, 1, r, "NewPaletted"),
+		Stride:  r.Dx(),
+		Rect:    r,
+		Palette: p,
+	}
+}
+
+// NewYCbCr returns a new image.YCbCr with the given bounds and subsample ratio, whose Y, Cb and Cr
+// planes are allocated from the arena in a single buffer, like image.NewYCbCr does.
+func NewYCbCr(a nuke.Arena, r image.Rectangle, subsampleRatio image.YCbCrSubsampleRatio) *image.YCbCr {
+	w, h, cw, ch := yCbCrSize(r, subsampleRatio)
+
+	totalLength, ok := mul3NonNeg(1, w, h)
+	if ok {
+		var chromaLength int
+		if chromaLength, ok = mul3NonNeg(2, cw, ch); ok {
+			totalLength, ok = add2NonNeg(totalLength, chromaLength)
+		}
+	}
+	if !ok {
+		panic("image: NewYCbCr Rectangle has huge or negative dimensions")
+	}
+	i0 := w*h + 0*cw*ch
+	i1 := w*h + 1*cw*ch
+	i2 := w*h + 2*cw*ch
+	b := nuke.MakeSlice[byte](a, i2, i2)
+	return &image.YCbCr{
+		Y:              b[:i0:i0],
+		Cb:             b[i0:i1:i1],
+		Cr:             b[i1:i2:i2],
+		SubsampleRatio: subsampleRatio,
+		YStride:        w,
+		CStride:        cw,
+		Rect:           r,
+	}
+}
+
+// makePix allocates the pixel buffer of an image with the given bounds and bytes per pixel,
+// panicking like the image package does if the buffer length overflows.
+func makePix(a nuke.Arena, bytesPerPixel int, r image.Rectangle, fn string) []uint8 {
+	n, ok := mul3NonNeg(bytesPerPixel, r.Dx(), r.Dy())
+	if !ok {
+		panic("image: " + fn + " Rectangle has huge or negative dimensions")
+	}
+	return nuke.MakeSlice[uint8](a, n, n)
+}
+
+// yCbCrSize returns the dimensions of the luma and chroma planes of an image.YCbCr.
+func yCbCrSize(r image.Rectangle, subsampleRatio image.YCbCrSubsampleRatio) (w, h, cw, ch int) {
+	w, h = r.Dx(), r.Dy()
+	switch subsampleRatio {
+	case image.YCbCrSubsampleRatio422:
+		cw = (r.Max.X+1)/2 - r.Min.X/2
+		ch = h
+	case image.YCbCrSubsampleRatio420:
+		cw = (r.Max.X+1)/2 - r.Min.X/2
+		ch = (r.Max.Y+1)/2 - r.Min.Y/2
+	case image.YCbCrSubsampleRatio440:
+		cw = w
+		ch = (r.Max.Y+1)/2 - r.Min.Y/2
+	case image.YCbCrSubsampleRatio411:
+		cw = (r.Max.X+3)/4 - r.Min.X/4
+		ch = h
+	case image.YCbCrSubsampleRatio410:
+		cw = (r.Max.X+3)/4 - r.Min.X/4
+		ch = (r.Max.Y+1)/2 - r.Min.Y/2
+	default:
+		// Default to 4:4:4 subsampling.
+		cw = w
+		ch = h
+	}
+	return w, h, cw, ch
+}
+
+// mul3NonNeg returns (x * y * z), unless at least one argument is negative or if the
+// computation overflows the int type, in which case it returns (0, false).
+func mul3NonNeg(x, y, z int) (int, bool) {
+	if x < 0 || y < 0 || z < 0 {
+		return 0, false
+	}
+	hi, lo := bits.Mul64(uint64(x), uint64(y))
+	if hi != 0 {
+		return 0, false
+	}
+	hi, lo = bits.Mul64(lo, uint64(z))
+	if hi != 0 {
+		return 0, false
+	}
+	a := int(lo)
+	if a < 0 || uint64(a) != lo {
+		return 0, false
+	}
+	return a, true
+}
+
+// add2NonNeg returns (x + y), unless at least one argument is negative or if the
+// computation overflows the int type, in which case it returns (0, false).
+func add2NonNeg(x, y int) (int, bool) {
+	if x < 0 || y < 0 {
+		return 0, false
+	}
+	a := x + y
+	if a < 0 {
+		return 0, false
+	}
+	return a, true
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukeimage
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+	"github.com/ortuman/nuke/nuketest"
+)
+
+func TestNewImages(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	arena := &nuketest.MockArena{}
+	r := image.Rect(-3, 5, 61, 45)
+
+	for _, tc := range []struct {
+		got, want image.Image
+	}{
+		{NewRGBA(arena, r), image.NewRGBA(r)},
+		{NewRGBA64(arena, r), image.NewRGBA64(r)},
+		{NewNRGBA(arena, r), image.NewNRGBA(r)},
+		{NewNRGBA64(arena, r), image.NewNRGBA64(r)},
+		{NewGray(arena, r), image.NewGray(r)},
+		{NewGray16(arena, r), image.NewGray16(r)},
+		{NewAlpha(arena, r), image.NewAlpha(r)},
+		{NewCMYK(arena, r), image.NewCMYK(r)},
+		{NewPaletted(arena, r, color.Palette{color.Black}), image.NewPaletted(r, color.Palette{color.Black})},
+		{NewYCbCr(arena, r, image.YCbCrSubsampleRatio420), image.NewYCbCr(r, image.YCbCrSubsampleRatio420)},
+		{NewYCbCr(arena, r, image.YCbCrSubsampleRatio410), image.NewYCbCr(r, image.YCbCrSubsampleRatio410)},
+	} {
+		require.Equal(t, tc.want, tc.got)
+	}
+	// A single allocation for the pixels of every image.
+	require.Len(t, arena.Requests(), 11)
+}
+
+func TestDraw(t *testing.T) {
+	arena := nuke.NewMonotonicArena(64*1024, 1)
+
+	img := NewRGBA(arena, image.Rect(0, 0, 16, 16))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	require.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, img.At(15, 15))
+}
+
+func TestHugeDimensions(t *testing.T) {
+	arena := nuke.NewMonotonicArena(1024, 1)
+
+	require.Panics(t, func() { NewRGBA(arena, image.Rectangle{Max: image.Pt(-1, 1)}) })
+	require.Panics(t, func() { NewYCbCr(arena, image.Rect(0, 0, math.MaxInt, 4), image.YCbCrSubsampleRatio444) })
+}
@@ -0,0 +1,1006 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukejson provides JSON encoding and decoding backed by arena memory.
+package nukejson
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/ortuman/nuke"
+)
+
+// SyntaxError describes malformed JSON input.
+type SyntaxError struct {
+	msg string
+
+	// Offset is the number of bytes read before the error was found.
+	Offset int64
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("nukejson: %s at offset %d", e.msg, e.Offset)
+}
+
+var numberType = reflect.TypeOf(json.Number(""))
+
+// Unmarshal parses the JSON-encoded data and stores the result in the value pointed to by v,
+// following the same rules as json.Unmarshal, except that decoding stops at the first error.
+// Strings, the backing arrays of slices and the values that nil pointers are set to are allocated
+// from the arena, falling back to the heap when it can't serve them, so they're only valid until
+// the arena is reset.
+//
+// Maps, as well as the maps and slices created when decoding into interface values, are always
+// allocated from the heap, although their strings are still allocated from the arena.
+// Decoding into types holding pointers, including strings and slices, allocates them from the
+// arena like New and MakeSlice do, which requires an arena created with nuke.NewGCArena to be safe.
+func Unmarshal(a nuke.Arena, data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &json.InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	d := decodeState{a: a, data: data}
+	if err := d.value(rv); err != nil {
+		return err
+	}
+	if d.skipSpace(); d.off < len(d.data) {
+		return d.syntaxError("invalid character " + quoteChar(d.data[d.off]) + " after top-level value")
+	}
+	return nil
+}
+
+type decodeState struct {
+	a    nuke.Arena
+	data []byte
+	off  int
+}
+
+// value decodes the next JSON value into v. An invalid v makes the value be validated and discarded.
+func (d *decodeState) value(v reflect.Value) error {
+	d.skipSpace()
+	if d.off >= len(d.data) {
+		return d.syntaxError("unexpected end of JSON input")
+	}
+	if v.IsValid() {
+		u, tu, pv := indirect(d.a, v, d.data[d.off] == 'n')
+		if u != nil {
+			start := d.off
+			if err := d.value(reflect.Value{}); err != nil {
+				return err
+			}
+			return u.UnmarshalJSON(d.data[start:d.off])
+		}
+		if tu != nil {
+			if d.data[d.off] != '"' {
+				if err := d.value(reflect.Value{}); err != nil {
+					return err
+				}
+				return d.typeError("non-string", reflect.TypeOf(tu))
+			}
+			s, _, err := d.literalString()
+			if err != nil {
+				return err
+			}
+			return tu.UnmarshalText(s)
+		}
+		v = pv
+	}
+	switch c := d.data[d.off]; {
+	case c == '{':
+		return d.object(v)
+	case c == '[':
+		return d.array(v)
+	case c == '"':
+		return d.str(v)
+	case c == 't' || c == 'f' || c == 'n':
+		return d.literal(v)
+	case c == '-' || ('0' <= c && c <= '9'):
+		return d.number(v)
+	default:
+		return d.syntaxError("invalid character " + quoteChar(c) + " looking for beginning of value")
+	}
+}
+
+// indirect walks down v allocating pointers as needed, until it gets to a non-pointer.
+// If it encounters an Unmarshaler, indirect stops and returns that. If decodingNull is true,
+// indirect stops at the first settable pointer so it can be set to nil.
+func indirect(a nuke.Arena, v reflect.Value, decodingNull bool) (json.Unmarshaler, encoding.TextUnmarshaler, reflect.Value) {
+	// Unmarshal methods are usually declared on pointer receivers.
+	if v.Kind() != reflect.Pointer && v.Type().Name() != "" && v.CanAddr() {
+		v = v.Addr()
+	}
+	for {
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			e := v.Elem()
+			if e.Kind() == reflect.Pointer && !e.IsNil() && (!decodingNull || e.Elem().Kind() == reflect.Pointer) {
+				v = e
+				continue
+			}
+		}
+		if v.Kind() != reflect.Pointer {
+			break
+		}
+		if decodingNull && v.CanSet() {
+			break
+		}
+		if v.Elem().Kind() == reflect.Interface && v.Elem().Elem().Equal(v) {
+			v = v.Elem()
+			break
+		}
+		if v.IsNil() {
+			v.Set(nuke.NewOf(a, v.Type().Elem()))
+		}
+		if v.Type().NumMethod() > 0 && v.CanInterface() {
+			if u, ok := v.Interface().(json.Unmarshaler); ok {
+				return u, nil, reflect.Value{}
+			}
+			if !decodingNull {
+				if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+					return nil, u, reflect.Value{}
+				}
+			}
+		}
+		v = v.Elem()
+	}
+	return nil, nil, v
+}
+
+func (d *decodeState) object(v reflect.Value) error {
+	d.off++ // '{'
+
+	var fields structFields
+	var mapElem reflect.Value
+
+	if v.IsValid() {
+		switch v.Kind() {
+		case reflect.Interface:
+			if v.NumMethod() != 0 {
+				return d.typeError("object", v.Type())
+			}
+			m, err := d.objectInterface()
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(m))
+			return nil
+
+		case reflect.Map:
+			switch v.Type().Key().Kind() {
+			case reflect.String,
+				reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			default:
+				return d.typeError("object", v.Type())
+			}
+			if v.IsNil() {
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+			mapElem = reflect.New(v.Type().Elem()).Elem()
+
+		case reflect.Struct:
+			fields = cachedFields(v.Type())
+
+		default:
+			d.off--
+			if err := d.value(reflect.Value{}); err != nil {
+				return err
+			}
+			return d.typeError("object", v.Type())
+		}
+	}
+	if d.skipSpace(); d.off < len(d.data) && d.data[d.off] == '}' {
+		d.off++
+		return nil
+	}
+	for {
+		if d.skipSpace(); d.off >= len(d.data) || d.data[d.off] != '"' {
+			return d.syntaxErrorAt("looking for beginning of object key string")
+		}
+		key, _, err := d.literalString()
+		if err != nil {
+			return err
+		}
+		if d.skipSpace(); d.off >= len(d.data) || d.data[d.off] != ':' {
+			return d.syntaxErrorAt("after object key")
+		}
+		d.off++
+
+		switch {
+		case mapElem.IsValid():
+			mapElem.SetZero()
+			if err := d.value(mapElem); err != nil {
+				return err
+			}
+			kv, err := d.mapKey(v.Type().Key(), key)
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(kv, mapElem)
+
+		case v.IsValid():
+			var fv reflect.Value
+			if f := fields.lookup(key); f != nil {
+				fv = v
+				for i, idx := range f.index {
+					if i > 0 && fv.Kind() == reflect.Pointer {
+						if fv.IsNil() {
+							if !fv.CanSet() {
+								return fmt.Errorf("nukejson: cannot set embedded pointer to unexported struct: %v", fv.Type().Elem())
+							}
+							fv.Set(nuke.NewOf(d.a, fv.Type().Elem()))
+						}
+						fv = fv.Elem()
+					}
+					fv = fv.Field(idx)
+				}
+				if f.quoted {
+					if err := d.quoted(fv); err != nil {
+						return err
+					}
+					break
+				}
+			}
+			if err := d.value(fv); err != nil {
+				return err
+			}
+
+		default:
+			if err := d.value(reflect.Value{}); err != nil {
+				return err
+			}
+		}
+
+		if d.skipSpace(); d.off >= len(d.data) {
+			return d.syntaxError("unexpected end of JSON input")
+		}
+		switch d.data[d.off] {
+		case ',':
+			d.off++
+		case '}':
+			d.off++
+			return nil
+		default:
+			return d.syntaxErrorAt("after object key:value pair")
+		}
+	}
+}
+
+// quoted decodes the next JSON value into v, the value of a field with the ",string" option,
+// which is encoded within a JSON string.
+func (d *decodeState) quoted(v reflect.Value) error {
+	if d.skipSpace(); d.off >= len(d.data) {
+		return d.syntaxError("unexpected end of JSON input")
+	}
+	switch d.data[d.off] {
+	case '"':
+	case 'n':
+		return d.value(v) // null is decoded as if the option wasn't there
+	default:
+		if err := d.value(reflect.Value{}); err != nil {
+			return err
+		}
+		return fmt.Errorf("nukejson: invalid use of ,string struct tag, trying to unmarshal unquoted value into %v", v.Type())
+	}
+	s, _, err := d.literalString()
+	if err != nil {
+		return err
+	}
+	inner := decodeState{a: d.a, data: s}
+	if len(s) == 0 || !strings.ContainsRune(`"-0123456789ftn`, rune(s[0])) || inner.value(v) != nil || inner.off != len(s) {
+		return fmt.Errorf("nukejson: invalid use of ,string struct tag, trying to unmarshal %q into %v", s, v.Type())
+	}
+	return nil
+}
+
+func (d *decodeState) mapKey(typ reflect.Type, key []byte) (reflect.Value, error) {
+	switch typ.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(nuke.MakeString(d.a, key)).Convert(typ), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(bytesString(key), 10, 64)
+		if err != nil || reflect.Zero(typ).OverflowInt(n) {
+			return reflect.Value{}, d.typeError("number "+string(key), typ)
+		}
+		return reflect.ValueOf(n).Convert(typ), nil
+
+	default:
+		n, err := strconv.ParseUint(bytesString(key), 10, 64)
+		if err != nil || reflect.Zero(typ).OverflowUint(n) {
+			return reflect.Value{}, d.typeError("number "+string(key), typ)
+		}
+		return reflect.ValueOf(n).Convert(typ), nil
+	}
+}
+
+func (d *decodeState) array(v reflect.Value) error {
+	d.off++ // '['
+
+	if v.IsValid() {
+		switch v.Kind() {
+		case reflect.Interface:
+			if v.NumMethod() != 0 {
+				return d.typeError("array", v.Type())
+			}
+			s, err := d.arrayInterface()
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(s))
+			return nil
+
+		case reflect.Array, reflect.Slice:
+
+		default:
+			d.off--
+			if err := d.value(reflect.Value{}); err != nil {
+				return err
+			}
+			return d.typeError("array", v.Type())
+		}
+	}
+	i := 0
+	if d.skipSpace(); d.off < len(d.data) && d.data[d.off] == ']' {
+		d.off++
+	} else {
+		for {
+			var ev reflect.Value
+			if v.IsValid() {
+				if v.Kind() == reflect.Slice {
+					if i >= v.Cap() {
+						v.Set(d.growSlice(v, i+1))
+					}
+					if i >= v.Len() {
+						v.SetLen(i + 1)
+					}
+				}
+				if i < v.Len() {
+					ev = v.Index(i)
+					ev.SetZero()
+				}
+			}
+			if err := d.value(ev); err != nil {
+				return err
+			}
+			i++
+
+			if d.skipSpace(); d.off >= len(d.data) {
+				return d.syntaxError("unexpected end of JSON input")
+			}
+			if d.data[d.off] == ']' {
+				d.off++
+				break
+			}
+			if d.data[d.off] != ',' {
+				return d.syntaxErrorAt("after array element")
+			}
+			d.off++
+		}
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	switch {
+	case v.Kind() == reflect.Array:
+		for ; i < v.Len(); i++ {
+			v.Index(i).SetZero()
+		}
+	case i < v.Len():
+		v.SetLen(i)
+	case i == 0 && v.IsNil():
+		v.Set(nuke.MakeSliceOf(d.a, v.Type(), 0, 0))
+	}
+	return nil
+}
+
+// growSlice returns a copy of s with room for at least n elements,
+// growing its capacity like SliceAppend does.
+func (d *decodeState) growSlice(s reflect.Value, n int) reflect.Value {
+	newCap := s.Cap()
+	switch {
+	case newCap == 0:
+		newCap = 4
+	case newCap < 256:
+		newCap *= 2
+	default:
+		newCap += newCap / 4
+	}
+	newCap = max(newCap, n)
+	s2 := nuke.MakeSliceOf(d.a, s.Type(), s.Len(), newCap)
+	reflect.Copy(s2, s)
+	return s2
+}
+
+func (d *decodeState) str(v reflect.Value) error {
+	s, unquoted, err := d.literalString()
+	if err != nil || !v.IsValid() {
+		return err
+	}
+	str := func() string {
+		if unquoted {
+			// Unquoted strings are already a copy of their own.
+			return unsafe.String(unsafe.SliceData(s), len(s))
+		}
+		return nuke.MakeString(d.a, s)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if v.Type() == numberType && !isValidNumber(s) {
+			return fmt.Errorf("nukejson: invalid number literal, trying to unmarshal %q into Number", s)
+		}
+		v.SetString(str())
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return d.typeError("string", v.Type())
+		}
+		b := nuke.MakeSlice[byte](d.a, base64.StdEncoding.DecodedLen(len(s)), base64.StdEncoding.DecodedLen(len(s)))
+		n, err := base64.StdEncoding.Decode(b, s)
+		if err != nil {
+			return err
+		}
+		v.SetBytes(b[:n])
+
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return d.typeError("string", v.Type())
+		}
+		v.Set(reflect.ValueOf(str()))
+
+	default:
+		return d.typeError("string", v.Type())
+	}
+	return nil
+}
+
+func (d *decodeState) literal(v reflect.Value) error {
+	var lit string
+	switch d.data[d.off] {
+	case 't':
+		lit = "true"
+	case 'f':
+		lit = "false"
+	default:
+		lit = "null"
+	}
+	if !bytes.HasPrefix(d.data[d.off:], []byte(lit)) {
+		return d.syntaxErrorAt("in literal " + lit)
+	}
+	d.off += len(lit)
+	if !v.IsValid() {
+		return nil
+	}
+	if lit == "null" {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			v.SetZero()
+		}
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(lit == "true")
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return d.typeError("bool", v.Type())
+		}
+		v.Set(reflect.ValueOf(lit == "true"))
+	default:
+		return d.typeError("bool", v.Type())
+	}
+	return nil
+}
+
+func (d *decodeState) number(v reflect.Value) error {
+	start := d.off
+	for d.off < len(d.data) && isNumberChar(d.data[d.off]) {
+		d.off++
+	}
+	lit := d.data[start:d.off]
+	if !isValidNumber(lit) {
+		return d.syntaxError("invalid number literal " + strconv.Quote(string(lit)))
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	s := bytesString(lit)
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v.OverflowInt(n) {
+			return d.typeError("number "+string(lit), v.Type())
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil || v.OverflowUint(n) {
+			return d.typeError("number "+string(lit), v.Type())
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil || v.OverflowFloat(n) {
+			return d.typeError("number "+string(lit), v.Type())
+		}
+		v.SetFloat(n)
+
+	case reflect.String:
+		if v.Type() != numberType {
+			return d.typeError("number", v.Type())
+		}
+		v.SetString(nuke.MakeString(d.a, lit))
+
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return d.typeError("number", v.Type())
+		}
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return d.typeError("number "+string(lit), v.Type())
+		}
+		v.Set(reflect.ValueOf(n))
+
+	default:
+		return d.typeError("number", v.Type())
+	}
+	return nil
+}
+
+// valueInterface decodes the next JSON value as encoding/json does when decoding into an empty interface.
+func (d *decodeState) valueInterface() (any, error) {
+	var v any
+	err := d.value(reflect.ValueOf(&v).Elem())
+	return v, err
+}
+
+func (d *decodeState) objectInterface() (map[string]any, error) {
+	m := make(map[string]any)
+	if d.skipSpace(); d.off < len(d.data) && d.data[d.off] == '}' {
+		d.off++
+		return m, nil
+	}
+	for {
+		if d.skipSpace(); d.off >= len(d.data) || d.data[d.off] != '"' {
+			return nil, d.syntaxErrorAt("looking for beginning of object key string")
+		}
+		key, _, err := d.literalString()
+		if err != nil {
+			return nil, err
+		}
+		if d.skipSpace(); d.off >= len(d.data) || d.data[d.off] != ':' {
+			return nil, d.syntaxErrorAt("after object key")
+		}
+		d.off++
+
+		v, err := d.valueInterface()
+		if err != nil {
+			return nil, err
+		}
+		m[nuke.MakeString(d.a, key)] = v
+
+		if d.skipSpace(); d.off >= len(d.data) {
+			return nil, d.syntaxError("unexpected end of JSON input")
+		}
+		switch d.data[d.off] {
+		case ',':
+			d.off++
+		case '}':
+			d.off++
+			return m, nil
+		default:
+			return nil, d.syntaxErrorAt("after object key:value pair")
+		}
+	}
+}
+
+func (d *decodeState) arrayInterface() ([]any, error) {
+	s := []any{}
+	if d.skipSpace(); d.off < len(d.data) && d.data[d.off] == ']' {
+		d.off++
+		return s, nil
+	}
+	for {
+		v, err := d.valueInterface()
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+
+		if d.skipSpace(); d.off >= len(d.data) {
+			return nil, d.syntaxError("unexpected end of JSON input")
+		}
+		switch d.data[d.off] {
+		case ',':
+			d.off++
+		case ']':
+			d.off++
+			return s, nil
+		default:
+			return nil, d.syntaxErrorAt("after array element")
+		}
+	}
+}
+
+// literalString consumes a string literal and returns its contents. Strings without escape
+// sequences are returned as a subslice of the input, which must be copied before being retained,
+// while the rest are unquoted into arena memory, as reported by unquoted.
+func (d *decodeState) literalString() (s []byte, unquoted bool, err error) {
+	d.off++ // '"'
+	start := d.off
+	for d.off < len(d.data) {
+		switch c := d.data[d.off]; {
+		case c == '"':
+			d.off++
+			return d.data[start : d.off-1], false, nil
+		case c == '\\':
+			s, err := d.unquote(start)
+			return s, true, err
+		case c < ' ':
+			return nil, false, d.syntaxErrorAt("in string literal")
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(d.data[d.off:])
+			if r == utf8.RuneError && size == 1 {
+				s, err := d.unquote(start)
+				return s, true, err
+			}
+			d.off += size
+		default:
+			d.off++
+		}
+	}
+	return nil, false, d.syntaxError("unexpected end of JSON input")
+}
+
+// unquote decodes the string literal starting at start, which contains escape sequences or invalid UTF-8.
+func (d *decodeState) unquote(start int) ([]byte, error) {
+	b := nuke.MakeSlice[byte](d.a, 0, d.off-start+16)
+	b = append(b, d.data[start:d.off]...)
+
+	for d.off < len(d.data) {
+		c := d.data[d.off]
+		switch {
+		case c == '"':
+			d.off++
+			return b, nil
+
+		case c == '\\':
+			if d.off+1 >= len(d.data) {
+				return nil, d.syntaxError("unexpected end of JSON input")
+			}
+			switch e := d.data[d.off+1]; e {
+			case '"', '\\', '/':
+				b = nuke.SliceAppend(d.a, b, e)
+			case 'b':
+				b = nuke.SliceAppend(d.a, b, '\b')
+			case 'f':
+				b = nuke.SliceAppend(d.a, b, '\f')
+			case 'n':
+				b = nuke.SliceAppend(d.a, b, '\n')
+			case 'r':
+				b = nuke.SliceAppend(d.a, b, '\r')
+			case 't':
+				b = nuke.SliceAppend(d.a, b, '\t')
+			case 'u':
+				d.off += 2
+				r, ok := d.hex4()
+				if !ok {
+					return nil, d.syntaxErrorAt("in \\u hexadecimal character escape")
+				}
+				if utf16.IsSurrogate(r) {
+					// A valid surrogate pair must follow right away, otherwise it's replaced by U+FFFD.
+					r1 := utf8.RuneError
+					if bytes.HasPrefix(d.data[d.off:], []byte(`\u`)) {
+						off := d.off
+						d.off += 2
+						if r2, ok := d.hex4(); ok {
+							r1 = utf16.DecodeRune(r, r2)
+						}
+						if r1 == utf8.RuneError {
+							d.off = off
+						}
+					}
+					r = r1
+				}
+				b = appendRune(d.a, b, r)
+				continue
+			default:
+				return nil, d.syntaxErrorAt("in string escape code")
+			}
+			d.off += 2
+
+		case c < ' ':
+			return nil, d.syntaxErrorAt("in string literal")
+
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(d.data[d.off:])
+			if r == utf8.RuneError && size == 1 {
+				b = appendRune(d.a, b, utf8.RuneError)
+			} else {
+				b = nuke.SliceAppend(d.a, b, d.data[d.off:d.off+size]...)
+			}
+			d.off += size
+
+		default:
+			b = nuke.SliceAppend(d.a, b, c)
+			d.off++
+		}
+	}
+	return nil, d.syntaxError("unexpected end of JSON input")
+}
+
+// hex4 decodes the four hexadecimal digits of a \u escape sequence.
+func (d *decodeState) hex4() (rune, bool) {
+	if d.off+4 > len(d.data) {
+		return 0, false
+	}
+	var r rune
+	for _, c := range d.data[d.off : d.off+4] {
+		switch {
+		case '0' <= c && c <= '9':
+			c = c - '0'
+		case 'a' <= c && c <= 'f':
+			c = c - 'a' + 10
+		case 'A' <= c && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r*16 + rune(c)
+	}
+	d.off += 4
+	return r, true
+}
+
+func (d *decodeState) skipSpace() {
+	for d.off < len(d.data) {
+		switch d.data[d.off] {
+		case ' ', '\t', '\r', '\n':
+			d.off++
+		default:
+			return
+		}
+	}
+}
+
+func (d *decodeState) syntaxError(msg string) error {
+	return &SyntaxError{msg: msg, Offset: int64(d.off)}
+}
+
+// syntaxErrorAt reports the character at the current offset as invalid in the given context.
+func (d *decodeState) syntaxErrorAt(context string) error {
+	if d.off >= len(d.data) {
+		return d.syntaxError("unexpected end of JSON input")
+	}
+	return d.syntaxError("invalid character " + quoteChar(d.data[d.off]) + " " + context)
+}
+
+func (d *decodeState) typeError(value string, typ reflect.Type) error {
+	return &json.UnmarshalTypeError{Value: value, Type: typ, Offset: int64(d.off)}
+}
+
+func appendRune(a nuke.Arena, b []byte, r rune) []byte {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	return nuke.SliceAppend(a, b, buf[:n]...)
+}
+
+func quoteChar(c byte) string {
+	if c == '\'' {
+		return `'\''`
+	}
+	if c == '"' {
+		return `'"'`
+	}
+	s := strconv.Quote(string(c))
+	return "'" + s[1:len(s)-1] + "'"
+}
+
+// bytesString returns a string sharing memory with b, which must not be modified while it's in use.
+func bytesString(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+func isNumberChar(c byte) bool {
+	return '0' <= c && c <= '9' || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E'
+}
+
+// isValidNumber reports whether s is a valid JSON number literal.
+func isValidNumber(s []byte) bool {
+	if len(s) == 0 {
+		return false
+	}
+	if s[0] == '-' {
+		if s = s[1:]; len(s) == 0 {
+			return false
+		}
+	}
+	switch {
+	case s[0] == '0':
+		s = s[1:]
+	case '1' <= s[0] && s[0] <= '9':
+		s = s[1:]
+		for len(s) > 0 && '0' <= s[0] && s[0] <= '9' {
+			s = s[1:]
+		}
+	default:
+		return false
+	}
+	if len(s) >= 2 && s[0] == '.' && '0' <= s[1] && s[1] <= '9' {
+		s = s[2:]
+		for len(s) > 0 && '0' <= s[0] && s[0] <= '9' {
+			s = s[1:]
+		}
+	}
+	if len(s) >= 2 && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s[0] == '+' || s[0] == '-' {
+			if s = s[1:]; len(s) == 0 {
+				return false
+			}
+		}
+		for len(s) > 0 && '0' <= s[0] && s[0] <= '9' {
+			s = s[1:]
+		}
+	}
+	return len(s) == 0
+}
+
+// field is a struct field JSON object keys are decoded into.
+type field struct {
+	name  string
+	index []int
+
+	// quoted reports whether the field has the ",string" option, and it applies to its type.
+	quoted bool
+}
+
+type structFields struct {
+	list   []field
+	byName map[string]int
+}
+
+// lookup returns the field matching key, preferring an exact match over a case-insensitive one.
+func (fs structFields) lookup(key []byte) *field {
+	if i, ok := fs.byName[string(key)]; ok {
+		return &fs.list[i]
+	}
+	for i := range fs.list {
+		if bytes.EqualFold([]byte(fs.list[i].name), key) {
+			return &fs.list[i]
+		}
+	}
+	return nil
+}
+
+var fieldCache sync.Map // map[reflect.Type]structFields
+
+func cachedFields(typ reflect.Type) structFields {
+	if fs, ok := fieldCache.Load(typ); ok {
+		return fs.(structFields)
+	}
+	fs, _ := fieldCache.LoadOrStore(typ, typeFields(typ))
+	return fs.(structFields)
+}
+
+// typeFields returns the fields JSON objects are decoded into for the given struct type,
+// applying the same visibility rules as encoding/json to fields of embedded structs.
+func typeFields(typ reflect.Type) structFields {
+	type candidate struct {
+		field
+		depth  int
+		tagged bool
+	}
+	var candidates []candidate
+
+	type level struct {
+		typ   reflect.Type
+		index []int
+	}
+	current := []level{{typ: typ}}
+	visited := map[reflect.Type]bool{}
+
+	for depth := 0; len(current) > 0; depth++ {
+		var next []level
+		for _, l := range current {
+			if visited[l.typ] {
+				continue
+			}
+			visited[l.typ] = true
+
+			for i := 0; i < l.typ.NumField(); i++ {
+				sf := l.typ.Field(i)
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous {
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := append(append([]int(nil), l.index...), i)
+
+				if name == "" && sf.Anonymous && ft.Kind() == reflect.Struct {
+					next = append(next, level{typ: ft, index: index})
+					continue
+				}
+				tagged := name != ""
+				if name == "" {
+					name = sf.Name
+				}
+				quoted := false
+				if slices.Contains(strings.Split(opts, ","), "string") {
+					switch ft.Kind() {
+					case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+						reflect.Float32, reflect.Float64, reflect.String:
+						quoted = true
+					}
+				}
+				candidates = append(candidates, candidate{
+					field:  field{name: name, index: index, quoted: quoted},
+					depth:  depth,
+					tagged: tagged,
+				})
+			}
+		}
+		current = next
+	}
+
+	// Among fields sharing a name, the shallowest one wins, or the only tagged one among them.
+	// Names remaining ambiguous are dropped altogether.
+	fs := structFields{byName: make(map[string]int)}
+	byName := make(map[string][]candidate)
+	var names []string
+	for _, c := range candidates {
+		if _, ok := byName[c.name]; !ok {
+			names = append(names, c.name)
+		}
+		byName[c.name] = append(byName[c.name], c)
+	}
+	for _, name := range names {
+		cs := byName[name]
+		var dominant []candidate
+		for _, c := range cs {
+			if c.depth == cs[0].depth {
+				dominant = append(dominant, c)
+			}
+		}
+		if len(dominant) > 1 {
+			var tagged []candidate
+			for _, c := range dominant {
+				if c.tagged {
+					tagged = append(tagged, c)
+				}
+			}
+			dominant = tagged
+		}
+		if len(dominant) != 1 {
+			continue
+		}
+		fs.byName[name] = len(fs.list)
+		fs.list = append(fs.list, dominant[0].field)
+	}
+	return fs
+}
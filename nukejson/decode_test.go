@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukejson
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+	"github.com/ortuman/nuke/nuketest"
+)
+
+type Inner struct {
+	ID   int64    `json:"id"`
+	Tags []string `json:"tags"`
+}
+
+type Embedded struct {
+	Kind string
+}
+
+type Doc struct {
+	Embedded
+	Name    string            `json:"name"`
+	Score   float64           `json:"score"`
+	Active  bool              `json:"active"`
+	Count   *uint16           `json:"count"`
+	Items   []Inner           `json:"items"`
+	Fixed   [2]int            `json:"fixed"`
+	Attrs   map[string]string `json:"attrs"`
+	ByID    map[int]bool      `json:"by_id"`
+	Raw     []byte            `json:"raw"`
+	Any     any               `json:"any"`
+	Num     json.Number       `json:"num"`
+	When    time.Time         `json:"when"`
+	Ignored string            `json:"-"`
+	private int
+}
+
+const docJSON = `{
+	"Kind": "doc",
+	"name": "caf\u00e9 \"nuke\"",
+	"score": -1.5e2,
+	"active": true,
+	"count": 7,
+	"items": [{"id": 1, "tags": ["a", "b"]}, {"ID": 2, "tags": null}, {"id": 3, "tags": []}],
+	"fixed": [1, 2, 3],
+	"attrs": {"k": "v", "emoji": "\ud83d\ude00"},
+	"by_id": {"10": true},
+	"raw": "aGVsbG8=",
+	"any": {"list": [1, "two", false, null]},
+	"num": 12.50,
+	"when": "2024-01-02T03:04:05Z",
+	"Ignored": "x",
+	"unknown": {"nested": [1, 2, {"deep": "value"}]}
+}`
+
+func TestUnmarshal(t *testing.T) {
+	var want Doc
+	require.NoError(t, json.Unmarshal([]byte(docJSON), &want))
+
+	arena := nuke.NewGCArena(4096)
+
+	var got Doc
+	require.NoError(t, Unmarshal(arena, []byte(docJSON), &got))
+	require.Equal(t, want, got)
+}
+
+func TestUnmarshalAllocatesFromArena(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	arena := &nuketest.MockArena{}
+
+	var v struct {
+		A string
+		B []int
+		C *int
+	}
+	require.NoError(t, Unmarshal(arena, []byte(`{"A": "hello", "B": [1, 2, 3], "C": 1}`), &v))
+	require.Equal(t, "hello", v.A)
+	require.Equal(t, []int{1, 2, 3}, v.B)
+	require.Equal(t, 1, *v.C)
+
+	// One allocation for the string, another for the slice and another for the pointer.
+	require.Len(t, arena.Requests(), 3)
+}
+
+func TestUnmarshalReusesSlices(t *testing.T) {
+	arena := &nuketest.MockArena{}
+
+	v := make([]int, 0, 8)
+	require.NoError(t, Unmarshal(arena, []byte(`[1, 2, 3]`), &v))
+	require.Equal(t, []int{1, 2, 3}, v)
+	require.Empty(t, arena.Requests())
+}
+
+func TestUnmarshalInterface(t *testing.T) {
+	const data = `{"a": [1, "x", true, null, {"b": {}}], "c": -0.5}`
+
+	var want any
+	require.NoError(t, json.Unmarshal([]byte(data), &want))
+
+	var got any
+	require.NoError(t, Unmarshal(nuke.NewGCArena(1024), []byte(data), &got))
+	require.Equal(t, want, got)
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	arena := nuke.NewGCArena(1024)
+
+	for _, data := range []string{
+		``,
+		`{`,
+		`{"a" 1}`,
+		`{"a": 1,}`,
+		`[1 2]`,
+		`"abc`,
+		`"\x"`,
+		"\"\x01\"",
+		`01`,
+		`1.`,
+		`-`,
+		`tru`,
+		`{} {}`,
+	} {
+		var v any
+		err := Unmarshal(arena, []byte(data), &v)
+		require.Error(t, err, data)
+		require.Error(t, json.Unmarshal([]byte(data), &v), data)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+
+	var n int8
+	require.ErrorAs(t, Unmarshal(arena, []byte(`300`), &n), &typeErr)
+
+	var s struct{ A int }
+	require.ErrorAs(t, Unmarshal(arena, []byte(`{"A": "x"}`), &s), &typeErr)
+	require.ErrorAs(t, Unmarshal(arena, []byte(`[1]`), &s), &typeErr)
+
+	var invalidErr *json.InvalidUnmarshalError
+	require.ErrorAs(t, Unmarshal(arena, []byte(`1`), s), &invalidErr)
+	require.ErrorAs(t, Unmarshal(arena, []byte(`1`), nil), &invalidErr)
+}
+
+type unexportedInner struct {
+	X int
+}
+
+func TestUnmarshalEmbeddedUnexportedPointer(t *testing.T) {
+	arena := nuke.NewGCArena(1024)
+
+	var v struct{ *unexportedInner }
+	require.EqualError(t, Unmarshal(arena, []byte(`{"X": 1}`), &v),
+		"nukejson: cannot set embedded pointer to unexported struct: nukejson.unexportedInner")
+	require.Error(t, json.Unmarshal([]byte(`{"X": 1}`), &v))
+
+	// Non-nil pointers are decoded into.
+	v.unexportedInner = &unexportedInner{}
+	require.NoError(t, Unmarshal(arena, []byte(`{"X": 1}`), &v))
+	require.Equal(t, 1, v.X)
+}
+
+func TestUnmarshalStringOption(t *testing.T) {
+	type quoted struct {
+		N   int      `json:"n,string"`
+		F   *float64 `json:"f,string"`
+		B   bool     `json:",string"`
+		S   string   `json:"s,string"`
+		L   []int    `json:"l,string"` // doesn't apply to slices
+		Nil int      `json:"nil,string"`
+	}
+	const data = `{"n": "5", "f": "-1.5", "B": "true", "s": "\"x\"", "l": [1], "nil": null}`
+
+	var want quoted
+	require.NoError(t, json.Unmarshal([]byte(data), &want))
+
+	var got quoted
+	require.NoError(t, Unmarshal(nuke.NewGCArena(1024), []byte(data), &got))
+	require.Equal(t, want, got)
+	require.Equal(t, 5, got.N)
+
+	for _, data := range []string{
+		`{"n": 5}`,
+		`{"n": "x"}`,
+		`{"n": "5 "}`,
+		`{"n": " 5"}`,
+		`{"n": "[5]"}`,
+		`{"s": "x"}`,
+	} {
+		var v quoted
+		require.ErrorContains(t, Unmarshal(nuke.NewGCArena(1024), []byte(data), &v), "invalid use of ,string struct tag", data)
+		require.Error(t, json.Unmarshal([]byte(data), &v), data)
+	}
+}
+
+func TestUnmarshalInvalidUTF8(t *testing.T) {
+	data := []byte("[\"a\xffb\", \"\\ud800x\"]")
+
+	var want []string
+	require.NoError(t, json.Unmarshal(data, &want))
+
+	var got []string
+	require.NoError(t, Unmarshal(nuke.NewGCArena(1024), data, &got))
+	require.Equal(t, want, got)
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukejson
+
+import (
+	"encoding/json"
+
+	"github.com/ortuman/nuke"
+)
+
+// Marshal returns the JSON encoding of v, like json.Marshal does, in memory allocated
+// from the arena. The returned bytes are only valid until the arena is reset.
+func Marshal(a nuke.Arena, v any) ([]byte, error) {
+	e := NewEncoder(a)
+	if err := e.Encode(v); err != nil {
+		return nil, err
+	}
+	b := e.Bytes()
+	return b[:len(b)-1], nil // drop the trailing newline
+}
+
+// Encoder writes a stream of JSON values, each followed by a newline, to a buffer allocated
+// from an arena. It's configured like json.Encoder, whose scratch memory is already pooled,
+// so encoding allocates no other memory besides the buffer itself.
+type Encoder struct {
+	buf *nuke.Buffer
+	enc *json.Encoder
+}
+
+// NewEncoder returns an Encoder writing to a buffer allocated from the arena.
+func NewEncoder(a nuke.Arena) *Encoder {
+	buf := nuke.NewBuffer(a, 0)
+	return &Encoder{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+// Encode appends the JSON encoding of v to the buffer, followed by a newline.
+// If encoding fails, the buffer is left untouched.
+func (e *Encoder) Encode(v any) error {
+	return e.enc.Encode(v)
+}
+
+// SetIndent instructs the encoder to indent values like json.Encoder.SetIndent does.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.enc.SetIndent(prefix, indent)
+}
+
+// SetEscapeHTML specifies whether problematic HTML characters should be escaped inside
+// JSON quoted strings, like json.Encoder.SetEscapeHTML does.
+func (e *Encoder) SetEscapeHTML(on bool) {
+	e.enc.SetEscapeHTML(on)
+}
+
+// Bytes returns the values encoded so far, which remain valid until the next call
+// to Encode or Reset, or until the arena is reset.
+func (e *Encoder) Bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// Reset discards the values encoded so far, keeping the buffer for future values.
+func (e *Encoder) Reset() {
+	e.buf.Reset()
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukejson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+)
+
+func TestMarshal(t *testing.T) {
+	arena := nuke.NewMonotonicArena(4096, 1)
+
+	v := map[string]any{"a": []int{1, 2, 3}, "b": "<b>", "c": nil}
+	want, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	got, err := Marshal(arena, v)
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got))
+
+	_, err = Marshal(arena, func() {})
+	require.Error(t, err)
+}
+
+func TestEncoder(t *testing.T) {
+	arena := nuke.NewMonotonicArena(4096, 1)
+
+	e := NewEncoder(arena)
+	e.SetEscapeHTML(false)
+	require.NoError(t, e.Encode("<a>"))
+	require.NoError(t, e.Encode(1))
+	require.Equal(t, "\"<a>\"\n1\n", string(e.Bytes()))
+
+	e.Reset()
+	e.SetIndent("", " ")
+	require.NoError(t, e.Encode([]int{1}))
+	require.Equal(t, "[\n 1\n]\n", string(e.Bytes()))
+}
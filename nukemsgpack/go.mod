@@ -0,0 +1,16 @@
+module github.com/ortuman/nuke/nukemsgpack
+
+go 1.25.0
+
+replace github.com/ortuman/nuke => ../
+
+require (
+	github.com/ortuman/nuke v0.0.0
+	github.com/stretchr/testify v1.12.1
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require (
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+)
@@ -0,0 +1,147 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukemsgpack provides MessagePack integration, decoding strings, bytes and
+// generic values into arena memory.
+package nukemsgpack
+
+import (
+	"bytes"
+	"io"
+	"unsafe"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+
+	"github.com/ortuman/nuke"
+)
+
+// allocLimit is the size above which strings and bytes are read in chunks, so that a corrupted
+// or malicious length can't make the decoder allocate more memory than the input actually holds.
+const allocLimit = 1 << 20
+
+// zeroChunk is the unit strings and bytes above allocLimit are read in.
+var zeroChunk [64 << 10]byte
+
+// Decoder is a msgpack.Decoder whose DecodeString, DecodeBytes, DecodeInterface, DecodeMap and
+// DecodeSlice methods allocate the strings and byte slices they return from an arena, so they're
+// only valid until the arena is reset. Maps and slices of generic values are still allocated from
+// the heap, since they hold pointers.
+//
+// The decoder is also set as the map decoder of the underlying msgpack.Decoder, so that maps decoded
+// into interface values by Decode get their keys allocated from the arena too. Other strings and bytes
+// decoded by Decode are allocated from the heap, so types decoded in hot paths should implement
+// msgpack.CustomDecoder in terms of the methods of a Decoder instead.
+// Interned strings and dictionaries are not supported by the methods of a Decoder.
+type Decoder struct {
+	*msgpack.Decoder
+	a nuke.Arena
+}
+
+// NewDecoder returns a Decoder reading from r and allocating from the arena.
+func NewDecoder(a nuke.Arena, r io.Reader) *Decoder {
+	d := &Decoder{Decoder: msgpack.NewDecoder(r), a: a}
+	d.SetMapDecoder(func(*msgpack.Decoder) (any, error) {
+		return d.DecodeMap()
+	})
+	return d
+}
+
+// Unmarshal decodes the MessagePack-encoded data and stores the result in the value pointed to by v,
+// like msgpack.Unmarshal does, allocating the keys of generic maps from the arena.
+func Unmarshal(a nuke.Arena, data []byte, v any) error {
+	return NewDecoder(a, bytes.NewReader(data)).Decode(v)
+}
+
+// DecodeString decodes a string, or nil as an empty string, allocating it from the arena.
+func (d *Decoder) DecodeString() (string, error) {
+	b, err := d.decodeBytes()
+	return unsafe.String(unsafe.SliceData(b), len(b)), err
+}
+
+// DecodeBytes decodes a byte slice, or a string as its bytes, allocating it from the arena.
+func (d *Decoder) DecodeBytes() ([]byte, error) {
+	return d.decodeBytes()
+}
+
+// DecodeInterface decodes any value like msgpack.Decoder.DecodeInterface does, but using
+// the methods of the Decoder for strings, bytes, maps and arrays.
+func (d *Decoder) DecodeInterface() (any, error) {
+	c, err := d.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case msgpcode.IsFixedString(c), c == msgpcode.Str8, c == msgpcode.Str16, c == msgpcode.Str32:
+		return d.DecodeString()
+
+	case c == msgpcode.Bin8, c == msgpcode.Bin16, c == msgpcode.Bin32:
+		return d.DecodeBytes()
+
+	case msgpcode.IsFixedMap(c), c == msgpcode.Map16, c == msgpcode.Map32:
+		return d.DecodeMap()
+
+	case msgpcode.IsFixedArray(c), c == msgpcode.Array16, c == msgpcode.Array32:
+		return d.DecodeSlice()
+
+	default:
+		return d.Decoder.DecodeInterface()
+	}
+}
+
+// DecodeMap decodes a map of generic values keyed by strings, or nil.
+func (d *Decoder) DecodeMap() (map[string]any, error) {
+	n, err := d.DecodeMapLen()
+	if err != nil || n == -1 {
+		return nil, err
+	}
+	m := make(map[string]any, min(n, allocLimit/64))
+	for i := 0; i < n; i++ {
+		k, err := d.DecodeString()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.DecodeInterface()
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+// DecodeSlice decodes an array of generic values, or nil.
+func (d *Decoder) DecodeSlice() ([]any, error) {
+	n, err := d.DecodeArrayLen()
+	if err != nil || n == -1 {
+		return nil, err
+	}
+	s := make([]any, 0, min(n, allocLimit/16))
+	for i := 0; i < n; i++ {
+		v, err := d.DecodeInterface()
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+	}
+	return s, nil
+}
+
+func (d *Decoder) decodeBytes() ([]byte, error) {
+	n, err := d.DecodeBytesLen()
+	if err != nil || n <= 0 {
+		return nil, err
+	}
+	if n <= allocLimit {
+		b := nuke.MakeSlice[byte](d.a, n, n)
+		return b, d.ReadFull(b)
+	}
+	b := nuke.MakeSlice[byte](d.a, 0, allocLimit)
+	for len(b) < n {
+		off := len(b)
+		b = nuke.SliceAppend(d.a, b, zeroChunk[:min(n-off, len(zeroChunk))]...)
+		if err := d.ReadFull(b[off:]); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
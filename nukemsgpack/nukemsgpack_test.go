@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukemsgpack
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/ortuman/nuke"
+	"github.com/ortuman/nuke/nuketest"
+)
+
+type event struct {
+	Name  string
+	Attrs map[string]any
+}
+
+// decode decodes the event in terms of the arena-aware Decoder methods.
+func (e *event) decode(d *Decoder) error {
+	var err error
+	if e.Name, err = d.DecodeString(); err != nil {
+		return err
+	}
+	e.Attrs, err = d.DecodeMap()
+	return err
+}
+
+func TestDecoder(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	require.NoError(t, enc.EncodeString("login"))
+	require.NoError(t, enc.Encode(map[string]any{
+		"user": "alice",
+		"tags": []any{"a", []byte{1, 2}, int8(3), nil},
+		"meta": map[string]any{"ok": true},
+	}))
+
+	arena := &nuketest.MockArena{}
+	d := NewDecoder(arena, &buf)
+
+	var e event
+	require.NoError(t, e.decode(d))
+	require.Equal(t, "login", e.Name)
+	require.Equal(t, map[string]any{
+		"user": "alice",
+		"tags": []any{"a", []byte{1, 2}, int8(3), nil},
+		"meta": map[string]any{"ok": true},
+	}, e.Attrs)
+
+	// Every string and byte slice, keys included, has been allocated from the arena.
+	require.Len(t, arena.Requests(), 8)
+}
+
+func TestDecoderLargeString(t *testing.T) {
+	s := strings.Repeat("x", 3*allocLimit+1)
+	b, err := msgpack.Marshal(s)
+	require.NoError(t, err)
+
+	d := NewDecoder(nuke.NewMonotonicArena(4*allocLimit, 1), bytes.NewReader(b))
+	got, err := d.DecodeString()
+	require.NoError(t, err)
+	require.Equal(t, s, got)
+
+	// Lengths exceeding the input fail rather than allocating memory for them.
+	d = NewDecoder(nil, bytes.NewReader(b[:1<<10]))
+	_, err = d.DecodeString()
+	require.Error(t, err)
+}
+
+func TestUnmarshal(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	b, err := msgpack.Marshal(map[string]any{"A": map[string]any{"b": 1}})
+	require.NoError(t, err)
+
+	arena := &nuketest.MockArena{}
+
+	var v struct{ A any }
+	require.NoError(t, Unmarshal(arena, b, &v))
+	require.Equal(t, map[string]any{"b": int8(1)}, v.A)
+	require.Len(t, arena.Requests(), 1)
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukeotel reports nuke arena metrics through OpenTelemetry.
+package nukeotel
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/ortuman/nuke"
+)
+
+// ScopeName is the instrumentation scope name of the meter used to report arena metrics.
+const ScopeName = "github.com/ortuman/nuke/nukeotel"
+
+// ArenaNameKey is the attribute key identifying the arena a measurement belongs to.
+const ArenaNameKey = attribute.Key("nuke.arena.name")
+
+// ErrMetricsNotSupported is returned when registering an arena that doesn't expose its metrics.
+var ErrMetricsNotSupported = errors.New("nukeotel: arena doesn't expose metrics")
+
+// Register creates the observable instruments reporting the metrics of the provided arena,
+// using a meter obtained from mp. Every measurement carries the arena name under ArenaNameKey.
+// Since measurements are collected from the goroutine running the metric reader,
+// the arena must be safe to be accessed concurrently.
+// The returned registration should be unregistered once the arena is no longer in use.
+func Register(mp metric.MeterProvider, name string, a nuke.Arena) (metric.Registration, error) {
+	mr, ok := a.(nuke.Stater)
+	if !ok {
+		return nil, ErrMetricsNotSupported
+	}
+	meter := mp.Meter(ScopeName)
+
+	allocs, err := meter.Int64ObservableCounter(
+		"nuke.arena.allocations",
+		metric.WithDescription("Number of allocations served by the arena."),
+		metric.WithUnit("{allocation}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	allocated, err := meter.Int64ObservableCounter(
+		"nuke.arena.allocated",
+		metric.WithDescription("Number of bytes allocated from the arena."),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	overflows, err := meter.Int64ObservableCounter(
+		"nuke.arena.overflows",
+		metric.WithDescription("Number of allocations the arena couldn't serve."),
+		metric.WithUnit("{allocation}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	heapFallbacks, err := meter.Int64ObservableCounter(
+		"nuke.arena.heap_fallbacks",
+		metric.WithDescription("Number of allocations sent to the heap because the arena couldn't serve them."),
+		metric.WithUnit("{allocation}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	resets, err := meter.Int64ObservableCounter(
+		"nuke.arena.resets",
+		metric.WithDescription("Number of times the arena has been reset."),
+		metric.WithUnit("{reset}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	attrs := metric.WithAttributeSet(attribute.NewSet(ArenaNameKey.String(name)))
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		m := mr.Metrics()
+		o.ObserveInt64(allocs, int64(m.Allocs), attrs)
+		o.ObserveInt64(allocated, int64(m.AllocatedBytes), attrs)
+		o.ObserveInt64(overflows, int64(m.FailedAllocs), attrs)
+		o.ObserveInt64(heapFallbacks, int64(m.HeapFallbacks), attrs)
+		o.ObserveInt64(resets, int64(m.Resets), attrs)
+		return nil
+	}, allocs, allocated, overflows, heapFallbacks, resets)
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukeotel
+
+import (
+	"context"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/ortuman/nuke"
+	"github.com/ortuman/nuke/nuketest"
+)
+
+type noMetricsArena struct{}
+
+func (noMetricsArena) Alloc(_, _ uintptr) unsafe.Pointer { return nil }
+func (noMetricsArena) Reset(_ bool)                      {}
+
+func TestRegister(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	arena := nuke.NewConcurrentArena(nuke.NewMonotonicArena(1024, 1))
+
+	reg, err := Register(mp, "test", arena)
+	require.NoError(t, err)
+	defer func() { _ = reg.Unregister() }()
+
+	_ = nuke.New[int](arena)
+	_ = nuke.MakeSlice[byte](arena, 0, 2048) // sent to the heap
+	arena.Reset(false)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	values := make(map[string]int64)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		sum := m.Data.(metricdata.Sum[int64])
+		require.Len(t, sum.DataPoints, 1)
+
+		dp := sum.DataPoints[0]
+		name, ok := dp.Attributes.Value(ArenaNameKey)
+		require.True(t, ok)
+		require.Equal(t, "test", name.AsString())
+
+		values[m.Name] = dp.Value
+	}
+	require.Equal(t, map[string]int64{
+		"nuke.arena.allocations":    1,
+		"nuke.arena.allocated":      8,
+		"nuke.arena.overflows":      1,
+		"nuke.arena.heap_fallbacks": 1,
+		"nuke.arena.resets":         1,
+	}, values)
+}
+
+func TestRegisterUnsupportedArena(t *testing.T) {
+	mp := sdkmetric.NewMeterProvider()
+
+	_, err := Register(mp, "test", noMetricsArena{})
+	require.ErrorIs(t, err, ErrMetricsNotSupported)
+}
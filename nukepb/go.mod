@@ -0,0 +1,13 @@
+module github.com/ortuman/nuke/nukepb
+
+go 1.25.0
+
+replace github.com/ortuman/nuke => ../
+
+require (
+	github.com/ortuman/nuke v0.0.0
+	github.com/stretchr/testify v1.12.1
+	google.golang.org/protobuf v1.36.11
+)
+
+require go.yaml.in/yaml/v3 v3.0.5 // indirect
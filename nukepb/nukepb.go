@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukepb provides protobuf integration, unmarshalling messages into arena memory.
+package nukepb
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ortuman/nuke"
+)
+
+// ArenaUnmarshaler is implemented by messages capable of allocating their fields from an arena,
+// such as those produced by code generators aware of this package or written by hand.
+type ArenaUnmarshaler interface {
+	UnmarshalArena(a nuke.Arena, b []byte) error
+}
+
+// vtUnsafeUnmarshaler is implemented by messages generated by vtprotobuf with the unmarshal_unsafe
+// feature, whose string and bytes fields alias the input buffer rather than copying it.
+type vtUnsafeUnmarshaler interface {
+	UnmarshalVTUnsafe(b []byte) error
+}
+
+// vtUnmarshaler is implemented by messages generated by vtprotobuf with the unmarshal feature.
+type vtUnmarshaler interface {
+	UnmarshalVT(b []byte) error
+}
+
+// UnmarshalOptions configures the unmarshaler, like proto.UnmarshalOptions does.
+type UnmarshalOptions struct {
+	// Proto holds the options used for messages that are unmarshalled by the proto package.
+	// Messages implementing custom unmarshal methods only honor its Merge option.
+	Proto proto.UnmarshalOptions
+}
+
+// Unmarshal parses the wire-format message in b and places the result in m using the default options.
+func Unmarshal(a nuke.Arena, b []byte, m proto.Message) error {
+	return UnmarshalOptions{}.Unmarshal(a, b, m)
+}
+
+// Unmarshal parses the wire-format message in b and places the result in m, using the most
+// arena-friendly method m implements, in order of preference:
+//
+//   - UnmarshalArena, as defined by ArenaUnmarshaler, which gets the arena to allocate from.
+//   - UnmarshalVTUnsafe, as generated by vtprotobuf, which is passed a copy of b allocated from
+//     the arena, so that all the string and bytes fields of the message share that single copy.
+//   - UnmarshalVT, as generated by vtprotobuf.
+//   - The proto package, according to the Proto options.
+//
+// Except for ArenaUnmarshaler implementations, the message itself, its sub-messages and repeated
+// fields are allocated from the heap, as protobuf runtimes allow no other allocator. Messages whose
+// fields reference arena memory must not be used once the arena is reset.
+func (o UnmarshalOptions) Unmarshal(a nuke.Arena, b []byte, m proto.Message) error {
+	switch mu := m.(type) {
+	case ArenaUnmarshaler:
+		o.reset(m)
+		return mu.UnmarshalArena(a, b)
+
+	case vtUnsafeUnmarshaler:
+		o.reset(m)
+		buf := nuke.MakeSlice[byte](a, len(b), len(b))
+		copy(buf, b)
+		return mu.UnmarshalVTUnsafe(buf)
+
+	case vtUnmarshaler:
+		o.reset(m)
+		return mu.UnmarshalVT(b)
+	}
+	return o.Proto.Unmarshal(b, m)
+}
+
+// reset clears m unless the options request merging into it, as custom unmarshal methods merge.
+func (o UnmarshalOptions) reset(m proto.Message) {
+	if !o.Proto.Merge {
+		proto.Reset(m)
+	}
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukepb
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/ortuman/nuke"
+	"github.com/ortuman/nuke/nuketest"
+)
+
+// vtStringValue mimics a message generated by vtprotobuf with the unmarshal_unsafe feature.
+type vtStringValue struct {
+	*wrapperspb.StringValue
+	buf []byte
+}
+
+func (m *vtStringValue) UnmarshalVTUnsafe(b []byte) error {
+	m.buf = b
+	_, _, n := protowire.ConsumeTag(b)
+	v, _ := protowire.ConsumeBytes(b[n:])
+	m.Value = unsafe.String(unsafe.SliceData(v), len(v))
+	return nil
+}
+
+// arenaStringValue implements ArenaUnmarshaler.
+type arenaStringValue struct {
+	*wrapperspb.StringValue
+	a nuke.Arena
+}
+
+func (m *arenaStringValue) UnmarshalArena(a nuke.Arena, b []byte) error {
+	m.a = a
+	return proto.UnmarshalOptions{Merge: true}.Unmarshal(b, m.StringValue)
+}
+
+func TestUnmarshal(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	b, err := proto.Marshal(wrapperspb.String("hello"))
+	require.NoError(t, err)
+
+	arena := &nuketest.MockArena{}
+
+	// Plain messages are unmarshalled by the proto package.
+	m := &wrapperspb.StringValue{}
+	require.NoError(t, Unmarshal(arena, b, m))
+	require.Equal(t, "hello", m.GetValue())
+	require.Empty(t, arena.Requests())
+
+	// vtprotobuf messages alias a copy of the input allocated from the arena.
+	vm := &vtStringValue{StringValue: &wrapperspb.StringValue{}}
+	require.NoError(t, Unmarshal(arena, b, vm))
+	require.Equal(t, "hello", vm.GetValue())
+	require.Equal(t, b, vm.buf)
+	require.NotSame(t, &b[0], &vm.buf[0])
+	require.Len(t, arena.Requests(), 1)
+
+	am := &arenaStringValue{StringValue: &wrapperspb.StringValue{}}
+	require.NoError(t, Unmarshal(arena, b, am))
+	require.Equal(t, "hello", am.GetValue())
+	require.Same(t, arena, am.a)
+}
+
+func TestUnmarshalMerge(t *testing.T) {
+	b, err := proto.Marshal(wrapperspb.String("hello"))
+	require.NoError(t, err)
+
+	am := &arenaStringValue{StringValue: wrapperspb.String("bye")}
+	require.NoError(t, UnmarshalOptions{Proto: proto.UnmarshalOptions{Merge: true}}.Unmarshal(nil, nil, am))
+	require.Equal(t, "bye", am.GetValue())
+
+	require.NoError(t, Unmarshal(nil, nil, am))
+	require.Empty(t, am.GetValue())
+	require.NoError(t, Unmarshal(nil, b, am))
+	require.Equal(t, "hello", am.GetValue())
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukesafe provides a facade over nuke whose API never mentions unsafe.Pointer,
+// so that codebases forbidding the unsafe package in application code can still use arenas.
+// Memory is only obtained as typed values, slices, strings and handles, with the same
+// semantics as the corresponding functions of nuke.
+//
+// The facade makes no memory safety guarantees beyond those of nuke: values allocated from an
+// arena must not be accessed once it has been reset, and types holding pointers must only be
+// allocated from arenas created with NewGCArena.
+package nukesafe
+
+import (
+	"context"
+
+	"github.com/ortuman/nuke"
+)
+
+// Arena is an opaque arena. The zero value is a nil arena, which allocates from the heap.
+type Arena struct {
+	a nuke.Arena
+}
+
+// NewMonotonicArena returns a monotonic arena with the given number of buffers and buffer size
+// (see nuke.NewMonotonicArena).
+func NewMonotonicArena(bufferSize, bufferCount int, opts ...nuke.Option) Arena {
+	return Arena{a: nuke.NewMonotonicArena(bufferSize, bufferCount, opts...)}
+}
+
+// NewGCArena returns an arena whose memory is visible to the garbage collector, which makes
+// it safe to allocate types holding pointers (see nuke.NewGCArena).
+func NewGCArena(chunkSize int) Arena {
+	return Arena{a: nuke.NewGCArena(chunkSize)}
+}
+
+// NewConcurrentArena returns an arena wrapping a, which is safe to be accessed concurrently
+// from multiple goroutines.
+func NewConcurrentArena(a Arena) Arena {
+	if a.a == nil {
+		return a
+	}
+	return Arena{a: nuke.NewConcurrentArena(a.a)}
+}
+
+// Wrap returns an Arena wrapping a nuke arena, such as one taken from a nuke.ArenaPool.
+func Wrap(a nuke.Arena) Arena {
+	return Arena{a: a}
+}
+
+// Unwrap returns the nuke arena wrapped by a, to be passed to integrations of this module,
+// such as the ones of the nukejson or nukehttp packages.
+func (a Arena) Unwrap() nuke.Arena {
+	return a.a
+}
+
+// Reset resets the arena, optionally releasing its memory. Every value allocated from it
+// becomes invalid. Resetting a nil arena has no effect.
+func (a Arena) Reset(release bool) {
+	if a.a != nil {
+		a.a.Reset(release)
+	}
+}
+
+// Metrics returns the allocation metrics of the arena, or zero metrics if it doesn't expose them.
+func (a Arena) Metrics() nuke.Metrics {
+	if s, ok := a.a.(nuke.Stater); ok {
+		return s.Metrics()
+	}
+	return nuke.Metrics{}
+}
+
+// New allocates a zero value of type T from the arena (see nuke.New).
+func New[T any](a Arena) *T {
+	return nuke.New[T](a.a)
+}
+
+// MakeSlice creates a slice of type T with the given length and capacity from the arena
+// (see nuke.MakeSlice).
+func MakeSlice[T any](a Arena, len, cap int) []T {
+	return nuke.MakeSlice[T](a.a, len, cap)
+}
+
+// Append appends data to the slice, growing it from the arena if needed (see nuke.SliceAppend).
+func Append[T any](a Arena, s []T, data ...T) []T {
+	return nuke.SliceAppend(a.a, s, data...)
+}
+
+// MakeString returns a copy of b allocated from the arena as a string (see nuke.MakeString).
+func MakeString(a Arena, b []byte) string {
+	return nuke.MakeString(a.a, b)
+}
+
+// Alloc allocates a zero value of type T from the arena, returning a handle to it (see nuke.Alloc).
+func Alloc[T any](a Arena) nuke.Ref[T] {
+	return nuke.Alloc[T](a.a)
+}
+
+// NewBuffer returns a buffer of the given initial size allocated from the arena (see nuke.NewBuffer).
+func NewBuffer(a Arena, size int) *nuke.Buffer {
+	return nuke.NewBuffer(a.a, size)
+}
+
+// InjectContextArena returns a new context with the arena injected into it.
+func InjectContextArena(ctx context.Context, a Arena) context.Context {
+	return nuke.InjectContextArena(ctx, a.a)
+}
+
+// ExtractContextArena returns the arena injected into the context, or a nil arena if none.
+func ExtractContextArena(ctx context.Context) Arena {
+	return Arena{a: nuke.ExtractContextArena(ctx)}
+}
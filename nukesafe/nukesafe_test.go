@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukesafe
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke/nuketest"
+)
+
+func TestArena(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	a := NewConcurrentArena(NewMonotonicArena(1024, 1))
+
+	x := New[int](a)
+	*x = 42
+	s := MakeSlice[byte](a, 0, 4)
+	s = Append(a, s, 'f', 'o', 'o', 'b', 'a', 'r')
+	require.Equal(t, "foobar", MakeString(a, s))
+
+	ref := Alloc[int](a)
+	ref.Store(7)
+	require.Equal(t, 7, ref.Load())
+
+	b := NewBuffer(a, 16)
+	b.WriteString("hello")
+	require.Equal(t, "hello", b.String())
+
+	require.Equal(t, uint64(6), a.Metrics().Allocs)
+	a.Reset(false)
+	require.Equal(t, uint64(1), a.Metrics().Resets)
+}
+
+func TestNilArena(t *testing.T) {
+	var a Arena
+	require.NotNil(t, New[int](a))
+	require.Len(t, MakeSlice[int](a, 3, 3), 3)
+	require.Zero(t, a.Metrics())
+	a.Reset(true)
+	require.Equal(t, a, NewConcurrentArena(a))
+}
+
+func TestContextArena(t *testing.T) {
+	a := NewGCArena(1024)
+	ctx := InjectContextArena(context.Background(), a)
+	require.Equal(t, a, ExtractContextArena(ctx))
+	require.Equal(t, Arena{}, ExtractContextArena(context.Background()))
+	require.Equal(t, a, Wrap(a.Unwrap()))
+}
+
+func TestNoUnsafe(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+
+	for _, file := range files {
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly)
+		require.NoError(t, err)
+		for _, imp := range f.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			require.NotEqual(t, "unsafe", path, file)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukeslog provides a log/slog handler formatting every record in memory allocated
+// from an arena taken from a pool, which is reset once the record has been written.
+package nukeslog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ortuman/nuke"
+	"github.com/ortuman/nuke/nukejson"
+)
+
+// Handler is a slog.Handler writing records as line-delimited JSON objects, in the same format
+// as slog.JSONHandler does. Every record is formatted into a buffer allocated from an arena
+// taken from the pool, along with the JSON encoding of its values of arbitrary types, and the
+// arena is returned to the pool right after the record is written.
+//
+// Like with slog.JSONHandler, each call to Handle results in a single serialized call to
+// io.Writer.Write, and values failing to be encoded are formatted as error strings.
+type Handler struct {
+	pool         *nuke.ArenaPool
+	opts         slog.HandlerOptions
+	preformatted []byte   // attributes from WithAttrs, formatted from the heap
+	groups       []string // groups from WithGroup
+	nOpenGroups  int      // groups opened in preformatted
+	mu           *sync.Mutex
+	w            io.Writer
+}
+
+// NewJSONHandler returns a Handler writing to w, which formats records with arenas from the pool,
+// using the given options. A nil opts is equivalent to the zero slog.HandlerOptions.
+func NewJSONHandler(pool *nuke.ArenaPool, w io.Writer, opts *slog.HandlerOptions) *Handler {
+	if opts == nil {
+		opts = &slog.HandlerOptions{}
+	}
+	return &Handler{pool: pool, opts: *opts, mu: &sync.Mutex{}, w: w}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// WithAttrs returns a new Handler whose output includes the given attributes,
+// which are formatted once from the heap.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	// Empty groups are ignored, so there's nothing to do if there's nothing else.
+	if countEmptyGroups(attrs) == len(attrs) {
+		return h
+	}
+	h2 := h.clone()
+	buf := nuke.NewBuffer(nil, len(h2.preformatted))
+	_, _ = buf.Write(h2.preformatted)
+
+	s := h2.newState(nil, buf)
+	if len(h2.preformatted) > 0 && h2.preformatted[len(h2.preformatted)-1] != '{' {
+		s.sep = ","
+	}
+	pos := buf.Len()
+	s.openGroups()
+	if !s.appendAttrs(attrs) {
+		buf.Truncate(pos)
+	} else {
+		h2.nOpenGroups = len(h2.groups)
+	}
+	h2.preformatted = buf.Bytes()
+	return h2
+}
+
+// WithGroup returns a new Handler nesting the attributes that follow in the given group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := h.clone()
+	h2.groups = append(h2.groups, name)
+	return h2
+}
+
+// Handle formats the record as a JSON object on a line of its own and writes it.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	a := h.pool.Get()
+	defer h.pool.Put(a)
+
+	buf := nuke.NewBuffer(a, 1024)
+	s := h.newState(a, buf)
+	_ = buf.WriteByte('{')
+
+	// Built-in attributes, which aren't in any group.
+	groups := s.groups
+	s.groups = nil
+	rep := h.opts.ReplaceAttr
+	if !r.Time.IsZero() {
+		t := r.Time.Round(0) // strip the monotonic reading
+		if rep == nil {
+			s.appendKey(slog.TimeKey)
+			s.appendTime(t)
+		} else {
+			s.appendAttr(slog.Time(slog.TimeKey, t))
+		}
+	}
+	if rep == nil {
+		s.appendKey(slog.LevelKey)
+		s.appendString(r.Level.String())
+	} else {
+		s.appendAttr(slog.Any(slog.LevelKey, r.Level))
+	}
+	if h.opts.AddSource {
+		s.appendAttr(slog.Any(slog.SourceKey, source(r.PC)))
+	}
+	if rep == nil {
+		s.appendKey(slog.MessageKey)
+		s.appendString(r.Message)
+	} else {
+		s.appendAttr(slog.String(slog.MessageKey, r.Message))
+	}
+	s.groups = groups
+
+	if len(h.preformatted) > 0 {
+		_, _ = buf.WriteString(s.sep)
+		_, _ = buf.Write(h.preformatted)
+		s.sep = ","
+		if h.preformatted[len(h.preformatted)-1] == '{' {
+			s.sep = ""
+		}
+	}
+	// Groups from WithGroup are only output if the record has attributes.
+	nOpenGroups := h.nOpenGroups
+	if r.NumAttrs() > 0 {
+		pos := buf.Len()
+		s.openGroups()
+		nOpenGroups = len(h.groups)
+		empty := true
+		r.Attrs(func(attr slog.Attr) bool {
+			if s.appendAttr(attr) {
+				empty = false
+			}
+			return true
+		})
+		if empty {
+			buf.Truncate(pos)
+			nOpenGroups = h.nOpenGroups
+		}
+	}
+	for range h.groups[:nOpenGroups] {
+		_ = buf.WriteByte('}')
+	}
+	_, _ = buf.WriteString("}\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *Handler) clone() *Handler {
+	return &Handler{
+		pool:         h.pool,
+		opts:         h.opts,
+		preformatted: slices.Clip(h.preformatted),
+		groups:       slices.Clip(h.groups),
+		nOpenGroups:  h.nOpenGroups,
+		mu:           h.mu, // shared among all clones
+		w:            h.w,
+	}
+}
+
+func (h *Handler) newState(a nuke.Arena, buf *nuke.Buffer) *state {
+	s := &state{h: h, a: a, buf: buf}
+	if h.opts.ReplaceAttr != nil {
+		s.groups = append([]string(nil), h.groups[:h.nOpenGroups]...)
+	}
+	return s
+}
+
+// state holds the state of the formatting of a record, or of attributes in WithAttrs.
+type state struct {
+	h      *Handler
+	a      nuke.Arena
+	buf    *nuke.Buffer
+	sep    string
+	groups []string // open groups, only kept for ReplaceAttr
+	enc    *nukejson.Encoder
+}
+
+func (s *state) openGroups() {
+	for _, name := range s.h.groups[s.h.nOpenGroups:] {
+		s.openGroup(name)
+	}
+}
+
+func (s *state) openGroup(name string) {
+	s.appendKey(name)
+	_ = s.buf.WriteByte('{')
+	s.sep = ""
+	if s.h.opts.ReplaceAttr != nil {
+		s.groups = append(s.groups, name)
+	}
+}
+
+func (s *state) closeGroup() {
+	_ = s.buf.WriteByte('}')
+	s.sep = ","
+	if s.h.opts.ReplaceAttr != nil {
+		s.groups = s.groups[:len(s.groups)-1]
+	}
+}
+
+// appendAttrs appends the attributes, reporting whether anything was appended.
+func (s *state) appendAttrs(attrs []slog.Attr) bool {
+	nonEmpty := false
+	for _, attr := range attrs {
+		if s.appendAttr(attr) {
+			nonEmpty = true
+		}
+	}
+	return nonEmpty
+}
+
+// appendAttr appends the attribute, after replacing it, reporting whether anything was appended.
+func (s *state) appendAttr(attr slog.Attr) bool {
+	attr.Value = attr.Value.Resolve()
+	if rep := s.h.opts.ReplaceAttr; rep != nil && attr.Value.Kind() != slog.KindGroup {
+		attr = rep(s.groups, attr)
+		attr.Value = attr.Value.Resolve()
+	}
+	if attr.Equal(slog.Attr{}) {
+		return false
+	}
+	if attr.Value.Kind() == slog.KindAny {
+		if src, ok := attr.Value.Any().(*slog.Source); ok {
+			if *src == (slog.Source{}) {
+				return false
+			}
+			attr.Value = sourceGroup(src)
+		}
+	}
+	if attr.Value.Kind() != slog.KindGroup {
+		s.appendKey(attr.Key)
+		s.appendValue(attr.Value)
+		return true
+	}
+	attrs := attr.Value.Group()
+	if len(attrs) == 0 {
+		return false
+	}
+	// The group may still turn out empty, if ReplaceAttr drops all its attributes.
+	pos := s.buf.Len()
+	sep := s.sep
+	if attr.Key != "" {
+		s.openGroup(attr.Key)
+	}
+	if !s.appendAttrs(attrs) {
+		s.buf.Truncate(pos)
+		s.sep = sep
+		if attr.Key != "" && s.h.opts.ReplaceAttr != nil {
+			s.groups = s.groups[:len(s.groups)-1]
+		}
+		return false
+	}
+	if attr.Key != "" {
+		s.closeGroup()
+	}
+	return true
+}
+
+func (s *state) appendKey(key string) {
+	_, _ = s.buf.WriteString(s.sep)
+	s.appendString(key)
+	_ = s.buf.WriteByte(':')
+	s.sep = ","
+}
+
+func (s *state) appendValue(v slog.Value) {
+	var tmp [64]byte
+	switch v.Kind() {
+	case slog.KindString:
+		s.appendString(v.String())
+	case slog.KindInt64:
+		_, _ = s.buf.Write(strconv.AppendInt(tmp[:0], v.Int64(), 10))
+	case slog.KindUint64:
+		_, _ = s.buf.Write(strconv.AppendUint(tmp[:0], v.Uint64(), 10))
+	case slog.KindFloat64:
+		// Floats are formatted like json.Marshal does, which differs from strconv.
+		s.appendJSON(v.Float64())
+	case slog.KindBool:
+		_, _ = s.buf.Write(strconv.AppendBool(tmp[:0], v.Bool()))
+	case slog.KindDuration:
+		_, _ = s.buf.Write(strconv.AppendInt(tmp[:0], int64(v.Duration()), 10))
+	case slog.KindTime:
+		s.appendTime(v.Time())
+	default:
+		x := v.Any()
+		if err, ok := x.(error); ok {
+			if _, ok := x.(json.Marshaler); !ok {
+				s.appendString(err.Error())
+				return
+			}
+		}
+		s.appendJSON(x)
+	}
+}
+
+func (s *state) appendTime(t time.Time) {
+	if y := t.Year(); y < 0 || y >= 10000 {
+		// RFC 3339 is clear that years are 4 digits exactly.
+		s.appendError(errors.New("time.Time year outside of range [0,9999]"))
+		return
+	}
+	var tmp [64]byte
+	_ = s.buf.WriteByte('"')
+	_, _ = s.buf.Write(t.AppendFormat(tmp[:0], time.RFC3339Nano))
+	_ = s.buf.WriteByte('"')
+}
+
+// appendJSON appends the JSON encoding of v, encoded into arena memory.
+func (s *state) appendJSON(v any) {
+	if s.enc == nil {
+		s.enc = nukejson.NewEncoder(s.a)
+		s.enc.SetEscapeHTML(false)
+	}
+	defer s.enc.Reset()
+	if err := s.enc.Encode(v); err != nil {
+		s.appendError(err)
+		return
+	}
+	b := s.enc.Bytes()
+	_, _ = s.buf.Write(b[:len(b)-1]) // drop the trailing newline
+}
+
+func (s *state) appendError(err error) {
+	s.appendString("!ERROR:" + err.Error())
+}
+
+// appendString appends s as a quoted JSON string, escaped like slog.JSONHandler does.
+func (s *state) appendString(str string) {
+	const hex = "0123456789abcdef"
+
+	buf := s.buf
+	_ = buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(str); {
+		if b := str[i]; b < utf8.RuneSelf {
+			if b >= ' ' && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			_, _ = buf.WriteString(str[start:i])
+			_ = buf.WriteByte('\\')
+			switch b {
+			case '\\', '"':
+				_ = buf.WriteByte(b)
+			case '\n':
+				_ = buf.WriteByte('n')
+			case '\r':
+				_ = buf.WriteByte('r')
+			case '\t':
+				_ = buf.WriteByte('t')
+			default:
+				_, _ = buf.Write([]byte{'u', '0', '0', hex[b>>4], hex[b&0xf]})
+			}
+			i++
+			start = i
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(str[i:])
+		if c == utf8.RuneError && size == 1 {
+			_, _ = buf.WriteString(str[start:i])
+			_, _ = buf.WriteString("\ufffd")
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid in JSON strings, but not in JavaScript.
+		if c == '\u2028' || c == '\u2029' {
+			_, _ = buf.WriteString(str[start:i])
+			_, _ = buf.Write([]byte{'\\', 'u', '2', '0', '2', hex[c&0xf]})
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	_, _ = buf.WriteString(str[start:])
+	_ = buf.WriteByte('"')
+}
+
+// source returns the source location of the program counter.
+func source(pc uintptr) *slog.Source {
+	fs := runtime.CallersFrames([]uintptr{pc})
+	f, _ := fs.Next()
+	return &slog.Source{Function: f.Function, File: f.File, Line: f.Line}
+}
+
+// sourceGroup returns the group value slog.JSONHandler formats sources as.
+func sourceGroup(src *slog.Source) slog.Value {
+	var attrs []slog.Attr
+	if src.Function != "" {
+		attrs = append(attrs, slog.String("function", src.Function))
+	}
+	if src.File != "" {
+		attrs = append(attrs, slog.String("file", src.File))
+	}
+	if src.Line != 0 {
+		attrs = append(attrs, slog.Int("line", src.Line))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func countEmptyGroups(attrs []slog.Attr) int {
+	n := 0
+	for _, attr := range attrs {
+		if attr.Value.Kind() == slog.KindGroup && len(attr.Value.Group()) == 0 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukeslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+)
+
+type marshalErr struct{}
+
+func (marshalErr) Error() string { return "marshal error" }
+
+func (marshalErr) MarshalJSON() ([]byte, error) { return []byte(`{"e":1}`), nil }
+
+type valuer struct{}
+
+func (valuer) LogValue() slog.Value { return slog.GroupValue(slog.Int("resolved", 1)) }
+
+func newPool() *nuke.ArenaPool {
+	return nuke.NewArenaPool(func() nuke.Arena {
+		return nuke.NewGCArena(4096)
+	})
+}
+
+func TestHandlerMatchesJSONHandler(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	when := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	attrs := []slog.Attr{
+		slog.String("s", "quote\" slash\\ <html> \n\t\x01 \xff   café"),
+		slog.Int("i", -1),
+		slog.Uint64("u", 2),
+		slog.Float64("f", 1e21),
+		slog.Bool("b", true),
+		slog.Duration("d", time.Second),
+		slog.Time("t", when),
+		slog.Any("err", errors.New("boom")),
+		slog.Any("merr", marshalErr{}),
+		slog.Any("map", map[string]any{"k": []int{1, 2}}),
+		slog.Any("bad", func() {}),
+		slog.Any("valuer", valuer{}),
+		slog.Group("g", slog.Int("a", 1), slog.Group("empty"), slog.Group("", slog.Int("inlined", 2))),
+		slog.Group("dropped", slog.String("secret", "x")),
+	}
+	for _, opts := range []*slog.HandlerOptions{
+		nil,
+		{AddSource: true, Level: slog.LevelDebug},
+		{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "secret" || a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			if len(groups) > 0 {
+				a.Key = groups[len(groups)-1] + "-" + a.Key
+			}
+			return a
+		}},
+	} {
+		for _, with := range []func(slog.Handler) slog.Handler{
+			func(h slog.Handler) slog.Handler { return h },
+			func(h slog.Handler) slog.Handler { return h.WithAttrs([]slog.Attr{slog.Int("pre", 1)}) },
+			func(h slog.Handler) slog.Handler { return h.WithGroup("wg") },
+			func(h slog.Handler) slog.Handler {
+				return h.WithGroup("wg").WithAttrs([]slog.Attr{slog.Int("pre", 1)}).WithGroup("wg2")
+			},
+			func(h slog.Handler) slog.Handler { return h.WithAttrs([]slog.Attr{slog.Group("empty")}) },
+		} {
+			var want, got bytes.Buffer
+			for _, n := range []int{0, len(attrs)} {
+				r := slog.NewRecord(when, slog.LevelWarn, "hello <world>", pcs[0])
+				r.AddAttrs(attrs[:n]...)
+
+				require.NoError(t, with(slog.NewJSONHandler(&want, opts)).Handle(context.Background(), r))
+				require.NoError(t, with(NewJSONHandler(newPool(), &got, opts)).Handle(context.Background(), r))
+			}
+			require.Equal(t, want.String(), got.String())
+		}
+	}
+}
+
+func TestHandlerEnabled(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(NewJSONHandler(newPool(), &out, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	logger.Info("skipped")
+	require.Zero(t, out.Len())
+
+	logger.Error("logged", "k", "v")
+	require.Contains(t, out.String(), `"msg":"logged","k":"v"}`)
+}
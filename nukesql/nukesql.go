@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukesql provides database/sql helpers copying column values into arena memory.
+package nukesql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"unsafe"
+
+	"github.com/ortuman/nuke"
+)
+
+// Rows is the subset of *sql.Rows and *sql.Row used to scan column values.
+type Rows interface {
+	Scan(dest ...any) error
+}
+
+// String returns a sql.Scanner storing a column value into dst as a string allocated from the arena.
+// Drivers usually return text columns as byte slices, which database/sql would otherwise convert
+// into strings allocated from the heap. Like with database/sql, scanning NULL into dst fails.
+func String(a nuke.Arena, dst *string) sql.Scanner {
+	return &stringScanner{a: a, dst: dst}
+}
+
+// Bytes returns a sql.Scanner storing a column value into dst as a byte slice allocated from the arena.
+// NULL is stored as a nil slice.
+func Bytes(a nuke.Arena, dst *[]byte) sql.Scanner {
+	return &bytesScanner{a: a, dst: dst}
+}
+
+// NullString returns a sql.Scanner storing a column value into dst, whose string is allocated from the arena.
+func NullString(a nuke.Arena, dst *sql.NullString) sql.Scanner {
+	return &nullStringScanner{a: a, dst: dst}
+}
+
+// Scanner scans rows into a fixed set of destinations. Destinations of type *string, *[]byte and
+// *sql.NullString get their values allocated from the arena, while the rest are passed to the rows as is.
+// Binding destinations once, rather than on every call to Scan, allows scanning any number of rows
+// without allocating anything from the heap beyond what drivers do.
+type Scanner struct {
+	dest []any
+}
+
+// NewScanner returns a Scanner scanning rows into dest, allocating from the arena.
+func NewScanner(a nuke.Arena, dest ...any) *Scanner {
+	s := &Scanner{dest: make([]any, len(dest))}
+	for i, d := range dest {
+		switch d := d.(type) {
+		case *string:
+			s.dest[i] = String(a, d)
+		case *[]byte:
+			s.dest[i] = Bytes(a, d)
+		case *sql.NullString:
+			s.dest[i] = NullString(a, d)
+		default:
+			s.dest[i] = d
+		}
+	}
+	return s
+}
+
+// Scan copies the columns of the current row into the bound destinations, like sql.Rows.Scan does.
+// Values allocated from the arena remain valid until the arena is reset.
+func (s *Scanner) Scan(rows Rows) error {
+	return rows.Scan(s.dest...)
+}
+
+// Scan copies the columns of the current row into dest, like sql.Rows.Scan does, allocating the values
+// of *string, *[]byte and *sql.NullString destinations from the arena. Scanners created by NewScanner
+// should be preferred when scanning many rows into the same destinations.
+func Scan(a nuke.Arena, rows Rows, dest ...any) error {
+	return NewScanner(a, dest...).Scan(rows)
+}
+
+type stringScanner struct {
+	a   nuke.Arena
+	dst *string
+}
+
+func (s *stringScanner) Scan(src any) error {
+	if src == nil {
+		return errors.New("nukesql: converting NULL to string is unsupported")
+	}
+	b, err := valueBytes(s.a, src)
+	if err != nil {
+		return err
+	}
+	*s.dst = unsafe.String(unsafe.SliceData(b), len(b))
+	return nil
+}
+
+type bytesScanner struct {
+	a   nuke.Arena
+	dst *[]byte
+}
+
+func (s *bytesScanner) Scan(src any) error {
+	if src == nil {
+		*s.dst = nil
+		return nil
+	}
+	b, err := valueBytes(s.a, src)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		b = []byte{} // distinguish empty values from NULL
+	}
+	*s.dst = b
+	return nil
+}
+
+type nullStringScanner struct {
+	a   nuke.Arena
+	dst *sql.NullString
+}
+
+func (s *nullStringScanner) Scan(src any) error {
+	if src == nil {
+		*s.dst = sql.NullString{}
+		return nil
+	}
+	b, err := valueBytes(s.a, src)
+	if err != nil {
+		return err
+	}
+	*s.dst = sql.NullString{String: unsafe.String(unsafe.SliceData(b), len(b)), Valid: true}
+	return nil
+}
+
+// valueBytes returns a copy of the textual representation of a driver value allocated from the arena,
+// formatting non-textual values like database/sql does when scanning them into strings.
+func valueBytes(a nuke.Arena, src any) ([]byte, error) {
+	var b []byte
+	switch v := src.(type) {
+	case []byte:
+		if len(v) == 0 {
+			return b, nil
+		}
+		return nuke.SliceAppend(a, b, v...), nil
+	case string:
+		if len(v) == 0 {
+			return b, nil
+		}
+		return nuke.SliceAppend(a, b, unsafe.Slice(unsafe.StringData(v), len(v))...), nil
+	case int64:
+		var buf [20]byte
+		return nuke.SliceAppend(a, b, strconv.AppendInt(buf[:0], v, 10)...), nil
+	case float64:
+		var buf [32]byte
+		return nuke.SliceAppend(a, b, strconv.AppendFloat(buf[:0], v, 'g', -1, 64)...), nil
+	case bool:
+		var buf [5]byte
+		return nuke.SliceAppend(a, b, strconv.AppendBool(buf[:0], v)...), nil
+	case time.Time:
+		var buf [64]byte
+		return nuke.SliceAppend(a, b, v.AppendFormat(buf[:0], time.RFC3339Nano)...), nil
+	default:
+		return nil, fmt.Errorf("nukesql: unsupported driver value of type %T", src)
+	}
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukesql
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke/nuketest"
+)
+
+// fakeRows scans a fixed row of driver values, the way database/sql passes them to scanners.
+type fakeRows []any
+
+func (r fakeRows) Scan(dest ...any) error {
+	if len(dest) != len(r) {
+		return errors.New("wrong number of destinations")
+	}
+	for i, d := range dest {
+		switch d := d.(type) {
+		case sql.Scanner:
+			if err := d.Scan(r[i]); err != nil {
+				return err
+			}
+		case *int64:
+			*d = r[i].(int64)
+		default:
+			return errors.New("unsupported destination")
+		}
+	}
+	return nil
+}
+
+func TestScan(t *testing.T) {
+	nuketest.SkipIfArenasDisabled(t)
+	arena := &nuketest.MockArena{}
+
+	driverBytes := []byte("alice")
+	row := fakeRows{driverBytes, int64(42), []byte{1, 2}, nil, "x"}
+
+	var (
+		name  string
+		id    int64
+		blob  []byte
+		email sql.NullString
+		tag   sql.NullString
+	)
+	require.NoError(t, Scan(arena, row, &name, &id, &blob, &email, &tag))
+
+	// Values don't share memory with the driver.
+	driverBytes[0] = 'A'
+	require.Equal(t, "alice", name)
+	require.Equal(t, int64(42), id)
+	require.Equal(t, []byte{1, 2}, blob)
+	require.Equal(t, sql.NullString{}, email)
+	require.Equal(t, sql.NullString{String: "x", Valid: true}, tag)
+	require.Len(t, arena.Requests(), 3)
+}
+
+func TestScanner(t *testing.T) {
+	arena := &nuketest.MockArena{}
+
+	var s string
+	sc := NewScanner(arena, &s)
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tc := range []struct {
+		src  any
+		want string
+	}{
+		{int64(-7), "-7"},
+		{1.5, "1.5"},
+		{true, "true"},
+		{ts, "2024-01-02T03:04:05Z"},
+		{[]byte{}, ""},
+	} {
+		require.NoError(t, sc.Scan(fakeRows{tc.src}))
+		require.Equal(t, tc.want, s)
+	}
+	require.Error(t, sc.Scan(fakeRows{nil}))
+	require.Error(t, sc.Scan(fakeRows{struct{}{}}))
+}
+
+func TestBytesScanner(t *testing.T) {
+	var b []byte
+	require.NoError(t, Bytes(nil, &b).Scan([]byte{}))
+	require.NotNil(t, b)
+	require.Empty(t, b)
+
+	require.NoError(t, Bytes(nil, &b).Scan(nil))
+	require.Nil(t, b)
+}
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nuketemplate provides text/template and html/template integration,
+// executing templates into arena memory.
+package nuketemplate
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/ortuman/nuke"
+)
+
+// Template is implemented by both *text/template.Template and *html/template.Template.
+type Template interface {
+	Execute(w io.Writer, data any) error
+	ExecuteTemplate(w io.Writer, name string, data any) error
+}
+
+// Execute applies the template to data, returning the output in a buffer allocated from the arena,
+// which is only valid until the arena is reset. If an error occurs, the partial output is returned.
+func Execute(a nuke.Arena, t Template, data any) ([]byte, error) {
+	buf := nuke.NewBuffer(a, 0)
+	err := t.Execute(buf, data)
+	return buf.Bytes(), err
+}
+
+// ExecuteTemplate is like Execute, but applies the associated template with the given name.
+func ExecuteTemplate(a nuke.Arena, t Template, name string, data any) ([]byte, error) {
+	buf := nuke.NewBuffer(a, 0)
+	err := t.ExecuteTemplate(buf, name, data)
+	return buf.Bytes(), err
+}
+
+// Funcs returns template functions whose results are allocated from the arena, overriding the
+// print, printf and println builtins and adding join, which concatenates the elements of a slice
+// of strings placing a separator between them. The map can be passed to the Funcs method of both
+// text and html templates.
+//
+// Since the functions are bound to the arena, and templates can't be modified once executed,
+// they're meant to be installed on a per-request clone of the template. Functions must have been
+// defined when the template was parsed, so the template must be parsed with Funcs(nil) first:
+//
+//	t := template.Must(template.New("page").Funcs(nuketemplate.Funcs(nil)).Parse(text))
+//	// For every request:
+//	rt := template.Must(t.Clone()).Funcs(nuketemplate.Funcs(arena))
+//	out, err := nuketemplate.Execute(arena, rt, data)
+func Funcs(a nuke.Arena) template.FuncMap {
+	return template.FuncMap{
+		"print": func(args ...any) string {
+			buf := nuke.NewBuffer(a, 0)
+			_, _ = fmt.Fprint(buf, args...)
+			return buf.String()
+		},
+		"printf": func(format string, args ...any) string {
+			buf := nuke.NewBuffer(a, 0)
+			_, _ = fmt.Fprintf(buf, format, args...)
+			return buf.String()
+		},
+		"println": func(args ...any) string {
+			buf := nuke.NewBuffer(a, 0)
+			_, _ = fmt.Fprintln(buf, args...)
+			return buf.String()
+		},
+		"join": func(elems []string, sep string) string {
+			if len(elems) == 0 {
+				return ""
+			}
+			n := len(sep) * (len(elems) - 1)
+			for _, e := range elems {
+				n += len(e)
+			}
+			buf := nuke.NewBuffer(a, n)
+			for i, e := range elems {
+				if i > 0 {
+					_, _ = buf.WriteString(sep)
+				}
+				_, _ = buf.WriteString(e)
+			}
+			return buf.String()
+		},
+	}
+}
@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuketemplate
+
+import (
+	htmltemplate "html/template"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+)
+
+func TestExecute(t *testing.T) {
+	arena := nuke.NewMonotonicArena(4096, 1)
+
+	tmpl := template.Must(template.New("page").Funcs(Funcs(nil)).Parse(
+		`{{define "item"}}[{{.}}]{{end}}{{printf "%s=%d" .Name .N}} {{join .Tags ", "}} {{print .N}}`,
+	))
+	rt := template.Must(tmpl.Clone()).Funcs(Funcs(arena))
+
+	data := map[string]any{"Name": "n", "N": 3, "Tags": []string{"a", "b"}}
+	out, err := Execute(arena, rt, data)
+	require.NoError(t, err)
+	require.Equal(t, "n=3 a, b 3", string(out))
+
+	out, err = ExecuteTemplate(arena, rt, "item", 1)
+	require.NoError(t, err)
+	require.Equal(t, "[1]", string(out))
+
+	m, ok := arena.(interface{ Metrics() nuke.Metrics })
+	require.True(t, ok)
+	require.Zero(t, m.Metrics().HeapFallbacks)
+}
+
+func TestExecuteHTML(t *testing.T) {
+	arena := nuke.NewMonotonicArena(4096, 1)
+
+	tmpl := htmltemplate.Must(htmltemplate.New("page").Funcs(Funcs(nil)).Parse(
+		`<p>{{printf "%s!" .}}</p><p>{{println .}}</p>`,
+	))
+	rt := htmltemplate.Must(tmpl.Clone()).Funcs(Funcs(arena))
+
+	out, err := Execute(arena, rt, "<b>hi</b>")
+	require.NoError(t, err)
+	require.Equal(t, "<p>&lt;b&gt;hi&lt;/b&gt;!</p><p>&lt;b&gt;hi&lt;/b&gt;\n</p>", string(out))
+}
+
+func TestExecuteError(t *testing.T) {
+	tmpl := template.Must(template.New("page").Option("missingkey=error").Parse(`a{{.Missing}}`))
+
+	out, err := Execute(nuke.NewMonotonicArena(1024, 1), tmpl, map[string]any{})
+	require.Error(t, err)
+	require.Equal(t, "a", string(out))
+}
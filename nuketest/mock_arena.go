@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nuketest provides utilities for testing code that allocates from arenas.
+package nuketest
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/ortuman/nuke"
+)
+
+// Request is an allocation request received by a MockArena.
+type Request struct {
+	Size      uintptr
+	Alignment uintptr
+
+	// Served reports whether the request was served by the arena,
+	// as opposed to being failed and sent to the heap.
+	Served bool
+}
+
+// MockArena is an Arena test double with programmable behaviors.
+// Memory is allocated from the heap, so values remain valid after Reset.
+// The zero value is an arena serving every allocation. It's safe for concurrent use,
+// but its fields must not be modified while the arena is being used.
+type MockArena struct {
+	// FailAfter makes the arena fail allocations once it has served that many since
+	// it was last reset. Zero means no limit.
+	FailAfter int
+
+	// Capacity makes the arena fail allocations that don't fit in the given number of bytes,
+	// counting those served since it was last reset. Zero means no limit.
+	Capacity uintptr
+
+	// Exhausted makes the arena fail every allocation.
+	Exhausted bool
+
+	// EnforceAlignment makes the arena panic when requested an alignment that isn't a power of two.
+	EnforceAlignment bool
+
+	mtx      sync.Mutex
+	requests []Request
+	resets   int
+	allocs   int
+	used     uintptr
+}
+
+var _ nuke.Arena = (*MockArena)(nil)
+
+// Alloc satisfies the Arena interface.
+func (a *MockArena) Alloc(size, alignment uintptr) unsafe.Pointer {
+	if a.EnforceAlignment && (alignment == 0 || alignment&(alignment-1) != 0) {
+		panic(fmt.Sprintf("nuketest: alignment %d is not a power of two", alignment))
+	}
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	fail := a.Exhausted ||
+		(a.FailAfter > 0 && a.allocs >= a.FailAfter) ||
+		(a.Capacity > 0 && a.used+size > a.Capacity)
+
+	a.requests = append(a.requests, Request{Size: size, Alignment: alignment, Served: !fail})
+	if fail {
+		return nil
+	}
+	a.allocs++
+	a.used += size
+
+	alignment = max(alignment, 1)
+	buf := make([]byte, size+alignment)
+	ptr := unsafe.Pointer(unsafe.SliceData(buf))
+	return unsafe.Add(ptr, (alignment-uintptr(ptr)%alignment)%alignment)
+}
+
+// Reset satisfies the Arena interface.
+func (a *MockArena) Reset(bool) {
+	a.mtx.Lock()
+	a.resets++
+	a.allocs = 0
+	a.used = 0
+	a.mtx.Unlock()
+}
+
+// Requests returns every allocation request received by the arena so far, including failed ones.
+func (a *MockArena) Requests() []Request {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return append([]Request(nil), a.requests...)
+}
+
+// Resets returns the number of times the arena has been reset.
+func (a *MockArena) Resets() int {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return a.resets
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuketest
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+)
+
+func TestMockArenaRecordsRequests(t *testing.T) {
+	SkipIfArenasDisabled(t)
+	a := &MockArena{}
+
+	_ = nuke.New[int64](a)
+	_ = nuke.MakeSlice[int32](a, 0, 4)
+
+	require.Equal(t, []Request{
+		{Size: 8, Alignment: unsafe.Alignof(int64(0)), Served: true}, // 4 on 32-bit platforms
+		{Size: 16, Alignment: 4, Served: true},
+	}, a.Requests())
+}
+
+func TestMockArenaFailAfter(t *testing.T) {
+	a := &MockArena{FailAfter: 2}
+
+	require.NotNil(t, a.Alloc(8, 8))
+	require.NotNil(t, a.Alloc(8, 8))
+	require.Nil(t, a.Alloc(8, 8))
+
+	a.Reset(false)
+	require.NotNil(t, a.Alloc(8, 8))
+	require.Equal(t, 1, a.Resets())
+}
+
+func TestMockArenaCapacity(t *testing.T) {
+	a := &MockArena{Capacity: 16}
+
+	require.NotNil(t, a.Alloc(12, 4))
+	require.Nil(t, a.Alloc(8, 4))
+	require.NotNil(t, a.Alloc(4, 4))
+
+	a = &MockArena{Exhausted: true}
+	require.Nil(t, a.Alloc(1, 1))
+}
+
+func TestMockArenaAlignment(t *testing.T) {
+	a := &MockArena{EnforceAlignment: true}
+
+	ptr := a.Alloc(8, 64)
+	require.Zero(t, uintptr(ptr)%64)
+
+	require.Panics(t, func() { _ = a.Alloc(8, 3) })
+	require.NotPanics(t, func() { _ = (&MockArena{}).Alloc(8, 3) })
+}
@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuketest
+
+import (
+	"testing"
+
+	"github.com/ortuman/nuke"
+)
+
+// SkipIfArenasDisabled skips the test when the nuke package has been built with the nuke_off
+// or the purego build tag, under which every allocation goes to the heap, so that tests
+// asserting that memory comes from an arena can run alongside the rest of the suite.
+func SkipIfArenasDisabled(tb testing.TB) {
+	tb.Helper()
+	if nuke.NewMonotonicArena(1, 1).Alloc(1, 1) == nil {
+		tb.Skip("arenas are disabled")
+	}
+}
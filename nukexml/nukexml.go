@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package nukexml provides XML decoding helpers building their results in arena memory.
+package nukexml
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"unsafe"
+
+	"github.com/ortuman/nuke"
+)
+
+// Element is an XML element, as built by Parse.
+type Element struct {
+	Name xml.Name
+	Attr []xml.Attr
+
+	// Text is the concatenation of all the character data directly contained by the element.
+	Text string
+
+	Children []*Element
+}
+
+// Parse reads an XML document from r and returns its root element, allocating the whole
+// element tree from the arena: elements, their attributes, children and character data.
+// Comments, processing instructions and directives are skipped.
+//
+// Elements hold pointers, so the arena must be created with nuke.NewGCArena for them to be safe,
+// as with any other type holding pointers. Names are those returned by the xml.Decoder, which
+// are allocated from the heap.
+func Parse(a nuke.Arena, r io.Reader) (*Element, error) {
+	return ParseDecoder(a, xml.NewDecoder(r))
+}
+
+// ParseDecoder is like Parse, but reads tokens from the passed decoder, which allows configuring it.
+func ParseDecoder(a nuke.Arena, d *xml.Decoder) (*Element, error) {
+	var root *Element
+	var stack []*Element
+	var text [][]byte // character data of every element in stack
+
+	for {
+		tok, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			if root == nil {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			e := nuke.New[Element](a)
+			e.Name = tok.Name
+			e.Attr = copyAttrs(a, tok.Attr)
+
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = nuke.SliceAppend(a, parent.Children, e)
+			} else if root == nil {
+				root = e
+			}
+			stack = append(stack, e)
+			text = append(text, nil)
+
+		case xml.EndElement:
+			e := stack[len(stack)-1]
+			t := text[len(text)-1]
+			e.Text = unsafe.String(unsafe.SliceData(t), len(t))
+			stack = stack[:len(stack)-1]
+			text = text[:len(text)-1]
+
+		case xml.CharData:
+			if len(text) > 0 {
+				text[len(text)-1] = nuke.SliceAppend(a, text[len(text)-1], tok...)
+			}
+		}
+	}
+}
+
+// CopyToken returns a copy of a token like xml.CopyToken does, allocating the bytes of character
+// data, comments, processing instructions and directives from the arena, as well as the attributes
+// of start elements and their values. The token itself, boxed into an interface, is allocated from the heap.
+func CopyToken(a nuke.Arena, t xml.Token) xml.Token {
+	switch t := t.(type) {
+	case xml.CharData:
+		return xml.CharData(copyBytes(a, t))
+	case xml.Comment:
+		return xml.Comment(copyBytes(a, t))
+	case xml.Directive:
+		return xml.Directive(copyBytes(a, t))
+	case xml.ProcInst:
+		return xml.ProcInst{Target: t.Target, Inst: copyBytes(a, t.Inst)}
+	case xml.StartElement:
+		return xml.StartElement{Name: t.Name, Attr: copyAttrs(a, t.Attr)}
+	default:
+		return t
+	}
+}
+
+func copyAttrs(a nuke.Arena, attrs []xml.Attr) []xml.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := nuke.MakeSlice[xml.Attr](a, len(attrs), len(attrs))
+	for i, attr := range attrs {
+		out[i] = xml.Attr{
+			Name:  attr.Name,
+			Value: nuke.MakeString(a, unsafe.Slice(unsafe.StringData(attr.Value), len(attr.Value))),
+		}
+	}
+	return out
+}
+
+func copyBytes(a nuke.Arena, b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	out := nuke.MakeSlice[byte](a, len(b), len(b))
+	copy(out, b)
+	return out
+}
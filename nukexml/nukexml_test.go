@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nukexml
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ortuman/nuke"
+)
+
+const feed = `<?xml version="1.0"?>
+<!-- feed -->
+<feed lang="en"><title>News &amp; more</title><entry id="1">first<b>bold</b> entry</entry></feed>`
+
+func TestParse(t *testing.T) {
+	root, err := Parse(nuke.NewGCArena(4096), strings.NewReader(feed))
+	require.NoError(t, err)
+
+	require.Equal(t, "feed", root.Name.Local)
+	require.Equal(t, []xml.Attr{{Name: xml.Name{Local: "lang"}, Value: "en"}}, root.Attr)
+	require.Len(t, root.Children, 2)
+
+	title := root.Children[0]
+	require.Equal(t, "title", title.Name.Local)
+	require.Equal(t, "News & more", title.Text)
+	require.Nil(t, title.Attr)
+
+	entry := root.Children[1]
+	require.Equal(t, "first entry", entry.Text)
+	require.Equal(t, "1", entry.Attr[0].Value)
+	require.Equal(t, "bold", entry.Children[0].Text)
+}
+
+func TestParseErrors(t *testing.T) {
+	arena := nuke.NewGCArena(4096)
+
+	_, err := Parse(arena, strings.NewReader(`<a><b></a>`))
+	require.Error(t, err)
+
+	_, err = Parse(arena, strings.NewReader(``))
+	require.Error(t, err)
+}
+
+func TestCopyToken(t *testing.T) {
+	arena := nuke.NewMonotonicArena(1024, 1)
+
+	data := []byte("text")
+	tok := CopyToken(arena, xml.CharData(data))
+	data[0] = 'n'
+	require.Equal(t, xml.CharData("text"), tok)
+
+	start := xml.StartElement{Name: xml.Name{Local: "a"}, Attr: []xml.Attr{{Name: xml.Name{Local: "k"}, Value: "v"}}}
+	require.Equal(t, start, CopyToken(nuke.NewGCArena(1024), start))
+	require.Equal(t, xml.EndElement{Name: xml.Name{Local: "a"}}, CopyToken(arena, xml.EndElement{Name: xml.Name{Local: "a"}}))
+}
@@ -0,0 +1,9 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build nuke_off || purego
+
+package nuke
+
+// arenasDisabled reports whether the package has been built with the nuke_off or the purego
+// build tag, which make every allocation go to the heap and every arena inert.
+const arenasDisabled = true
@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build nuke_off || purego
+
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArenasDisabled(t *testing.T) {
+	arena := NewMonotonicArena(1024, 1)
+
+	_ = New[int](arena)
+	_ = MakeSlice[byte](arena, 0, 16)
+	_ = SliceAppend[byte](arena, nil, 1, 2, 3)
+	_ = NewTypedArena[int](arena).New()
+
+	require.Nil(t, arena.Alloc(8, 8))
+	require.Equal(t, BufferUsage{Size: 1024}, arena.(bufferUsageReporter).BufferUsage()[0])
+	require.Zero(t, arena.(Stater).Metrics())
+}
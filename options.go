@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+// Option configures an arena.
+type Option func(*options)
+
+type options struct {
+	sizeHistogram bool
+	canaries      bool
+	leakHandler   func(Leak)
+
+	largeAllocThreshold int
+	overflowHandler     func(size uintptr)
+	panicOnInvalidAlloc bool
+	escapeSamplingRate  int
+	escapeHandler       func(Escape)
+	strict              bool
+	maxBuffers          int
+	minAlignment        int
+	releasePolicy       ReleasePolicy
+	hooks               []Hooks
+}
+
+// WithSizeHistogram enables tracking a histogram of the sizes of all allocations
+// requested to the arena, including those it couldn't serve.
+func WithSizeHistogram() Option {
+	return func(o *options) { o.sizeHistogram = true }
+}
+
+// WithCanaries places a canary word after every allocation, which is verified on Reset
+// and by CheckCanaries to detect writes past the end of allocations. Since canaries make
+// every allocation bigger and prevent small ones from being packed together, they're only
+// placed when the package is built with the nuke_debug build tag.
+func WithCanaries() Option {
+	return func(o *options) { o.canaries = true }
+}
+
+// WithLargeAllocThreshold makes allocations bigger than size, or than the arena buffer size,
+// get dedicated memory that is dropped on Reset, instead of consuming most of a buffer or
+// falling back to the heap.
+func WithLargeAllocThreshold(size int) Option {
+	return func(o *options) { o.largeAllocThreshold = size }
+}
+
+// WithOverflowHandler makes the arena call fn with the requested size every time an allocation
+// can't be served, right before it falls back to the heap. The handler runs synchronously and,
+// for arenas wrapped by NewConcurrentArena, while holding their lock, so it must not access the arena.
+func WithOverflowHandler(fn func(size uintptr)) Option {
+	return func(o *options) { o.overflowHandler = fn }
+}
+
+// WithPanicOnInvalidAlloc makes the arena panic with ErrInvalidAlloc when requested an allocation
+// whose alignment isn't a power of two or whose size is out of range. By default such allocations
+// fail and fall back to the heap, except in nuke_debug builds, where they always panic.
+func WithPanicOnInvalidAlloc() Option {
+	return func(o *options) { o.panicOnInvalidAlloc = true }
+}
+
+// WithStrictMode makes New, MakeSlice, SliceAppend and TypedArena.New panic with ErrHeapFallback
+// when the arena can't serve an allocation, instead of transparently allocating it from the heap.
+// It's meant for proving that a code path is fully served by the arena, for instance in benchmarks.
+// To be notified of such allocations without panicking use WithOverflowHandler.
+func WithStrictMode() Option {
+	return func(o *options) { o.strict = true }
+}
+
+// WithGrowth makes the arena keep track of the bytes of the allocations it couldn't serve
+// since the last reset, and add as many buffers as needed to cover them on Reset, up to
+// maxBuffers buffers in total, so that its capacity converges to the demand. Allocations
+// bigger than the buffer size never fit, so they're left out. The number of buffers added
+// is reported by the GrownBuffers metric.
+func WithGrowth(maxBuffers int) Option {
+	return func(o *options) { o.maxBuffers = maxBuffers }
+}
+
+// WithMinAlignment makes the arena align every allocation to at least alignment bytes, which must be
+// a power of two, such as 16 for values updated with 128-bit atomics or 64 to keep allocations from
+// sharing cache lines. Allocations requesting a bigger alignment still get it. Since small objects are
+// only packed together when their alignment is below 16 bytes, such alignments also disable packing.
+func WithMinAlignment(alignment int) Option {
+	return func(o *options) { o.minAlignment = alignment }
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"cmp"
+	"hash/maphash"
+	"reflect"
+	"unsafe"
+)
+
+// minMapSlots is the minimum number of slots of the hash table of a map.
+const minMapSlots = 8
+
+// OrderedMap is a hash map whose memory is allocated from an arena and whose iteration order is
+// the order in which keys were first inserted, which suits configuration and JSON objects, whose
+// ordering must be deterministic. Entries are kept in an array in insertion order, indexed by an
+// open-addressing hash table, both allocated from the arena and grown like SliceAppend does.
+// The map is only valid until the arena is reset, and it's not safe to be accessed concurrently.
+// The zero value is an empty map allocating from the heap.
+//
+// Keys and values holding pointers, strings included, must only be stored in maps allocating
+// from arenas created with NewGCArena, as with MakeSlice.
+type OrderedMap[K cmp.Ordered, V any] struct {
+	a        Arena
+	seed     maphash.Seed
+	isString bool
+
+	// entries holds the entries of the map in insertion order, deleted ones included,
+	// and slots the hash table indexing them: 0 for empty slots, -1 for the slots of
+	// deleted entries, and the index of the entry plus one otherwise.
+	entries []orderedEntry[K, V]
+	slots   []int32
+
+	len  int
+	used int // number of non-empty slots
+}
+
+type orderedEntry[K, V any] struct {
+	key     K
+	value   V
+	deleted bool
+}
+
+// NewOrderedMap returns an empty OrderedMap allocating from the arena, with room for capacity entries.
+// If passed arena is nil, the map is allocated from the heap.
+func NewOrderedMap[K cmp.Ordered, V any](a Arena, capacity int) *OrderedMap[K, V] {
+	m := &OrderedMap[K, V]{a: a}
+	m.grow(capacity)
+	return m
+}
+
+// Len returns the number of entries of the map.
+func (m *OrderedMap[K, V]) Len() int {
+	return m.len
+}
+
+// Get returns the value associated with key, and whether there's one.
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if m.len > 0 {
+		if i, ok := m.find(key); ok {
+			return m.entries[m.slots[i]-1].value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Set associates value with key. Keys already in the map keep their position in the iteration order.
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	if (m.used+1)*4 > len(m.slots)*3 {
+		m.grow(m.len + 1)
+	} else if deleted := len(m.entries) - m.len; len(m.entries) == cap(m.entries) && deleted*4 >= len(m.entries) {
+		m.grow(m.len + 1) // drop deleted entries rather than growing the entries array
+	}
+	i, ok := m.find(key)
+	if ok {
+		m.entries[m.slots[i]-1].value = value
+		return
+	}
+	if m.slots[i] == 0 {
+		m.used++
+	}
+	m.entries = SliceAppend(m.a, m.entries, orderedEntry[K, V]{key: key, value: value})
+	m.slots[i] = int32(len(m.entries))
+	m.len++
+}
+
+// Delete removes the entry of key, reporting whether there was one. Keys set again afterwards
+// are moved to the end of the iteration order.
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	if m.len == 0 {
+		return false
+	}
+	i, ok := m.find(key)
+	if !ok {
+		return false
+	}
+	m.entries[m.slots[i]-1] = orderedEntry[K, V]{deleted: true}
+	m.slots[i] = -1
+	m.len--
+	return true
+}
+
+// Range calls fn for every entry of the map in insertion order, until it returns false.
+// The map must not be modified by fn.
+func (m *OrderedMap[K, V]) Range(fn func(key K, value V) bool) {
+	for i := range m.entries {
+		e := &m.entries[i]
+		if !e.deleted && !fn(e.key, e.value) {
+			return
+		}
+	}
+}
+
+// find returns the slot of key, reporting whether it's in the map. Otherwise, the returned
+// slot is the one key would be inserted into.
+func (m *OrderedMap[K, V]) find(key K) (int, bool) {
+	mask := len(m.slots) - 1
+	free := -1
+	for i := int(m.hash(key)) & mask; ; i = (i + 1) & mask {
+		switch s := m.slots[i]; {
+		case s == 0:
+			if free < 0 {
+				free = i
+			}
+			return free, false
+		case s < 0:
+			if free < 0 {
+				free = i
+			}
+		case m.entries[s-1].key == key:
+			return i, true
+		}
+	}
+}
+
+func (m *OrderedMap[K, V]) hash(key K) uint64 {
+	var zero K
+	if key == zero {
+		key = zero // hash negative zeros as positive ones, which they're equal to
+	}
+	if m.isString {
+		return maphash.String(m.seed, *(*string)(unsafe.Pointer(&key)))
+	}
+	return maphash.Bytes(m.seed, unsafe.Slice((*byte)(unsafe.Pointer(&key)), unsafe.Sizeof(key)))
+}
+
+// grow makes room for n entries, dropping deleted entries and rebuilding the hash table.
+func (m *OrderedMap[K, V]) grow(n int) {
+	if m.slots == nil {
+		m.seed = maphash.MakeSeed()
+		m.isString = typeOf[K]().Kind() == reflect.String
+	}
+	n = max(n, 2*m.len)
+	size := mapSlots(n)
+	if m.len < len(m.entries) {
+		live := m.entries[:0]
+		for _, e := range m.entries {
+			if !e.deleted {
+				live = append(live, e)
+			}
+		}
+		clear(m.entries[len(live):])
+		m.entries = live
+	}
+	if cap(m.entries) < n {
+		entries := MakeSlice[orderedEntry[K, V]](m.a, len(m.entries), n)
+		copy(entries, m.entries)
+		m.entries = entries
+	}
+	if len(m.slots) == size {
+		clear(m.slots) // dropping deleted entries doesn't need a bigger table
+	} else {
+		m.slots = MakeSlice[int32](m.a, size, size)
+	}
+	m.used = len(m.entries)
+	mask := size - 1
+	for j, e := range m.entries {
+		i := int(m.hash(e.key)) & mask
+		for m.slots[i] != 0 {
+			i = (i + 1) & mask
+		}
+		m.slots[i] = int32(j + 1)
+	}
+}
+
+// mapSlots returns the number of slots of a hash table holding up to n entries,
+// keeping its load factor below 3/4.
+func mapSlots(n int) int {
+	size := minMapSlots
+	for size*3 < n*4 {
+		size <<= 1
+	}
+	return size
+}
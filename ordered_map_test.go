@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"cmp"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func orderedKeys[K cmp.Ordered, V any](m *OrderedMap[K, V]) []K {
+	var keys []K
+	m.Range(func(k K, _ V) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+func TestOrderedMap(t *testing.T) {
+	arena := NewMonotonicArena(64*1024, 1)
+	m := NewOrderedMap[int, float64](arena, 0)
+
+	for i := 100; i > 0; i-- {
+		m.Set(i, float64(i))
+	}
+	require.Equal(t, 100, m.Len())
+	keys := orderedKeys(m)
+	require.Equal(t, 100, keys[0])
+	require.Equal(t, 1, keys[99])
+
+	// Updated keys keep their position, while keys set again after being deleted move to the end.
+	m.Set(100, 0)
+	require.True(t, m.Delete(99))
+	require.False(t, m.Delete(99))
+	m.Set(99, 1)
+	keys = orderedKeys(m)
+	require.Equal(t, []int{100, 98}, keys[:2])
+	require.Equal(t, 99, keys[99])
+
+	v, ok := m.Get(100)
+	require.True(t, ok)
+	require.Zero(t, v)
+	_, ok = m.Get(1000)
+	require.False(t, ok)
+
+	// Deleting and inserting over and over again doesn't grow the map without bound.
+	for i := 0; i < 10000; i++ {
+		m.Set(-1, 0)
+		m.Delete(-1)
+	}
+	require.Equal(t, 100, m.Len())
+	require.LessOrEqual(t, len(m.slots), 512)
+	require.Zero(t, arenaMetrics(arena).HeapFallbacks)
+}
+
+func TestOrderedMapKeys(t *testing.T) {
+	var floats OrderedMap[float64, int]
+	floats.Set(0, 1)
+	floats.Set(math.Copysign(0, -1), 2)
+	floats.Set(math.NaN(), 3)
+	require.Equal(t, 2, floats.Len())
+	v, _ := floats.Get(0)
+	require.Equal(t, 2, v)
+
+	type field string
+	fields := NewOrderedMap[field, string](NewGCArena(1024), 4)
+	fields.Set("name", "nuke")
+	fields.Set("kind", "arena")
+	fields.Set("name", "nuke!")
+	require.Equal(t, []field{"name", "kind"}, orderedKeys(fields))
+	v2, _ := fields.Get("name")
+	require.Equal(t, "nuke!", v2)
+
+	var stopped []field
+	fields.Range(func(k field, _ string) bool {
+		stopped = append(stopped, k)
+		return false
+	})
+	require.Equal(t, []field{"name"}, stopped)
+}
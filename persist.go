@@ -0,0 +1,295 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"slices"
+	"unsafe"
+)
+
+// ErrSaveNotSupported is returned by Save and Load when the arena can't be saved or loaded.
+var ErrSaveNotSupported = errors.New("nuke: arena doesn't support saving")
+
+// ErrInvalidSave is returned by Load when its input isn't an arena written by Save.
+var ErrInvalidSave = errors.New("nuke: invalid saved arena")
+
+// saveMagic and saveVersion identify the format written by Save.
+const (
+	saveMagic   = "NUKE"
+	saveVersion = 1
+)
+
+// persister is implemented by arenas that can be saved, loaded and addressed by offset.
+type persister interface {
+	save(w io.Writer) error
+	load(r io.Reader) error
+	offsetOf(ptr unsafe.Pointer) (uint64, bool)
+	pointerAt(off, size uint64) unsafe.Pointer
+}
+
+// Save writes the memory handed out by the arena since its last reset to w, so that it can be
+// restored later on with Load, such as to warm-start a process with precomputed in-arena structures
+// instead of rebuilding them. Allocations served from the heap, such as those above the threshold
+// set with WithLargeAllocThreshold, aren't saved.
+//
+// Memory is restored at different addresses, so the saved structures must not hold pointers into
+// the arena. They may hold offsets instead, as returned by OffsetOf and resolved by At, which
+// survive a Save and Load round trip.
+//
+// The format consists of the "NUKE" magic, followed by a version number and the number of buffers
+// as little-endian uint32, and by every buffer in turn, as its size and used bytes as little-endian
+// uint64, followed by the used bytes themselves and their CRC-32C checksum (see Checksums) as a
+// little-endian uint32, which Load verifies before handing the memory over to the arena.
+func Save(w io.Writer, a Arena) error {
+	p, ok := a.(persister)
+	if !ok {
+		return ErrSaveNotSupported
+	}
+	return p.save(w)
+}
+
+// Load resets the arena, releasing its memory, and restores into it the memory written by Save.
+// The arena allocates from the space left in its buffers afterwards, never from the restored bytes.
+// Saved buffers with more room than both their used bytes and the buffer size of the arena are
+// rejected with ErrInvalidSave, as are truncated and corrupted inputs.
+func Load(r io.Reader, a Arena) error {
+	p, ok := a.(persister)
+	if !ok {
+		return ErrSaveNotSupported
+	}
+	return p.load(r)
+}
+
+// OffsetOf returns the offset of ptr within the memory handed out by the arena, reporting false
+// if ptr doesn't point into it. Offsets count the used bytes of every buffer one after another,
+// like the output of Snapshot does, and remain valid until the arena is reset, as well as across
+// a Save and Load round trip.
+//
+// The first allocation served by an arena after a reset is at offset 0, which makes it a convenient
+// place for the root of a structure meant to be saved.
+func OffsetOf(a Arena, ptr unsafe.Pointer) (uint64, bool) {
+	if p, ok := a.(persister); ok {
+		return p.offsetOf(ptr)
+	}
+	return 0, false
+}
+
+// At returns a pointer to the value of type T at the given offset of the memory handed out by
+// the arena (see OffsetOf), or nil if the value doesn't fit the memory at the offset.
+func At[T any](a Arena, off uint64) *T {
+	p, ok := a.(persister)
+	if !ok {
+		return nil
+	}
+	var x T
+	return (*T)(p.pointerAt(off, uint64(unsafe.Sizeof(x))))
+}
+
+func (a *monotonicArena) save(w io.Writer) error {
+	if debugEnabled {
+		a.guard.enter()
+		defer a.guard.exit()
+	}
+	hdr := make([]byte, 0, 12)
+	hdr = append(hdr, saveMagic...)
+	hdr = binary.LittleEndian.AppendUint32(hdr, saveVersion)
+	hdr = binary.LittleEndian.AppendUint32(hdr, uint32(len(a.buffers)))
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+	for _, s := range a.buffers {
+		var b [16]byte
+		binary.LittleEndian.PutUint64(b[:8], uint64(s.size))
+		binary.LittleEndian.PutUint64(b[8:], uint64(s.offset))
+		if _, err := w.Write(b[:]); err != nil {
+			return err
+		}
+		var data []byte
+		if s.offset > 0 {
+			asanUnpoison(s.ptr, s.offset)
+			data = unsafe.Slice((*byte)(s.ptr), s.offset)
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+		binary.LittleEndian.PutUint32(b[:4], crc32.Checksum(data, castagnoli))
+		if _, err := w.Write(b[:4]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *monotonicArena) load(r io.Reader) error {
+	var hdr [12]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return loadError(err)
+	}
+	if string(hdr[:4]) != saveMagic {
+		return fmt.Errorf("%w: bad magic", ErrInvalidSave)
+	}
+	if v := binary.LittleEndian.Uint32(hdr[4:]); v != saveVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSave, v)
+	}
+	n := binary.LittleEndian.Uint32(hdr[8:])
+
+	// Read everything before touching the arena, so that it's left as is on error.
+	var buffers []*monotonicBuffer
+	cursor := 0
+	for i := uint32(0); i < n; i++ {
+		var b [16]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return loadError(err)
+		}
+		size := binary.LittleEndian.Uint64(b[:8])
+		used := binary.LittleEndian.Uint64(b[8:])
+		if used > size || size > uint64(maxAllocSize)-bufferAlignment {
+			return fmt.Errorf("%w: buffer %d uses %d bytes out of %d", ErrInvalidSave, i, used, size)
+		}
+		// Buffers can't have more room than what's restored into them and the arena gives its own
+		// buffers, so that a corrupted size can't make the arena allocate an arbitrary amount.
+		if size > max(used, uint64(a.bufferSize)) {
+			return fmt.Errorf("%w: buffer %d of %d bytes exceeds the buffer size of the arena", ErrInvalidSave, i, size)
+		}
+		data, err := readSaved(r, used)
+		if err != nil {
+			return loadError(err)
+		}
+		if _, err := io.ReadFull(r, b[:4]); err != nil {
+			return loadError(err)
+		}
+		if binary.LittleEndian.Uint32(b[:4]) != crc32.Checksum(data, castagnoli) {
+			return fmt.Errorf("%w: buffer %d: %w", ErrInvalidSave, i, ErrChecksumMismatch)
+		}
+		s := newMonotonicBuffer(int(size))
+		if used > 0 {
+			ptr, _ := s.alloc(uintptr(used), 1)
+			copy(unsafe.Slice((*byte)(ptr), used), data)
+			cursor = int(i)
+		}
+		buffers = append(buffers, s)
+	}
+
+	a.Reset(true)
+	if debugEnabled {
+		a.guard.enter()
+		defer a.guard.exit()
+	}
+	// Keep the buffers of the arena beyond the loaded ones.
+	if len(a.buffers) > len(buffers) {
+		buffers = append(buffers, a.buffers[len(buffers):]...)
+	}
+	a.buffers = buffers
+	a.cursor = cursor
+	for _, s := range a.buffers[:cursor] {
+		s.strand()
+	}
+	return nil
+}
+
+// readSaved reads n bytes from r, growing the returned slice as they're read rather than upfront,
+// so that a corrupted length can't make it allocate more than what r supplies.
+func readSaved(r io.Reader, n uint64) ([]byte, error) {
+	const chunkSize = 64 << 10
+	var data []byte
+	for uint64(len(data)) < n {
+		k := int(min(n-uint64(len(data)), chunkSize))
+		data = slices.Grow(data, k)
+		if _, err := io.ReadFull(r, data[len(data):len(data)+k]); err != nil {
+			return nil, err
+		}
+		data = data[:len(data)+k]
+	}
+	return data, nil
+}
+
+func (a *monotonicArena) offsetOf(ptr unsafe.Pointer) (uint64, bool) {
+	var off uint64
+	for _, s := range a.buffers {
+		if s.offset > 0 && uintptr(ptr) >= uintptr(s.ptr) && uintptr(ptr)-uintptr(s.ptr) < s.offset {
+			return off + uint64(uintptr(ptr)-uintptr(s.ptr)), true
+		}
+		off += uint64(s.offset)
+	}
+	return 0, false
+}
+
+func (a *monotonicArena) pointerAt(off, size uint64) unsafe.Pointer {
+	for _, s := range a.buffers {
+		if off < uint64(s.offset) {
+			if size > uint64(s.offset)-off {
+				return nil // values never straddle buffers
+			}
+			return unsafe.Add(s.ptr, off)
+		}
+		off -= uint64(s.offset)
+	}
+	return nil
+}
+
+func (a *concurrentArena) save(w io.Writer) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return Save(w, a.a)
+}
+
+func (a *concurrentArena) load(r io.Reader) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return Load(r, a.a)
+}
+
+func (a *concurrentArena) offsetOf(ptr unsafe.Pointer) (uint64, bool) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return OffsetOf(a.a, ptr)
+}
+
+func (a *concurrentArena) pointerAt(off, size uint64) unsafe.Pointer {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if p, ok := a.a.(persister); ok {
+		return p.pointerAt(off, size)
+	}
+	return nil
+}
+
+func (a *ScavengingArena) save(w io.Writer) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return Save(w, a.a)
+}
+
+func (a *ScavengingArena) load(r io.Reader) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.active = true
+	return Load(r, a.a)
+}
+
+func (a *ScavengingArena) offsetOf(ptr unsafe.Pointer) (uint64, bool) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return OffsetOf(a.a, ptr)
+}
+
+func (a *ScavengingArena) pointerAt(off, size uint64) unsafe.Pointer {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if p, ok := a.a.(persister); ok {
+		return p.pointerAt(off, size)
+	}
+	return nil
+}
+
+func loadError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: truncated input", ErrInvalidSave)
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoad(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	type table struct {
+		n     uint64
+		items uint64 // offset of the items
+	}
+	a := NewMonotonicArena(128, 4)
+
+	root := New[table](a)
+	items := MakeSlice[uint64](a, 12, 12)
+	for i := range items {
+		items[i] = uint64(i * i)
+	}
+	off, ok := OffsetOf(a, unsafe.Pointer(unsafe.SliceData(items)))
+	require.True(t, ok)
+	require.Equal(t, uint64(16), off) // the root table is at offset 0, and the items follow it
+	root.n, root.items = uint64(len(items)), off
+	*New[[4]uint64](a) = [4]uint64{1, 2, 3, 4} // doesn't fit the first buffer
+
+	var buf bytes.Buffer
+	require.NoError(t, Save(&buf, a))
+
+	b := NewConcurrentArena(NewMonotonicArena(1024, 1))
+	New[uint64](b)
+	require.NoError(t, Load(&buf, b))
+
+	root2 := At[table](b, 0)
+	require.NotNil(t, root2)
+	require.NotSame(t, root, root2)
+	require.Equal(t, *root, *root2)
+	items2 := unsafe.Slice(At[uint64](b, root2.items), root2.n)
+	require.Equal(t, items, items2)
+
+	// New allocations don't overwrite the loaded memory.
+	x := New[uint64](b)
+	*x = 42
+	require.Equal(t, items, items2)
+	xoff, ok := OffsetOf(b, unsafe.Pointer(x))
+	require.True(t, ok)
+	require.Equal(t, uint64(112+32), xoff)
+	require.Equal(t, [4]uint64{1, 2, 3, 4}, *At[[4]uint64](b, 112))
+
+	// Values never straddle buffers.
+	require.Nil(t, At[[2]uint64](b, 104))
+	require.Nil(t, At[uint64](b, 1000))
+	_, ok = OffsetOf(b, unsafe.Pointer(new(int)))
+	require.False(t, ok)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	a := NewMonotonicArena(256, 1)
+	*New[uint64](a) = 42
+
+	var buf bytes.Buffer
+	require.NoError(t, Save(&buf, a))
+	saved := buf.Bytes()
+
+	b := NewMonotonicArena(256, 1)
+	x := New[uint64](b)
+	*x = 7
+	require.ErrorIs(t, Load(bytes.NewReader(saved[:len(saved)-1]), b), ErrInvalidSave)
+	require.ErrorIs(t, Load(bytes.NewReader([]byte("JUNK0000")), b), ErrInvalidSave)
+
+	corrupted := append([]byte(nil), saved...)
+	corrupted[12+16] ^= 1 // first byte of the first buffer
+	err := Load(bytes.NewReader(corrupted), b)
+	require.ErrorIs(t, err, ErrInvalidSave)
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+	require.Equal(t, uint64(7), *x) // the arena is left as is on error
+
+	// Headers claiming more memory than the input supplies must be rejected without allocating it.
+	huge := []byte("NUKE")
+	huge = binary.LittleEndian.AppendUint32(huge, saveVersion)
+	huge = binary.LittleEndian.AppendUint32(huge, 1)
+	huge = binary.LittleEndian.AppendUint64(huge, 1<<42)
+	huge = binary.LittleEndian.AppendUint64(huge, 1)
+	huge = append(huge, 0, 0, 0, 0, 0)
+	require.ErrorIs(t, Load(bytes.NewReader(huge), b), ErrInvalidSave)
+	binary.LittleEndian.PutUint64(huge[20:], 1<<42)
+	require.ErrorIs(t, Load(bytes.NewReader(huge), b), ErrInvalidSave)
+	binary.LittleEndian.PutUint32(huge[8:], math.MaxUint32)
+	require.ErrorIs(t, Load(bytes.NewReader(huge[:12]), b), ErrInvalidSave)
+	require.Equal(t, uint64(7), *x)
+
+	require.ErrorIs(t, Save(&buf, NewGCArena(256)), ErrSaveNotSupported)
+	require.ErrorIs(t, Load(&buf, NewGCArena(256)), ErrSaveNotSupported)
+	require.Nil(t, At[uint64](NewGCArena(256), 0))
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "runtime"
+
+// pinner is implemented by arenas able to pin the memory backing them.
+type pinner interface {
+	pin(p *runtime.Pinner)
+}
+
+// PinArena pins all the memory currently backing the arena with p, so that pointers to values
+// allocated from it can be retained by C code after the cgo calls they're passed to return,
+// until p is unpinned. Arena memory is plain Go memory, which the garbage collector never moves,
+// and may be passed to cgo calls as is, as long as it holds no Go pointers, but cgo rules require
+// it to be pinned to be retained. Memory the arena acquires afterwards, such as a buffer allocated
+// lazily or the memory of a large allocation, must be pinned again.
+//
+// Only the arena memory is pinned, not what values allocated from a GC arena point to, which must
+// be pinned on its own. The memory must be unpinned before the arena is reset, as the arena may
+// release it.
+//
+// PinArena reports whether the arena supports pinning, as the arenas of this package do, and wrappers
+// do if the arenas they wrap do (see CapPin). Arenas not supporting it are left as they are.
+func PinArena(p *runtime.Pinner, a Arena) bool {
+	if !Capabilities(a).Has(CapPin) {
+		return false
+	}
+	a.(pinner).pin(p)
+	return true
+}
+
+func (a *monotonicArena) pin(p *runtime.Pinner) {
+	if debugEnabled {
+		a.guard.enter()
+		defer a.guard.exit()
+	}
+	for _, s := range a.buffers {
+		if s.ptr != nil {
+			p.Pin(s.ptr)
+		}
+	}
+	for _, buf := range a.large {
+		if len(buf) > 0 {
+			p.Pin(&buf[0])
+		}
+	}
+}
+
+func (a *gcArena) pin(p *runtime.Pinner) {
+	for _, c := range a.chunks {
+		p.Pin(c.v.UnsafePointer())
+	}
+	for _, v := range a.large {
+		if v.Len() > 0 {
+			p.Pin(v.UnsafePointer())
+		}
+	}
+	a.bytes.pin(p)
+}
+
+func (a *concurrentArena) pin(p *runtime.Pinner) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	PinArena(p, a.a)
+}
+
+func (a *ScavengingArena) pin(p *runtime.Pinner) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	PinArena(p, a.a)
+}
+
+func (a *FlipArena) pin(p *runtime.Pinner) {
+	PinArena(p, a.arenas[0])
+	PinArena(p, a.arenas[1])
+}
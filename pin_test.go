@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPinArena(t *testing.T) {
+	type node struct {
+		name string
+		next *node
+	}
+	arenas := map[string]Arena{
+		"monotonic":  NewMonotonicArena(1024, 2, WithLargeAllocThreshold(512)),
+		"gc":         NewGCArena(1024),
+		"concurrent": NewConcurrentArena(NewMonotonicArena(1024, 1)),
+		"flip":       NewFlipArena(NewGCArena(1024), NewMonotonicArena(1024, 1)),
+	}
+	for name, arena := range arenas {
+		t.Run(name, func(t *testing.T) {
+			_ = New[int](arena)
+			_ = MakeSlice[byte](arena, 600, 600)
+			if _, ok := arena.(typedAllocator); ok {
+				_ = New[node](arena)
+				_ = MakeSlice[node](arena, 100, 100)
+			}
+
+			var p runtime.Pinner
+			require.True(t, PinArena(&p, arena))
+			p.Unpin()
+			arena.Reset(true)
+		})
+	}
+	require.False(t, PinArena(&runtime.Pinner{}, nilArena{}))
+	require.False(t, PinArena(&runtime.Pinner{}, NewConcurrentArena(nilArena{})))
+	require.False(t, PinArena(&runtime.Pinner{}, NewFlipArena(NewGCArena(1024), nilArena{})))
+}
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// pointerFreeTypes caches whether types are free of pointers.
+var pointerFreeTypes sync.Map // map[reflect.Type]bool
+
+// PointerFree reports whether values of the given type hold no pointers, which makes them safe
+// to be allocated from any arena, and to be copied in and out of memory byte by byte. Results are
+// cached by type, so it's cheap enough to be called on every allocation.
+func PointerFree(typ reflect.Type) bool {
+	pointerFree, ok := pointerFreeTypes.Load(typ)
+	if !ok {
+		pointerFree, _ = pointerFreeTypes.LoadOrStore(typ, !hasPointers(typ))
+	}
+	return pointerFree.(bool)
+}
+
+// assertPointerFree panics if values of the given type hold pointers, which makes them unsafe
+// to be allocated from arena memory not scanned by the garbage collector.
+// It's only used when the package is built with the nuke_debug build tag.
+func assertPointerFree(typ reflect.Type) {
+	if !PointerFree(typ) {
+		panic(fmt.Sprintf("nuke: %s holds pointers and can't be allocated from an arena not scanned by the garbage collector (see NewGCArena)", typ))
+	}
+}
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointerFree(t *testing.T) {
+	for _, v := range []any{0, noScanObject{}, [4]complex64{}, [0]*int{}, struct{}{}} {
+		require.True(t, PointerFree(reflect.TypeOf(v)), "%T", v)
+	}
+	for _, v := range []any{"", []int{}, new(int), map[int]int{}, unsafe.Pointer(nil), [1]any{}, struct{ f func() }{}} {
+		require.False(t, PointerFree(reflect.TypeOf(v)), "%T", v)
+	}
+}
@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "unsafe"
+
+// PoisonByte is the value reclaimed arena memory is filled with on Reset
+// when the package is built with the nuke_debug build tag.
+const PoisonByte = 0xDE
+
+// IsPoisoned reports whether the value pointed to by p consists entirely of poison bytes.
+// In nuke_debug builds this is a strong sign of p referencing memory from an arena
+// that has been reset since the value was allocated.
+func IsPoisoned[T any](p *T) bool {
+	b := unsafe.Slice((*byte)(unsafe.Pointer(p)), unsafe.Sizeof(*p))
+	if len(b) == 0 {
+		return false
+	}
+	for _, c := range b {
+		if c != PoisonByte {
+			return false
+		}
+	}
+	return true
+}
+
+// AssertNotPoisoned panics if the value pointed to by p is poisoned (see IsPoisoned).
+func AssertNotPoisoned[T any](p *T) {
+	if IsPoisoned(p) {
+		panic("nuke: use of a value whose arena has been reset")
+	}
+}
+
+// poison fills b with poison bytes.
+func poison(b []byte) {
+	for i := range b {
+		b[i] = PoisonByte
+	}
+}
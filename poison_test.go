@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPoisoned(t *testing.T) {
+	var v uint32
+	require.False(t, IsPoisoned(&v))
+
+	v = 0xDEDEDEDE
+	require.True(t, IsPoisoned(&v))
+
+	v = 0xDEDEDE00
+	require.False(t, IsPoisoned(&v))
+
+	require.False(t, IsPoisoned(&struct{}{}))
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PressureMonitor watches the memory used by the Go runtime, which includes the memory of arenas,
+// against the soft memory limit set with debug.SetMemoryLimit, or the GOMEMLIMIT environment
+// variable, and runs the registered handlers when the usage crosses a threshold, so that arenas
+// can release their memory before the garbage collector has to struggle to stay below the limit.
+// ArenaPools created with WithPressureMonitor release the memory of their arenas while the
+// monitor reports pressure.
+type PressureMonitor struct {
+	threshold float64
+	usage     func() (used, limit uint64)
+
+	mtx      sync.Mutex
+	handlers []func()
+	pressure atomic.Bool
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewPressureMonitor returns a PressureMonitor checking every interval whether the memory used
+// by the runtime has reached the given fraction of the memory limit. No pressure is reported
+// while no memory limit is set, nor under TinyGo, which lacks runtime/metrics.
+// Close must be called to stop the monitor.
+func NewPressureMonitor(threshold float64, interval time.Duration) *PressureMonitor {
+	m := newPressureMonitor(threshold, runtimeMemoryUsage)
+	go m.monitor(interval)
+	return m
+}
+
+func newPressureMonitor(threshold float64, usage func() (used, limit uint64)) *PressureMonitor {
+	return &PressureMonitor{
+		threshold: threshold,
+		usage:     usage,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// OnPressure registers a handler to run every time the memory usage crosses the threshold.
+// Handlers run on the monitor goroutine, so they must only access arenas that are safe to be
+// accessed concurrently.
+func (m *PressureMonitor) OnPressure(fn func()) {
+	m.mtx.Lock()
+	m.handlers = append(m.handlers, fn)
+	m.mtx.Unlock()
+}
+
+// UnderPressure reports whether the memory usage was above the threshold when last checked.
+func (m *PressureMonitor) UnderPressure() bool {
+	return m.pressure.Load()
+}
+
+// Close stops the monitor, which reports no pressure afterwards.
+func (m *PressureMonitor) Close() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+		m.pressure.Store(false)
+	})
+}
+
+func (m *PressureMonitor) monitor(interval time.Duration) {
+	tc := time.NewTicker(interval)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-tc.C:
+			m.check()
+
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+// check updates the pressure state, running the handlers when entering it.
+func (m *PressureMonitor) check() {
+	used, limit := m.usage()
+	pressure := limit > 0 && float64(used) >= m.threshold*float64(limit)
+	if !m.pressure.Swap(pressure) && pressure {
+		m.mtx.Lock()
+		handlers := m.handlers
+		m.mtx.Unlock()
+
+		for _, fn := range handlers {
+			fn()
+		}
+	}
+}
+
+// WithPressureMonitor makes the pool release the memory of its idle arenas when the monitor
+// reports pressure, and that of arenas put back while it lasts.
+func WithPressureMonitor(m *PressureMonitor) PoolOption {
+	return func(o *poolOptions) { o.pressure = m }
+}
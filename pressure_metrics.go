@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+
+package nuke
+
+import (
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+)
+
+// runtimeMemoryUsage returns the memory used by the runtime as accounted for by the memory
+// limit, and the limit itself, or zero if there's none.
+func runtimeMemoryUsage() (used, limit uint64) {
+	l := debug.SetMemoryLimit(-1)
+	if l == math.MaxInt64 {
+		return 0, 0
+	}
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+	return samples[0].Value.Uint64() - samples[1].Value.Uint64(), uint64(l)
+}
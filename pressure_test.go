@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPressureMonitor(t *testing.T) {
+	var used uint64
+	m := newPressureMonitor(0.8, func() (uint64, uint64) { return used, 1000 })
+
+	var calls int
+	m.OnPressure(func() { calls++ })
+
+	used = 500
+	m.check()
+	require.False(t, m.UnderPressure())
+
+	used = 800
+	m.check()
+	m.check()
+	require.True(t, m.UnderPressure())
+	require.Equal(t, 1, calls) // handlers only run when entering pressure
+
+	used = 100
+	m.check()
+	require.False(t, m.UnderPressure())
+
+	used = 900
+	m.check()
+	require.Equal(t, 2, calls)
+
+	m.Close()
+	require.False(t, m.UnderPressure())
+}
+
+func TestPressureMonitorNoLimit(t *testing.T) {
+	m := NewPressureMonitor(0.1, time.Millisecond)
+	defer m.Close()
+
+	time.Sleep(5 * time.Millisecond)
+	require.False(t, m.UnderPressure()) // there's no memory limit during tests
+}
+
+func TestArenaPoolPressure(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	var used uint64
+	m := newPressureMonitor(0.5, func() (uint64, uint64) { return used, 1000 })
+
+	pool := NewArenaPool(func() Arena { return NewMonotonicArena(1024, 1) }, WithMaxArenas(2), WithPressureMonitor(m))
+	a1, a2 := pool.Get(), pool.Get()
+	New[int](a1)
+	New[int](a2)
+	pool.Put(a1)
+	require.Equal(t, 1, heldBuffers(a1))
+
+	// Entering pressure releases the memory of idle arenas, and that of arenas put back.
+	used = 600
+	m.check()
+	require.Zero(t, heldBuffers(a1))
+
+	pool.Put(a2)
+	require.Zero(t, heldBuffers(a2))
+}
@@ -0,0 +1,9 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build tinygo
+
+package nuke
+
+func runtimeMemoryUsage() (used, limit uint64) {
+	return 0, 0
+}
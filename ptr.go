@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+// epochReporter is implemented by arenas that keep track of how many times they have been reset.
+type epochReporter interface {
+	epoch() uint64
+}
+
+// Ptr is a checked pointer to a value allocated from an arena. It remembers the epoch
+// of the arena at allocation time, that is, how many times the arena had been reset,
+// so that uses of the value after the arena has been reset again can be detected.
+//
+// Checks are only performed in nuke_debug builds; otherwise Ptr is just a pointer
+// with a few extra words attached.
+type Ptr[T any] struct {
+	p     *T
+	a     epochReporter
+	epoch uint64
+}
+
+// NewPtr allocates a value of type T from the arena like New does, and returns a checked pointer to it.
+func NewPtr[T any](a Arena) Ptr[T] {
+	p := Ptr[T]{p: New[T](a)}
+	if r, ok := a.(epochReporter); ok {
+		p.a = r
+		p.epoch = r.epoch()
+	}
+	return p
+}
+
+// Get returns the pointer to the value. In nuke_debug builds it panics if the arena
+// the value was allocated from has been reset since.
+func (p Ptr[T]) Get() *T {
+	if debugEnabled && p.a != nil && p.a.epoch() != p.epoch {
+		panic("nuke: use of a value whose arena has been reset")
+	}
+	return p.p
+}
@@ -0,0 +1,23 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPtr(t *testing.T) {
+	arena := NewConcurrentArena(NewMonotonicArena(1024, 1))
+	arena.Reset(false)
+
+	p := NewPtr[int](arena)
+	*p.Get() = 42
+	require.Equal(t, 42, *p.Get())
+	require.Equal(t, uint64(1), p.epoch)
+
+	// Values allocated without an arena are never invalidated.
+	p = NewPtr[int](nil)
+	require.NotNil(t, p.Get())
+}
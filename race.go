@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build race
+
+package nuke
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// raceEnabled reports whether the package has been built with the race detector.
+const raceEnabled = true
+
+// raceAcquire establishes a happens-before relation with the last raceReleaseMerge on addr.
+func raceAcquire(addr unsafe.Pointer) {
+	runtime.RaceAcquire(addr)
+}
+
+// raceReleaseMerge makes everything done so far visible to subsequent raceAcquire calls on addr.
+func raceReleaseMerge(addr unsafe.Pointer) {
+	runtime.RaceReleaseMerge(addr)
+}
+
+// raceWriteRange reports a write of the given memory region to the race detector.
+func raceWriteRange(ptr unsafe.Pointer, size uintptr) {
+	if size > 0 {
+		runtime.RaceWriteRange(ptr, int(size))
+	}
+}
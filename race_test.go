@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build race
+
+package nuke
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentArenaReuseAcrossGoroutines(t *testing.T) {
+	arena := NewConcurrentArena(NewMonotonicArena(1024, 1))
+
+	// Every round writes to memory that goroutines of the previous round wrote
+	// to before the reset, which must not be reported as a race.
+	for round := 0; round < 10; round++ {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				s := MakeSlice[int](arena, 16, 16)
+				for k := range s {
+					s[k] = i
+				}
+				require.Equal(t, i, s[15])
+			}(i)
+		}
+		wg.Wait()
+		arena.Reset(false)
+	}
+}
@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+// Ref is a handle to a value allocated from an arena, meant for application code that must
+// not deal with unsafe pointers to arena memory. Values are read and written through Load
+// and Store, or accessed in place through Value.
+//
+// In nuke_debug builds every access validates that the Ref was obtained from Alloc and that
+// its arena hasn't been reset since, panicking otherwise (see Ptr).
+type Ref[T any] struct {
+	p Ptr[T]
+}
+
+// Alloc allocates a zeroed value of type T from the arena like New does, and returns a Ref to it.
+func Alloc[T any](a Arena) Ref[T] {
+	return Ref[T]{p: NewPtr[T](a)}
+}
+
+// Value returns a pointer to the referenced value, which must not be retained beyond the
+// next reset of the arena.
+func (r Ref[T]) Value() *T {
+	if debugEnabled && r.p.p == nil {
+		panic("nuke: use of a Ref that wasn't obtained from Alloc")
+	}
+	return r.p.Get()
+}
+
+// Load returns a copy of the referenced value.
+func (r Ref[T]) Load() T {
+	return *r.Value()
+}
+
+// Store sets the referenced value to v.
+func (r Ref[T]) Store(v T) {
+	*r.Value() = v
+}
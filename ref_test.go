@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRef(t *testing.T) {
+	type point struct{ X, Y int }
+
+	arena := NewMonotonicArena(1024, 1)
+
+	r := Alloc[point](arena)
+	require.Equal(t, point{}, r.Load())
+
+	r.Store(point{X: 1, Y: 2})
+	require.Equal(t, point{X: 1, Y: 2}, r.Load())
+
+	r.Value().Y = 3
+	require.Equal(t, point{X: 1, Y: 3}, r.Load())
+}
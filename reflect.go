@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// NewOf is the reflection counterpart of New, meant for code that only knows the type to be
+// allocated at run time, such as decoders. It returns a Value representing a pointer to a new
+// zero value of the given type, allocated from the arena if non-nil and capable of serving it.
+// Otherwise, or if the package has been built with the nuke_off build tag, it behaves like reflect.New.
+func NewOf(a Arena, typ reflect.Type) reflect.Value {
+	if ptr := allocOf(a, typ, 1); ptr != nil {
+		return reflect.NewAt(typ, ptr)
+	}
+	return reflect.New(typ)
+}
+
+// MakeSliceOf is the reflection counterpart of MakeSlice. It returns a Value holding a slice
+// of the given slice type, length and capacity, whose backing array is allocated from the arena
+// if non-nil and capable of serving it. Otherwise, or if the package has been built with the
+// nuke_off build tag, it behaves like reflect.MakeSlice.
+func MakeSliceOf(a Arena, typ reflect.Type, len, cap int) reflect.Value {
+	if _, ok := sliceSize(typ.Elem().Size(), len, cap); ok {
+		if ptr := allocOf(a, typ.Elem(), cap); ptr != nil {
+			s := reflect.New(typ)
+			*(*[]byte)(s.UnsafePointer()) = unsafe.Slice((*byte)(ptr), cap)[:len]
+			return s.Elem()
+		}
+	}
+	return reflect.MakeSlice(typ, len, cap)
+}
+
+// MakeString returns a string holding a copy of b, allocated from the arena if non-nil
+// and capable of serving it, or from the heap otherwise.
+func MakeString(a Arena, b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	s := MakeSlice[byte](a, len(b), len(b))
+	copy(s, b)
+	return unsafe.String(unsafe.SliceData(s), len(s))
+}
+
+// allocOf allocates n contiguous values of the given type from the arena, returning nil
+// if there's no arena or it can't serve the allocation, in which case the fallback is recorded.
+func allocOf(a Arena, typ reflect.Type, n int) unsafe.Pointer {
+	if a == nil || arenasDisabled {
+		return nil
+	}
+	var ptr unsafe.Pointer
+	if ta, ok := a.(typedAllocator); ok {
+		ptr = ta.allocTyped(typ, n)
+	} else {
+		if debugEnabled {
+			assertPointerFree(typ)
+		}
+		ptr = a.Alloc(typ.Size()*uintptr(n), uintptr(typ.Align()))
+	}
+	if ptr == nil {
+		recordHeapFallback(a)
+		return nil
+	}
+	if debugEnabled {
+		assertAligned(ptr, uintptr(typ.Align()))
+	}
+	return ptr
+}
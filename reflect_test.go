@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOf(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(1024, 1)
+
+	v := NewOf(arena, reflect.TypeOf(int64(0)))
+	require.Equal(t, reflect.Pointer, v.Kind())
+	require.Equal(t, int64(0), v.Elem().Int())
+	require.Equal(t, uint64(1), arenaMetrics(arena).Allocs)
+
+	v = NewOf(nil, reflect.TypeOf(int64(0)))
+	require.Equal(t, int64(0), v.Elem().Int())
+}
+
+func TestMakeSliceOf(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(1024, 1)
+
+	v := MakeSliceOf(arena, reflect.TypeOf([]int32(nil)), 2, 8)
+	s := v.Interface().([]int32)
+	require.Len(t, s, 2)
+	require.Equal(t, 8, cap(s))
+	require.Equal(t, uint64(1), arenaMetrics(arena).Allocs)
+
+	// Sizes exceeding the arena fall back to the heap.
+	v = MakeSliceOf(arena, reflect.TypeOf([]int32(nil)), 0, 1024)
+	require.Equal(t, 1024, v.Cap())
+	require.Equal(t, uint64(1), arenaMetrics(arena).HeapFallbacks)
+}
+
+func TestMakeString(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(1024, 1)
+
+	b := []byte("hello")
+	s := MakeString(arena, b)
+	b[0] = 'j'
+	require.Equal(t, "hello", s)
+	require.Equal(t, uint64(1), arenaMetrics(arena).Allocs)
+
+	require.Equal(t, "", MakeString(arena, nil))
+	require.Equal(t, "hello", MakeString(nil, []byte("hello")))
+}
@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "unsafe"
+
+// Rel is a relative pointer to a value of type T, storing the offset of the value from the
+// Rel itself rather than its address. In-arena data structures linked with Rel rather than
+// with pointers hold no GC-visible pointers by construction, and remain valid when their
+// memory is moved around as a whole, such as by Save and Load, or by a file-backed or
+// shared memory mapping.
+//
+// A Rel is only meaningful at the place it was set, so it must be accessed in place and
+// never copied, and it must live in the same arena buffer as the value it points to, or
+// more generally in the same allocation, which makes it suited to arenas with a single buffer.
+// The zero value is a nil pointer.
+type Rel[T any] struct {
+	// off is the offset of the value from the Rel shifted left by one bit,
+	// with the lowest bit set so that a zero offset isn't mistaken for nil.
+	off int64
+}
+
+// Set makes the Rel point to p, which may be nil.
+func (r *Rel[T]) Set(p *T) {
+	if p == nil {
+		r.off = 0
+		return
+	}
+	r.off = int64(uintptr(unsafe.Pointer(p))-uintptr(unsafe.Pointer(r)))<<1 | 1
+}
+
+// Get returns a pointer to the value the Rel points to, or nil if it's nil.
+func (r *Rel[T]) Get() *T {
+	if r.off == 0 {
+		return nil
+	}
+	return (*T)(unsafe.Add(unsafe.Pointer(r), r.off>>1))
+}
+
+// IsNil reports whether the Rel is nil.
+func (r *Rel[T]) IsNil() bool {
+	return r.off == 0
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRel(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	type node struct {
+		val  uint64
+		next Rel[node]
+	}
+	a := NewMonotonicArena(1024, 1)
+
+	// Build the list 0 -> 1 -> 2 -> 0, with the head at offset 0.
+	nodes := [3]*node{New[node](a), New[node](a), New[node](a)}
+	for i, n := range nodes {
+		n.val = uint64(i)
+		n.next.Set(nodes[(i+1)%3])
+	}
+	require.Same(t, nodes[1], nodes[0].next.Get())
+	require.Same(t, nodes[0], nodes[2].next.Get()) // negative offset
+
+	var buf bytes.Buffer
+	require.NoError(t, Save(&buf, a))
+	b := NewMonotonicArena(1024, 1)
+	require.NoError(t, Load(&buf, b))
+
+	n := At[node](b, 0)
+	require.NotSame(t, nodes[0], n)
+	for i := 0; i < 6; i++ {
+		require.Equal(t, uint64(i%3), n.val)
+		n = n.next.Get()
+	}
+}
+
+func TestRelNil(t *testing.T) {
+	type node struct {
+		next Rel[node]
+	}
+	v := &struct {
+		r Rel[int]
+		x int
+	}{x: 42}
+	require.True(t, v.r.IsNil())
+	require.Nil(t, v.r.Get())
+
+	v.r.Set(&v.x)
+	require.False(t, v.r.IsNil())
+	require.Equal(t, 42, *v.r.Get())
+	v.r.Set(nil)
+	require.Nil(t, v.r.Get())
+
+	// A value may point to itself.
+	n := &node{}
+	n.next.Set(n)
+	require.Same(t, n, n.next.Get())
+}
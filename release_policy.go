@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "time"
+
+// ReleasePolicy controls when an arena releases the memory of its buffers on Reset(false),
+// leaving it to the garbage collector to return to the OS, while Reset(true) keeps releasing
+// it unconditionally. The zero ReleasePolicy never releases memory on Reset(false).
+type ReleasePolicy struct {
+	// Immediate releases the memory of every buffer on every reset.
+	Immediate bool
+
+	// IdleResets releases the memory of buffers that haven't been used for this many
+	// consecutive resets, if positive.
+	IdleResets int
+
+	// IdleTime releases the memory of buffers that haven't been used for at least this
+	// long, as checked on every reset, if positive.
+	IdleTime time.Duration
+}
+
+// WithReleasePolicy sets the policy controlling when the arena releases the memory of its buffers.
+func WithReleasePolicy(p ReleasePolicy) Option {
+	return func(o *options) { o.releasePolicy = p }
+}
+
+// releasable reports whether the memory of the buffer must be released by the reset about to
+// happen, keeping track of how long it's been idle.
+func (p *ReleasePolicy) releasable(s *monotonicBuffer, now time.Time) bool {
+	if p.Immediate {
+		return true
+	}
+	if s.offset > 0 {
+		s.idleResets = 0
+		s.usedAt = now
+		return false
+	}
+	if s.ptr == nil {
+		return false // nothing to release
+	}
+	s.idleResets++
+	return (p.IdleResets > 0 && s.idleResets >= p.IdleResets) ||
+		(p.IdleTime > 0 && now.Sub(s.usedAt) >= p.IdleTime)
+}
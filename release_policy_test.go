@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// heldBuffers returns the number of arena buffers holding memory.
+func heldBuffers(a Arena) int {
+	n := 0
+	for _, s := range a.(*monotonicArena).buffers {
+		if s.ptr != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func TestReleasePolicyImmediate(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewMonotonicArena(1024, 1, WithReleasePolicy(ReleasePolicy{Immediate: true}))
+
+	New[int](a)
+	require.Equal(t, 1, heldBuffers(a))
+	a.Reset(false)
+	require.Zero(t, heldBuffers(a))
+}
+
+func TestReleasePolicyIdleResets(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewMonotonicArena(1024, 2, WithReleasePolicy(ReleasePolicy{IdleResets: 2}))
+
+	MakeSlice[byte](a, 1024, 1024)
+	MakeSlice[byte](a, 1024, 1024)
+	a.Reset(false)
+	require.Equal(t, 2, heldBuffers(a))
+
+	// Only the first buffer is used from now on, so the second one is released after two resets.
+	MakeSlice[byte](a, 8, 8)
+	a.Reset(false)
+	require.Equal(t, 2, heldBuffers(a))
+	MakeSlice[byte](a, 8, 8)
+	a.Reset(false)
+	require.Equal(t, 1, heldBuffers(a))
+}
+
+func TestReleasePolicyIdleTime(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewMonotonicArena(1024, 1, WithReleasePolicy(ReleasePolicy{IdleTime: time.Millisecond}))
+
+	New[int](a)
+	a.Reset(false)
+	a.Reset(false)
+	require.Equal(t, 1, heldBuffers(a))
+
+	time.Sleep(2 * time.Millisecond)
+	a.Reset(false)
+	require.Zero(t, heldBuffers(a))
+}
@@ -0,0 +1,220 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+const (
+	// startBufSize is the size of the initial buffer of a Scanner, unless set with Buffer.
+	startBufSize = 4096
+
+	// maxConsecutiveEmptyReads is the number of reads without progress a Scanner tolerates.
+	maxConsecutiveEmptyReads = 100
+)
+
+// Scanner reads data delimited by a split function, like bufio.Scanner does, but allocating
+// its read buffer from an arena. Buffers outgrown by long tokens are left in the arena until
+// it's reset, so the initial size set with Buffer should fit most of the tokens.
+//
+// Like with bufio.Scanner, the token returned by Bytes is overwritten by the next call to Scan,
+// and TokenCopy should be used to retain it without pinning the read buffer.
+type Scanner struct {
+	a            Arena
+	r            io.Reader
+	split        bufio.SplitFunc
+	maxTokenSize int
+	token        []byte
+	buf          []byte
+	initSize     int
+	start        int
+	end          int
+	err          error
+	empties      int
+	scanCalled   bool
+	done         bool
+}
+
+// NewScanner returns a Scanner reading from r, whose buffer is allocated from the arena.
+// The split function defaults to bufio.ScanLines.
+func NewScanner(a Arena, r io.Reader) *Scanner {
+	return &Scanner{
+		a:            a,
+		r:            r,
+		split:        bufio.ScanLines,
+		maxTokenSize: bufio.MaxScanTokenSize,
+		initSize:     startBufSize,
+	}
+}
+
+// Err returns the first non-EOF error encountered by the Scanner.
+func (s *Scanner) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+// Bytes returns the most recent token generated by a call to Scan. Its memory may be
+// overwritten by a subsequent call to Scan.
+func (s *Scanner) Bytes() []byte {
+	return s.token
+}
+
+// Text returns the most recent token generated by a call to Scan as a string
+// allocated from the arena.
+func (s *Scanner) Text() string {
+	return MakeString(s.a, s.token)
+}
+
+// TokenCopy returns a copy of the most recent token generated by a call to Scan, allocated
+// from the arena, which remains valid across subsequent calls to Scan until the arena is reset.
+func (s *Scanner) TokenCopy() []byte {
+	if s.token == nil {
+		return nil
+	}
+	b := MakeSlice[byte](s.a, len(s.token), len(s.token))
+	copy(b, s.token)
+	return b
+}
+
+// Buffer sets the initial size of the buffer to allocate when scanning and the maximum
+// size of buffer that may be allocated, like bufio.Scanner.Buffer does.
+// Buffer panics if it is called after scanning has started.
+func (s *Scanner) Buffer(size, max int) {
+	if s.scanCalled {
+		panic("nuke: Buffer called after Scan")
+	}
+	s.initSize = size
+	s.maxTokenSize = max
+}
+
+// Split sets the split function for the Scanner. Split panics if it is called after
+// scanning has started.
+func (s *Scanner) Split(split bufio.SplitFunc) {
+	if s.scanCalled {
+		panic("nuke: Split called after Scan")
+	}
+	s.split = split
+}
+
+// Scan advances the Scanner to the next token, which will then be available through
+// the Bytes, Text and TokenCopy methods, following the semantics of bufio.Scanner.Scan.
+func (s *Scanner) Scan() bool {
+	if s.done {
+		return false
+	}
+	s.scanCalled = true
+	// Loop until we have a token.
+	for {
+		// See if we can get a token with what we already have. If we've run out of data
+		// but have an error, give the split function a chance to recover any remaining data.
+		if s.end > s.start || s.err != nil {
+			advance, token, err := s.split(s.buf[s.start:s.end], s.err != nil)
+			if err != nil {
+				if errors.Is(err, bufio.ErrFinalToken) {
+					s.token = token
+					s.done = true
+					return token != nil
+				}
+				s.setErr(err)
+				return false
+			}
+			if !s.advance(advance) {
+				return false
+			}
+			s.token = token
+			if token != nil {
+				if s.err == nil || advance > 0 {
+					s.empties = 0
+				} else {
+					// Returning tokens not advancing input at EOF.
+					s.empties++
+					if s.empties > maxConsecutiveEmptyReads {
+						panic("nuke: too many empty tokens without progressing")
+					}
+				}
+				return true
+			}
+		}
+		// We cannot generate a token with what we are holding. If we've already hit EOF
+		// or an I/O error, we are done.
+		if s.err != nil {
+			s.start = 0
+			s.end = 0
+			return false
+		}
+		// Must read more data. First, shift data to beginning of buffer if there's lots
+		// of empty space or space is needed.
+		if s.start > 0 && (s.end == len(s.buf) || s.start > len(s.buf)/2) {
+			copy(s.buf, s.buf[s.start:s.end])
+			s.end -= s.start
+			s.start = 0
+		}
+		// Is the buffer full? If so, resize.
+		if s.end == len(s.buf) {
+			const maxInt = int(^uint(0) >> 1)
+			if len(s.buf) >= s.maxTokenSize || len(s.buf) > maxInt/2 {
+				s.setErr(bufio.ErrTooLong)
+				return false
+			}
+			newSize := len(s.buf) * 2
+			if newSize == 0 {
+				newSize = s.initSize
+			}
+			newSize = max(min(newSize, s.maxTokenSize), 1)
+			newBuf := MakeSlice[byte](s.a, newSize, newSize)
+			copy(newBuf, s.buf[s.start:s.end])
+			s.end -= s.start
+			s.start = 0
+			s.buf = newBuf
+		}
+		// Finally we can read some input. Make sure we don't get stuck with
+		// a misbehaving Reader.
+		for loop := 0; ; {
+			n, err := s.r.Read(s.buf[s.end:len(s.buf)])
+			if n < 0 || len(s.buf)-s.end < n {
+				s.setErr(bufio.ErrBadReadCount)
+				break
+			}
+			s.end += n
+			if err != nil {
+				s.setErr(err)
+				break
+			}
+			if n > 0 {
+				s.empties = 0
+				break
+			}
+			loop++
+			if loop > maxConsecutiveEmptyReads {
+				s.setErr(io.ErrNoProgress)
+				break
+			}
+		}
+	}
+}
+
+// advance consumes n bytes of the buffer, reporting whether the advance was legal.
+func (s *Scanner) advance(n int) bool {
+	if n < 0 {
+		s.setErr(bufio.ErrNegativeAdvance)
+		return false
+	}
+	if n > s.end-s.start {
+		s.setErr(bufio.ErrAdvanceTooFar)
+		return false
+	}
+	s.start += n
+	return true
+}
+
+// setErr records the first error encountered.
+func (s *Scanner) setErr(err error) {
+	if s.err == nil || s.err == io.EOF {
+		s.err = err
+	}
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanner(t *testing.T) {
+	input := strings.Repeat("the quick brown fox\njumps over\n\nthe lazy dog", 100)
+
+	for _, split := range []bufio.SplitFunc{bufio.ScanLines, bufio.ScanWords, bufio.ScanRunes} {
+		var want []string
+		bs := bufio.NewScanner(strings.NewReader(input))
+		bs.Split(split)
+		for bs.Scan() {
+			want = append(want, bs.Text())
+		}
+
+		var got []string
+		s := NewScanner(NewMonotonicArena(64*1024, 4), strings.NewReader(input))
+		s.Buffer(16, 1024)
+		s.Split(split)
+		for s.Scan() {
+			got = append(got, s.Text())
+		}
+		require.NoError(t, s.Err())
+		require.Equal(t, want, got)
+	}
+}
+
+func TestScannerTokenCopy(t *testing.T) {
+	arena := NewMonotonicArena(64*1024, 1)
+
+	s := NewScanner(arena, strings.NewReader("a\nb\nc"))
+	s.Buffer(4, 4)
+	var tokens [][]byte
+	for s.Scan() {
+		tokens = append(tokens, s.TokenCopy())
+	}
+	require.NoError(t, s.Err())
+	require.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, tokens)
+	require.Zero(t, arenaMetrics(arena).HeapFallbacks)
+}
+
+func TestScannerTooLong(t *testing.T) {
+	s := NewScanner(NewMonotonicArena(1024, 1), strings.NewReader("0123456789\n"))
+	s.Buffer(4, 8)
+	require.False(t, s.Scan())
+	require.ErrorIs(t, s.Err(), bufio.ErrTooLong)
+
+	require.Panics(t, func() { s.Split(bufio.ScanWords) })
+}
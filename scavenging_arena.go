@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Trimmer is implemented by arenas capable of releasing the memory held by buffers
+// that are not currently in use, without invalidating any live allocation.
+type Trimmer interface {
+	Trim()
+}
+
+// ScavengingArena is an arena that is safe to be accessed concurrently from multiple
+// goroutines and that releases the memory held by the unused buffers of its underlying
+// arena once it has been idle for a while.
+type ScavengingArena struct {
+	mtx    sync.Mutex
+	a      Arena
+	active bool
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewScavengingArena returns a ScavengingArena wrapping a, which trims it after being
+// idle for at least the given period. Close must be called to stop the scavenger.
+func NewScavengingArena(a Arena, idle time.Duration) *ScavengingArena {
+	sa := &ScavengingArena{
+		a:      a,
+		stopCh: make(chan struct{}),
+	}
+	go sa.scavenge(idle)
+	return sa
+}
+
+// Alloc satisfies the Arena interface.
+func (a *ScavengingArena) Alloc(size, alignment uintptr) unsafe.Pointer {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.active = true
+	return a.a.Alloc(size, alignment)
+}
+
+func (a *ScavengingArena) allocTyped(typ reflect.Type, n int) unsafe.Pointer {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if ta, ok := a.a.(typedAllocator); ok {
+		return ta.allocTyped(typ, n)
+	}
+	if debugEnabled {
+		assertPointerFree(typ) // the type is only checked here, as the wrapper is a typedAllocator
+	}
+	return a.a.Alloc(typ.Size()*uintptr(n), uintptr(typ.Align()))
+}
+
+// Reset satisfies the Arena interface.
+func (a *ScavengingArena) Reset(release bool) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.active = true
+	a.a.Reset(release)
+}
+
+// Metrics returns the allocation metrics of the underlying arena.
+func (a *ScavengingArena) Metrics() Metrics {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return arenaMetrics(a.a)
+}
+
+// SizeHistogram returns the histogram of allocation sizes of the underlying arena, if enabled.
+func (a *ScavengingArena) SizeHistogram() (SizeHistogram, bool) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if r, ok := a.a.(sizeHistogramReporter); ok {
+		return r.SizeHistogram()
+	}
+	return SizeHistogram{}, false
+}
+
+// BufferUsage returns the buffer usage of the underlying arena.
+func (a *ScavengingArena) BufferUsage() []BufferUsage {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if r, ok := a.a.(bufferUsageReporter); ok {
+		return r.BufferUsage()
+	}
+	return nil
+}
+
+func (a *ScavengingArena) recordHeapFallback() {
+	a.mtx.Lock()
+	defer a.mtx.Unlock() // strict arenas panic
+	recordHeapFallback(a.a)
+}
+
+func (a *ScavengingArena) checkCanaries() error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return CheckCanaries(a.a)
+}
+
+func (a *ScavengingArena) epoch() uint64 {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if r, ok := a.a.(epochReporter); ok {
+		return r.epoch()
+	}
+	return 0
+}
+
+func (a *ScavengingArena) demand() (uint64, bool) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if r, ok := a.a.(demandReporter); ok {
+		return r.demand()
+	}
+	return 0, false
+}
+
+func (a *ScavengingArena) allocSites() []AllocSite {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if r, ok := a.a.(allocSiteReporter); ok {
+		return r.allocSites()
+	}
+	return nil
+}
+
+// Trim releases the memory of the unused buffers of the underlying arena right away,
+// if it implements Trimmer, rather than waiting for the arena to become idle.
+func (a *ScavengingArena) Trim() {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	if t, ok := a.a.(Trimmer); ok {
+		t.Trim()
+	}
+}
+
+// Close stops the scavenger. The arena remains usable afterwards, but it won't be trimmed anymore.
+func (a *ScavengingArena) Close() {
+	a.stopOnce.Do(func() { close(a.stopCh) })
+}
+
+func (a *ScavengingArena) scavenge(idle time.Duration) {
+	tc := time.NewTicker(idle)
+	defer tc.Stop()
+
+	for {
+		select {
+		case <-tc.C:
+			a.mtx.Lock()
+			if t, ok := a.a.(Trimmer); ok && !a.active {
+				t.Trim()
+			}
+			a.active = false
+			a.mtx.Unlock()
+
+		case <-a.stopCh:
+			return
+		}
+	}
+}
+
+func (a *ScavengingArena) dump(w io.Writer, hexdump bool) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return Dump(w, a.a, hexdump)
+}
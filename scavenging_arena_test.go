@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScavengingArenaTrimsIdleBuffers(t *testing.T) {
+	ma := NewMonotonicArena(1024, 2).(*monotonicArena)
+
+	arena := NewScavengingArena(ma, 10*time.Millisecond)
+	defer arena.Close()
+
+	_ = MakeSlice[byte](arena, 1024, 1024)
+	_ = MakeSlice[byte](arena, 1024, 1024)
+	arena.Reset(false)
+
+	require.Eventually(t, func() bool {
+		arena.mtx.Lock()
+		defer arena.mtx.Unlock()
+		return ma.buffers[0].ptr == nil && ma.buffers[1].ptr == nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestScavengingArenaKeepsBuffersInUse(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	ma := NewMonotonicArena(1024, 2).(*monotonicArena)
+
+	arena := NewScavengingArena(ma, 10*time.Millisecond)
+
+	_ = MakeSlice[byte](arena, 1024, 1024)
+	_ = MakeSlice[byte](arena, 1024, 1024)
+	arena.Reset(false)
+	_ = New[int](arena)
+
+	time.Sleep(50 * time.Millisecond)
+	arena.Close()
+
+	arena.mtx.Lock()
+	defer arena.mtx.Unlock()
+	require.NotNil(t, ma.buffers[0].ptr)
+	require.Nil(t, ma.buffers[1].ptr)
+}
@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// sealChunkSize is the size of the chunks SaveSealed encrypts the output of Save in.
+const sealChunkSize = 64 * 1024
+
+// SaveSealed writes the memory handed out by the arena to w like Save does, encrypted and
+// authenticated with the AEAD, such as AES-GCM as returned by cipher.NewGCM, so that arenas
+// holding user data can be stored at rest. The output is sealed in chunks of 64KiB, each
+// authenticated along with its position, so that chunks can't be reordered or dropped.
+//
+// Every call draws a random nonce prefix, on top of which chunks are numbered. The AEAD must
+// take nonces of at least 12 bytes, and a key should not seal more than a few billion outputs.
+func SaveSealed(w io.Writer, a Arena, aead cipher.AEAD) error {
+	if aead.NonceSize() < 12 {
+		return fmt.Errorf("nuke: nonce size %d too small", aead.NonceSize())
+	}
+	sw := &sealWriter{w: w, aead: aead, nonce: make([]byte, aead.NonceSize())}
+	if _, err := rand.Read(sw.nonce); err != nil {
+		return err
+	}
+	if _, err := w.Write(sw.nonce); err != nil {
+		return err
+	}
+	if err := Save(sw, a); err != nil {
+		return err
+	}
+	return sw.seal(true)
+}
+
+// LoadSealed restores into the arena the memory written by SaveSealed with the same AEAD key,
+// like Load does, returning ErrInvalidSave if the input fails to authenticate. The whole input is
+// decrypted into memory and authenticated before being loaded, so the arena is left as is on error.
+func LoadSealed(r io.Reader, a Arena, aead cipher.AEAD) error {
+	if _, ok := a.(persister); !ok {
+		return ErrSaveNotSupported
+	}
+	sr := &sealReader{r: r, aead: aead, nonce: make([]byte, aead.NonceSize())}
+	if _, err := io.ReadFull(r, sr.nonce); err != nil {
+		return loadError(err)
+	}
+	var saved bytes.Buffer
+	if _, err := saved.ReadFrom(sr); err != nil {
+		return loadError(err)
+	}
+	// Make sure that the final chunk holds the end of the saved arena, and is followed by nothing else.
+	var b [1]byte
+	switch _, err := io.ReadFull(r, b[:]); err {
+	case io.EOF:
+	case nil:
+		return fmt.Errorf("%w: trailing data", ErrInvalidSave)
+	default:
+		return err
+	}
+	if n, ok := savedSize(saved.Bytes()); ok && n < saved.Len() {
+		return fmt.Errorf("%w: trailing data", ErrInvalidSave)
+	}
+	return Load(&saved, a)
+}
+
+// savedSize returns the size of the output of Save data starts with, reporting false if it's
+// truncated. Only the sizes it holds are parsed, as Load validates the rest.
+func savedSize(data []byte) (int, bool) {
+	if len(data) < 12 {
+		return 0, false
+	}
+	n := 12
+	for i := binary.LittleEndian.Uint32(data[8:]); i > 0; i-- {
+		// Every buffer is made of its size and used bytes, followed by them and their checksum.
+		if len(data)-n < 16+4 {
+			return 0, false
+		}
+		used := binary.LittleEndian.Uint64(data[n+8:])
+		if used > uint64(len(data)-n-16-4) {
+			return 0, false
+		}
+		n += 16 + int(used) + 4
+	}
+	return n, true
+}
+
+// sealWriter seals what's written to it in chunks of sealChunkSize bytes, each written
+// as its length as a little-endian uint32, followed by the sealed bytes.
+type sealWriter struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	nonce []byte
+	index uint64
+	buf   []byte
+}
+
+func (sw *sealWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		m := min(len(p), sealChunkSize-len(sw.buf))
+		sw.buf = append(sw.buf, p[:m]...)
+		p = p[m:]
+		if len(sw.buf) == sealChunkSize {
+			if err := sw.seal(false); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// seal seals the buffered bytes as the next chunk, marked as the last one if final is true.
+func (sw *sealWriter) seal(final bool) error {
+	ad := chunkAD(sw.index, final)
+	out := make([]byte, 4, 4+len(sw.buf)+sw.aead.Overhead())
+	out = sw.aead.Seal(out, chunkNonce(sw.nonce, sw.index), sw.buf, ad[:])
+	binary.LittleEndian.PutUint32(out, uint32(len(out)-4))
+	sw.index++
+	sw.buf = sw.buf[:0]
+	_, err := sw.w.Write(out)
+	return err
+}
+
+// sealReader opens the chunks written by a sealWriter.
+type sealReader struct {
+	r     io.Reader
+	aead  cipher.AEAD
+	nonce []byte
+	index uint64
+	buf   []byte
+	final bool
+}
+
+func (sr *sealReader) Read(p []byte) (int, error) {
+	for len(sr.buf) == 0 {
+		if sr.final {
+			return 0, io.EOF
+		}
+		if err := sr.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, sr.buf)
+	sr.buf = sr.buf[n:]
+	return n, nil
+}
+
+func (sr *sealReader) open() error {
+	var hdr [4]byte
+	if _, err := io.ReadFull(sr.r, hdr[:]); err != nil {
+		return io.ErrUnexpectedEOF // the final chunk is missing
+	}
+	n := binary.LittleEndian.Uint32(hdr[:])
+	if n < uint32(sr.aead.Overhead()) || n > uint32(sealChunkSize+sr.aead.Overhead()) {
+		return fmt.Errorf("%w: bad chunk length %d", ErrInvalidSave, n)
+	}
+	sealed := make([]byte, n)
+	if _, err := io.ReadFull(sr.r, sealed); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	// Chunks are either full or final, so try the kind the length suggests first.
+	final := n < uint32(sealChunkSize+sr.aead.Overhead())
+	nonce := chunkNonce(sr.nonce, sr.index)
+	ad := chunkAD(sr.index, final)
+	buf, err := sr.aead.Open(sealed[:0], nonce, sealed, ad[:])
+	if err != nil && !final {
+		final = true
+		ad = chunkAD(sr.index, final)
+		buf, err = sr.aead.Open(sealed[:0], nonce, sealed, ad[:])
+	}
+	if err != nil {
+		return fmt.Errorf("%w: chunk %d fails to authenticate", ErrInvalidSave, sr.index)
+	}
+	sr.buf = buf
+	sr.final = final
+	sr.index++
+	return nil
+}
+
+// chunkNonce returns the nonce of the i-th chunk, by XORing its index into the nonce prefix.
+func chunkNonce(prefix []byte, i uint64) []byte {
+	nonce := append([]byte(nil), prefix...)
+	tail := nonce[len(nonce)-8:]
+	binary.LittleEndian.PutUint64(tail, binary.LittleEndian.Uint64(tail)^i)
+	return nonce
+}
+
+// chunkAD returns the additional data the i-th chunk is authenticated along with.
+func chunkAD(i uint64, final bool) [9]byte {
+	var ad [9]byte
+	binary.LittleEndian.PutUint64(ad[:8], i)
+	if final {
+		ad[8] = 1
+	}
+	return ad
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAEAD(t *testing.T, key byte) cipher.AEAD {
+	block, err := aes.NewCipher(bytes.Repeat([]byte{key}, 32))
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return aead
+}
+
+func TestSaveSealed(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	aead := newTestAEAD(t, 1)
+
+	// Data spanning several chunks, including an exact multiple of the chunk size.
+	for _, size := range []int{100, 3*sealChunkSize + 123, sealChunkSize - 12 - 16 - 4} {
+		a := NewMonotonicArena(size, 1)
+		data := MakeSlice[byte](a, size, size)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+		var buf bytes.Buffer
+		require.NoError(t, SaveSealed(&buf, a, aead))
+		require.False(t, bytes.Contains(buf.Bytes(), data[:64]))
+
+		b := NewMonotonicArena(1024, 1)
+		require.NoError(t, LoadSealed(bytes.NewReader(buf.Bytes()), b, aead))
+		require.Equal(t, Snapshot(a), Snapshot(b))
+	}
+}
+
+func TestLoadSealedInvalid(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	aead := newTestAEAD(t, 1)
+	a := NewMonotonicArena(4*sealChunkSize, 1)
+	MakeSlice[byte](a, 3*sealChunkSize, 3*sealChunkSize)
+
+	var buf bytes.Buffer
+	require.NoError(t, SaveSealed(&buf, a, aead))
+	sealed := buf.Bytes()
+
+	b := NewMonotonicArena(4*sealChunkSize, 1)
+	x := New[uint64](b)
+	*x = 7
+	usage := b.(*monotonicArena).BufferUsage()
+	require.ErrorIs(t, LoadSealed(bytes.NewReader(sealed), b, newTestAEAD(t, 2)), ErrInvalidSave)
+
+	corrupted := append([]byte(nil), sealed...)
+	corrupted[len(corrupted)/2] ^= 1
+	require.ErrorIs(t, LoadSealed(bytes.NewReader(corrupted), b, aead), ErrInvalidSave)
+
+	// Dropping the last chunk is detected, even though the chunks left decrypt fine.
+	chunk := 4 + sealChunkSize + aead.Overhead()
+	truncated := sealed[:aead.NonceSize()+3*chunk]
+	require.ErrorIs(t, LoadSealed(bytes.NewReader(truncated), b, aead), ErrInvalidSave)
+
+	// So is data following the final chunk.
+	extended := append(append([]byte(nil), sealed...), 0)
+	require.ErrorIs(t, LoadSealed(bytes.NewReader(extended), b, aead), ErrInvalidSave)
+
+	// As is data following the saved arena within the final chunk.
+	var saved bytes.Buffer
+	require.NoError(t, Save(&saved, a))
+	var padded bytes.Buffer
+	sw := &sealWriter{w: &padded, aead: aead, nonce: make([]byte, aead.NonceSize())}
+	_, _ = padded.Write(sw.nonce)
+	_, _ = sw.Write(append(saved.Bytes(), 0))
+	require.NoError(t, sw.seal(true))
+	require.ErrorIs(t, LoadSealed(bytes.NewReader(padded.Bytes()), b, aead), ErrInvalidSave)
+
+	// The arena is left as is on error.
+	require.Equal(t, uint64(7), *x)
+	require.Equal(t, usage, b.(*monotonicArena).BufferUsage())
+
+	require.NoError(t, LoadSealed(bytes.NewReader(sealed), b, aead))
+	require.Equal(t, Snapshot(a), Snapshot(b))
+}
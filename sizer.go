@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"slices"
+	"sync"
+)
+
+// demandReporter is implemented by arenas keeping track of how many bytes they've been
+// requested since they were last reset, including the requests they couldn't serve.
+// Wrappers report false if the arena they wrap doesn't keep track of it.
+type demandReporter interface {
+	demand() (uint64, bool)
+}
+
+// ArenaSizer estimates the size arenas should have from the demand observed on arenas put back
+// into an ArenaPool (see WithArenaSizer), which is the number of bytes requested to them between
+// resets, including the requests they couldn't serve. It's safe to be accessed concurrently
+// from multiple goroutines.
+//
+// Only monotonic arenas, and those wrapping them, report their demand.
+type ArenaSizer struct {
+	minSize    int
+	maxSize    int
+	percentile float64
+
+	mtx     sync.Mutex
+	samples []uint64 // ring buffer of the latest observations
+	next    int
+	full    bool
+	size    int
+	dirty   bool
+}
+
+// NewArenaSizer returns an ArenaSizer estimating arena sizes as the given percentile, in the range
+// (0, 1], of the demand of the latest window observations, clamped to [minSize, maxSize].
+func NewArenaSizer(minSize, maxSize int, percentile float64, window int) *ArenaSizer {
+	if percentile <= 0 || percentile > 1 {
+		panic("nuke: ArenaSizer percentile out of range (0, 1]")
+	}
+	return &ArenaSizer{
+		minSize:    minSize,
+		maxSize:    max(maxSize, minSize),
+		percentile: percentile,
+		samples:    make([]uint64, max(window, 1)),
+		size:       minSize,
+	}
+}
+
+// Observe records the demand of the arena since it was last reset, so it must be called right
+// before resetting it. Arenas not reporting their demand are ignored.
+func (s *ArenaSizer) Observe(a Arena) {
+	dr, ok := a.(demandReporter)
+	if !ok {
+		return
+	}
+	d, ok := dr.demand()
+	if !ok {
+		return
+	}
+
+	s.mtx.Lock()
+	s.samples[s.next] = d
+	s.next++
+	if s.next == len(s.samples) {
+		s.next = 0
+		s.full = true
+	}
+	s.dirty = true
+	s.mtx.Unlock()
+}
+
+// Size returns the estimated size for new arenas, rounded up to a multiple of 64 bytes,
+// or the minimum size if no demand has been observed yet.
+func (s *ArenaSizer) Size() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if !s.dirty {
+		return s.size
+	}
+	n := s.next
+	if s.full {
+		n = len(s.samples)
+	}
+	sorted := slices.Clone(s.samples[:n])
+	slices.Sort(sorted)
+
+	i := max(int(s.percentile*float64(n)+0.999999)-1, 0)
+	size := min(sorted[i], uint64(s.maxSize))
+	s.size = min(max(int(alignUp(uintptr(size), bufferAlignment)), s.minSize), s.maxSize)
+	s.dirty = false
+	return s.size
+}
+
+// WithArenaSizer makes the pool observe the demand of arenas with the sizer before resetting them
+// on Put, so that the creation function can size new arenas after it.
+//
+//	sizer := nuke.NewArenaSizer(4*1024, 1024*1024, 0.95, 1000)
+//	pool := nuke.NewArenaPool(func() nuke.Arena {
+//		return nuke.NewMonotonicArena(sizer.Size(), 1)
+//	}, nuke.WithArenaSizer(sizer))
+func WithArenaSizer(s *ArenaSizer) PoolOption {
+	return func(o *poolOptions) { o.sizer = s }
+}
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArenaSizer(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	s := NewArenaSizer(1024, 64*1024, 0.9, 10)
+	require.Equal(t, 1024, s.Size())
+
+	for i := 1; i <= 10; i++ {
+		a := NewMonotonicArena(4096, 1)
+		MakeSlice[byte](a, i*1000, i*1000) // demands beyond the buffer size are counted too
+		s.Observe(a)
+	}
+	require.Equal(t, 9024, s.Size()) // 9000 rounded up
+
+	// Older observations fall out of the window.
+	for i := 0; i < 10; i++ {
+		s.Observe(NewConcurrentArena(NewMonotonicArena(4096, 1)))
+	}
+	require.Equal(t, 1024, s.Size())
+
+	s.Observe(NewGCArena(1024)) // ignored
+	require.Equal(t, 1024, s.Size())
+
+	// So are wrappers of arenas not reporting their demand, rather than observed as demanding nothing.
+	s = NewArenaSizer(1024, 64*1024, 0.5, 10)
+	a := NewMonotonicArena(8192, 1)
+	MakeSlice[byte](a, 5000, 5000)
+	s.Observe(a)
+	scavenging := NewScavengingArena(NewGCArena(1024), time.Hour)
+	defer scavenging.Close()
+	for i := 0; i < 10; i++ {
+		s.Observe(NewConcurrentArena(NewGCArena(1024)))
+		s.Observe(scavenging)
+	}
+	require.Equal(t, 5056, s.Size())
+
+	require.Panics(t, func() { NewArenaSizer(0, 0, 0, 1) })
+}
+
+func TestArenaPoolSizer(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	sizer := NewArenaSizer(64, 1024*1024, 1, 100)
+	pool := NewArenaPool(func() Arena {
+		return NewMonotonicArena(sizer.Size(), 1)
+	}, WithArenaSizer(sizer))
+
+	a := pool.Get()
+	MakeSlice[uint64](a, 1000, 1000)
+	pool.Put(a)
+	require.Equal(t, 8000, sizer.Size())
+}
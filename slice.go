@@ -7,7 +7,7 @@ const growThreshold = 256
 // SliceAppend appends elements to a slice of type T using a provided Arena
 // for memory allocation if needed.
 func SliceAppend[T any](a Arena, s []T, data ...T) []T {
-	if a == nil {
+	if a == nil || arenasDisabled {
 		return append(s, data...)
 	}
 	s = growSlice(a, s, len(data))
@@ -16,8 +16,20 @@ func SliceAppend[T any](a Arena, s []T, data ...T) []T {
 }
 
 func growSlice[T any](a Arena, s []T, dataLen int) []T {
-	newLen := len(s) + dataLen
-	newCap := cap(s)
+	newCap := growCap(len(s), cap(s), dataLen)
+	if newCap == cap(s) {
+		return s
+	}
+	s2 := MakeSlice[T](a, len(s), newCap)
+	copy(s2, s)
+	return s2
+}
+
+// growCap returns the capacity a slice of the given length and capacity grows to
+// in order to append dataLen elements to it.
+func growCap(len, cap, dataLen int) int {
+	newLen := len + dataLen
+	newCap := cap
 
 	if newCap > 0 {
 		for newLen > newCap {
@@ -26,14 +38,14 @@ func growSlice[T any](a Arena, s []T, dataLen int) []T {
 			} else {
 				newCap += newCap / 4
 			}
+			if newCap <= 0 {
+				// Growing overflowed, so settle on the requested length.
+				newCap = newLen
+				break
+			}
 		}
 	} else {
 		newCap = dataLen
 	}
-	if newCap == cap(s) {
-		return s
-	}
-	s2 := MakeSlice[T](a, len(s), newCap)
-	copy(s2, s)
-	return s2
+	return newCap
 }
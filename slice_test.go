@@ -3,6 +3,7 @@
 package nuke
 
 import (
+	"math"
 	"testing"
 	"unsafe"
 
@@ -41,3 +42,57 @@ func TestSliceAppendWithArena(t *testing.T) {
 	// Compare the result with the expected slice
 	require.Equal(t, expected, result)
 }
+
+func TestSliceSize(t *testing.T) {
+	size, ok := sliceSize(8, 2, 4)
+	require.True(t, ok)
+	require.Equal(t, uintptr(32), size)
+
+	_, ok = sliceSize(8, -1, 4)
+	require.False(t, ok)
+	_, ok = sliceSize(8, 5, 4)
+	require.False(t, ok)
+
+	// Sizes overflowing the address space are rejected, regardless of the platform word size.
+	_, ok = sliceSize(2, 0, math.MaxInt/2+1)
+	require.False(t, ok)
+	_, ok = sliceSize(math.MaxInt, 0, 2)
+	require.False(t, ok)
+}
+
+func TestMakeSliceOverflow(t *testing.T) {
+	arena := NewMonotonicArena(1024, 1)
+
+	require.Panics(t, func() { _ = MakeSlice[int64](arena, 0, math.MaxInt/2+1) })
+	require.Panics(t, func() { _ = MakeSlice[int64](arena, 0, -1) })
+	require.Panics(t, func() { _ = MakeSlice[int64](arena, 2, 1) })
+
+	// Nothing was allocated from the arena.
+	require.Zero(t, arena.(*monotonicArena).BufferUsage()[0].Used)
+}
+
+func FuzzMakeSlice(f *testing.F) {
+	f.Add(0, 0)
+	f.Add(4, 16)
+	f.Add(-1, 1)
+	f.Add(2, 1)
+	f.Add(0, math.MaxInt/2+1)
+
+	arena := NewMonotonicArena(1024, 1)
+
+	f.Fuzz(func(t *testing.T, l, c int) {
+		valid := l >= 0 && l <= c
+		if valid && c > 1<<16 {
+			t.Skip("too big for the heap fallback")
+		}
+		arena.Reset(false)
+
+		if !valid {
+			require.Panics(t, func() { _ = MakeSlice[int64](arena, l, c) })
+			return
+		}
+		s := MakeSlice[int64](arena, l, c)
+		require.Len(t, s, l)
+		require.Equal(t, c, cap(s))
+	})
+}
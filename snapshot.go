@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "unsafe"
+
+// Snapshotter is implemented by arenas able to expose the memory they've handed out.
+type Snapshotter interface {
+	// Bytes returns the regions of memory handed out by the arena since its last reset,
+	// without copying them. They're only valid until the arena is reset.
+	Bytes() [][]byte
+
+	// Snapshot returns a copy of the regions of memory handed out by the arena since its
+	// last reset, one after another.
+	Snapshot() []byte
+}
+
+// Bytes returns the regions of memory handed out by the arena since its last reset, in the order
+// they were filled, or nil if it doesn't implement Snapshotter. The regions aren't copied, so they
+// may be written to a connection or file as they are, such as with net.Buffers, but they're only
+// valid until the arena is reset.
+//
+// For the arenas of this package, a region is the used part of a buffer, alignment padding included.
+// Allocations served from the heap, such as those above the threshold set with WithLargeAllocThreshold,
+// aren't part of any region.
+func Bytes(a Arena) [][]byte {
+	if s, ok := a.(Snapshotter); ok {
+		return s.Bytes()
+	}
+	return nil
+}
+
+// Snapshot returns a copy of the memory handed out by the arena since its last reset,
+// or nil if it doesn't implement Snapshotter. It holds the regions returned by Bytes
+// one after another, so that the value found at a given offset (see OffsetOf) of the
+// arena is found at the same offset of the snapshot.
+//
+// Since regions are laid out back to back, values in the snapshot aren't necessarily as
+// aligned as they were in the arena. Readers interpreting a snapshot in place should
+// access it through copies, such as with encoding/binary, or restore the arena with
+// Save and Load instead, which keep the alignment of values.
+func Snapshot(a Arena) []byte {
+	if s, ok := a.(Snapshotter); ok {
+		return s.Snapshot()
+	}
+	return nil
+}
+
+// Bytes satisfies the Snapshotter interface.
+func (a *monotonicArena) Bytes() [][]byte {
+	if debugEnabled {
+		a.guard.enter()
+		defer a.guard.exit()
+	}
+	var regions [][]byte
+	for _, s := range a.buffers {
+		if s.offset > 0 {
+			asanUnpoison(s.ptr, s.offset)
+			regions = append(regions, unsafe.Slice((*byte)(s.ptr), s.offset))
+		}
+	}
+	return regions
+}
+
+// Snapshot satisfies the Snapshotter interface.
+func (a *monotonicArena) Snapshot() []byte {
+	var n int
+	regions := a.Bytes()
+	for _, r := range regions {
+		n += len(r)
+	}
+	b := make([]byte, 0, n)
+	for _, r := range regions {
+		b = append(b, r...)
+	}
+	return b
+}
+
+// Bytes satisfies the Snapshotter interface, forwarding to the underlying arena.
+func (a *concurrentArena) Bytes() [][]byte {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return Bytes(a.a)
+}
+
+// Snapshot satisfies the Snapshotter interface, forwarding to the underlying arena.
+func (a *concurrentArena) Snapshot() []byte {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return Snapshot(a.a)
+}
+
+// Bytes satisfies the Snapshotter interface, forwarding to the underlying arena.
+func (a *ScavengingArena) Bytes() [][]byte {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return Bytes(a.a)
+}
+
+// Snapshot satisfies the Snapshotter interface, forwarding to the underlying arena.
+func (a *ScavengingArena) Snapshot() []byte {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return Snapshot(a.a)
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	a := NewConcurrentArena(NewMonotonicArena(64, 2))
+
+	s := MakeSlice[byte](a, 48, 48)
+	copy(s, "hello")
+	*New[[8]uint64](a) = [8]uint64{1}
+
+	snap := Snapshot(a)
+	require.Len(t, snap, 112) // the space stranded at the end of the first buffer isn't part of it
+	require.Equal(t, "hello", string(snap[:5]))
+	require.Equal(t, byte(1), snap[48])
+
+	regions := Bytes(a)
+	require.Len(t, regions, 2)
+	require.Len(t, regions[0], 48)
+	require.Len(t, regions[1], 64)
+	require.Equal(t, snap, append(regions[0], regions[1]...))
+
+	// Values are found at the same offset of the arena and the snapshot.
+	off, ok := OffsetOf(a, unsafe.Pointer(unsafe.SliceData(s)))
+	require.True(t, ok)
+	require.Equal(t, "hello", string(snap[off:off+5]))
+
+	a.Reset(false)
+	require.Empty(t, Snapshot(a))
+	require.Empty(t, Bytes(a))
+	require.Nil(t, Snapshot(NewGCArena(64)))
+}
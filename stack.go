@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+
+package nuke
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// callers returns the call stack of its caller. The skip parameter has the same meaning
+// as in runtime.Callers, with 0 identifying the caller of callers.
+func callers(skip int) allocStack {
+	var stk allocStack
+	runtime.Callers(skip+2, stk[:])
+	return stk
+}
+
+func formatStack(stk allocStack) string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(stackPCs(stk))
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			return sb.String()
+		}
+	}
+}
+
+// allocSite returns the location of the innermost frame of stk lying outside of this package.
+func allocSite(stk allocStack) AllocSite {
+	frames := runtime.CallersFrames(stackPCs(stk))
+	for {
+		frame, more := frames.Next()
+		if !isInternalFrame(frame) || !more {
+			return AllocSite{Function: frame.Function, File: frame.File, Line: frame.Line}
+		}
+	}
+}
+
+func isInternalFrame(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, pkgPath+".") && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+func stackPCs(stk allocStack) []uintptr {
+	var pcs []uintptr
+	for _, pc := range stk {
+		if pc == 0 {
+			break
+		}
+		pcs = append(pcs, pc)
+	}
+	return pcs
+}
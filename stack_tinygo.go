@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build tinygo
+
+package nuke
+
+// TinyGo doesn't support walking the call stack, so no stacks are recorded.
+
+func callers(int) allocStack { return allocStack{} }
+
+func formatStack(allocStack) string { return "" }
+
+func allocSite(allocStack) AllocSite { return AllocSite{} }
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "encoding/json"
+
+// Stats is a point-in-time report of the state of an arena, meant to be captured by operational
+// tooling and support bundles. It's encoded to JSON as is, so no metrics library is needed.
+type Stats struct {
+	// Capabilities are the names of the optional features the arena supports (see Capabilities).
+	Capabilities string `json:"capabilities"`
+
+	// Metrics are the allocation metrics of the arena.
+	Metrics Metrics `json:"metrics"`
+
+	// Buffers is the usage of every buffer of the arena, if it reports it.
+	Buffers []BufferUsage `json:"buffers,omitempty"`
+
+	// SizeHistogram is the histogram of allocation sizes of the arena, if enabled (see WithSizeHistogram).
+	SizeHistogram *SizeHistogram `json:"size_histogram,omitempty"`
+}
+
+// StatsOf returns the stats of the provided Arena. Every part of the stats is read separately,
+// so those of an arena being allocated from concurrently may not be consistent with each other.
+func StatsOf(a Arena) Stats {
+	c := Capabilities(a)
+	s := Stats{
+		Capabilities: c.String(),
+		Metrics:      arenaMetrics(a),
+	}
+	if c.Has(CapBufferUsage) {
+		s.Buffers = a.(bufferUsageReporter).BufferUsage()
+	}
+	if c.Has(CapSizeHistogram) {
+		if h, ok := a.(sizeHistogramReporter).SizeHistogram(); ok {
+			s.SizeHistogram = &h
+		}
+	}
+	return s
+}
+
+// StatsJSON returns the stats of the provided Arena encoded as JSON.
+func StatsJSON(a Arena) ([]byte, error) {
+	return json.Marshal(StatsOf(a))
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsJSON(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewConcurrentArena(NewMonotonicArena(1024, 2, WithSizeHistogram()))
+	_ = MakeSlice[byte](arena, 1000, 1000)
+	_ = MakeSlice[byte](arena, 100, 100)
+
+	b, err := StatsJSON(arena)
+	require.NoError(t, err)
+
+	var s Stats
+	require.NoError(t, json.Unmarshal(b, &s))
+	require.Equal(t, StatsOf(arena), s)
+	require.Contains(t, s.Capabilities, "buffer-usage")
+	require.Equal(t, uint64(2), s.Metrics.Allocs)
+	require.Len(t, s.Buffers, 2)
+	require.Equal(t, uint64(1024), s.Buffers[0].Size)
+	require.Equal(t, uint64(1000), s.Buffers[0].Used)
+	require.Equal(t, uint64(24), s.Buffers[0].Stranded)
+	require.Equal(t, uint64(100), s.Buffers[1].Used)
+	require.NotNil(t, s.SizeHistogram)
+	require.Equal(t, uint64(2), s.SizeHistogram[7]+s.SizeHistogram[10])
+
+	// Arenas not keeping a histogram, nor reporting buffer usage, leave them out.
+	b, err = StatsJSON(NewGCArena(1024))
+	require.NoError(t, err)
+	require.NotContains(t, string(b), `"buffers"`)
+	require.NotContains(t, string(b), "size_histogram")
+
+	// So do wrappers of such arenas, whose capabilities are the ones of the arena they wrap.
+	s = StatsOf(NewConcurrentArena(NewGCArena(1024)))
+	require.Equal(t, Capabilities(NewGCArena(1024)).String(), s.Capabilities)
+	require.NotContains(t, s.Capabilities, "buffer-usage")
+	require.Nil(t, s.Buffers)
+}
@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"hash/maphash"
+	"math"
+	"unsafe"
+)
+
+// keyChunkSize is the minimum size of the chunks keys are interned into.
+const keyChunkSize = 4096
+
+// StringMap is a hash map keyed by strings whose memory is allocated from an arena, like OrderedMap,
+// with the difference that keys are copied into arena memory, once per distinct key, and referenced
+// by entries as offsets into it. This makes both the hash table and its entries free of pointers,
+// so they can be allocated from any arena, and neither them nor the keys are scanned by the garbage
+// collector, which suits large sets of short-lived strings, such as the label sets of metrics.
+//
+// Entries are iterated in insertion order. The map is only valid until the arena is reset, and it's
+// not safe to be accessed concurrently. Deleted keys keep using arena memory until then. The zero
+// value is an empty map allocating from the heap.
+//
+// Values holding pointers, strings included, must only be stored in maps allocating from arenas
+// created with NewGCArena, as with MakeSlice.
+type StringMap[V any] struct {
+	a    Arena
+	seed maphash.Seed
+
+	// chunks holds the memory keys are interned into. Keeping it referenced here makes it
+	// visible to the garbage collector, in case the arena couldn't serve it.
+	chunks [][]byte
+
+	// entries holds the entries of the map in insertion order, deleted ones included, and
+	// slots the hash table indexing them, as in OrderedMap.
+	entries []stringEntry[V]
+	slots   []int32
+
+	len  int
+	used int // number of non-empty slots
+}
+
+type stringEntry[V any] struct {
+	hash uint64
+
+	// chunk, off and len locate the key in the chunks of the map.
+	chunk   uint32
+	off     uint32
+	len     uint32
+	deleted bool
+
+	value V
+}
+
+// NewStringMap returns an empty StringMap allocating from the arena, with room for capacity entries.
+// If passed arena is nil, the map is allocated from the heap.
+func NewStringMap[V any](a Arena, capacity int) *StringMap[V] {
+	m := &StringMap[V]{a: a}
+	m.grow(capacity)
+	return m
+}
+
+// Len returns the number of entries of the map.
+func (m *StringMap[V]) Len() int {
+	return m.len
+}
+
+// Get returns the value associated with key, and whether there's one.
+func (m *StringMap[V]) Get(key string) (V, bool) {
+	if m.len > 0 {
+		if i, ok := m.find(key, maphash.String(m.seed, key)); ok {
+			return m.entries[m.slots[i]-1].value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Set associates value with key, copying the key into arena memory if it isn't in the map yet.
+// Keys already in the map keep their position in the iteration order.
+func (m *StringMap[V]) Set(key string, value V) {
+	if (m.used+1)*4 > len(m.slots)*3 {
+		m.grow(m.len + 1)
+	} else if deleted := len(m.entries) - m.len; len(m.entries) == cap(m.entries) && deleted*4 >= len(m.entries) {
+		m.grow(m.len + 1) // drop deleted entries rather than growing the entries array
+	}
+	h := maphash.String(m.seed, key)
+	i, ok := m.find(key, h)
+	if ok {
+		m.entries[m.slots[i]-1].value = value
+		return
+	}
+	if m.slots[i] == 0 {
+		m.used++
+	}
+	e := stringEntry[V]{hash: h, value: value}
+	e.chunk, e.off, e.len = m.intern(key)
+	m.entries = SliceAppend(m.a, m.entries, e)
+	m.slots[i] = int32(len(m.entries))
+	m.len++
+}
+
+// Delete removes the entry of key, reporting whether there was one. Keys set again afterwards
+// are moved to the end of the iteration order.
+func (m *StringMap[V]) Delete(key string) bool {
+	if m.len == 0 {
+		return false
+	}
+	i, ok := m.find(key, maphash.String(m.seed, key))
+	if !ok {
+		return false
+	}
+	m.entries[m.slots[i]-1] = stringEntry[V]{deleted: true}
+	m.slots[i] = -1
+	m.len--
+	return true
+}
+
+// Range calls fn for every entry of the map in insertion order, until it returns false. The keys
+// passed to fn are the ones interned into arena memory, which are only valid until the arena is reset.
+// The map must not be modified by fn.
+func (m *StringMap[V]) Range(fn func(key string, value V) bool) {
+	for i := range m.entries {
+		e := &m.entries[i]
+		if !e.deleted && !fn(m.key(e), e.value) {
+			return
+		}
+	}
+}
+
+// find returns the slot of key, whose hash is h, reporting whether it's in the map.
+// Otherwise, the returned slot is the one key would be inserted into.
+func (m *StringMap[V]) find(key string, h uint64) (int, bool) {
+	mask := len(m.slots) - 1
+	free := -1
+	for i := int(h) & mask; ; i = (i + 1) & mask {
+		switch s := m.slots[i]; {
+		case s == 0:
+			if free < 0 {
+				free = i
+			}
+			return free, false
+		case s < 0:
+			if free < 0 {
+				free = i
+			}
+		case m.entries[s-1].hash == h && m.key(&m.entries[s-1]) == key:
+			return i, true
+		}
+	}
+}
+
+// key returns the interned key of the entry.
+func (m *StringMap[V]) key(e *stringEntry[V]) string {
+	if e.len == 0 {
+		return ""
+	}
+	return unsafe.String(&m.chunks[e.chunk][e.off], e.len)
+}
+
+// intern copies key into the last chunk of the map, or a new one if it doesn't fit,
+// returning where it's been copied to.
+func (m *StringMap[V]) intern(key string) (chunk, off, n uint32) {
+	if len(key) == 0 {
+		return 0, 0, 0
+	}
+	if uint64(len(key)) > math.MaxUint32 {
+		panic("nuke: StringMap key too long")
+	}
+	last := len(m.chunks) - 1
+	if last < 0 || cap(m.chunks[last])-len(m.chunks[last]) < len(key) {
+		m.chunks = append(m.chunks, MakeSlice[byte](m.a, 0, max(keyChunkSize, len(key))))
+		last++
+	}
+	b := m.chunks[last]
+	m.chunks[last] = append(b, key...)
+	return uint32(last), uint32(len(b)), uint32(len(key))
+}
+
+// grow makes room for n entries, dropping deleted entries and rebuilding the hash table.
+func (m *StringMap[V]) grow(n int) {
+	if m.slots == nil {
+		m.seed = maphash.MakeSeed()
+	}
+	n = max(n, 2*m.len)
+	size := mapSlots(n)
+	if m.len < len(m.entries) {
+		live := m.entries[:0]
+		for _, e := range m.entries {
+			if !e.deleted {
+				live = append(live, e)
+			}
+		}
+		clear(m.entries[len(live):])
+		m.entries = live
+	}
+	if cap(m.entries) < n {
+		entries := MakeSlice[stringEntry[V]](m.a, len(m.entries), n)
+		copy(entries, m.entries)
+		m.entries = entries
+	}
+	if len(m.slots) == size {
+		clear(m.slots)
+	} else {
+		m.slots = MakeSlice[int32](m.a, size, size)
+	}
+	m.used = len(m.entries)
+	mask := size - 1
+	for j := range m.entries {
+		i := int(m.entries[j].hash) & mask
+		for m.slots[i] != 0 {
+			i = (i + 1) & mask
+		}
+		m.slots[i] = int32(j + 1)
+	}
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringMap(t *testing.T) {
+	arena := NewMonotonicArena(256*1024, 1)
+	m := NewStringMap[int](arena, 0)
+
+	// Keys are copied into arena memory.
+	b := []byte("job=api")
+	m.Set(unsafe.String(&b[0], len(b)), 1)
+	b[0] = 'J'
+	v, ok := m.Get("job=api")
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+	_, ok = m.Get("Job=api")
+	require.False(t, ok)
+
+	m.Set("", 2)
+	m.Set("instance=a", 3)
+	m.Set(strings.Repeat("x", 2*keyChunkSize), 4)
+	for i := 0; i < 1000; i++ {
+		m.Set("id="+strconv.Itoa(i), i)
+	}
+	require.Equal(t, 1004, m.Len())
+	v, _ = m.Get("")
+	require.Equal(t, 2, v)
+	v, _ = m.Get(strings.Repeat("x", 2*keyChunkSize))
+	require.Equal(t, 4, v)
+
+	// Setting a key already in the map doesn't intern it again.
+	chunks := len(m.chunks)
+	used := len(m.chunks[chunks-1])
+	m.Set("id=999", -1)
+	require.Equal(t, chunks, len(m.chunks))
+	require.Equal(t, used, len(m.chunks[chunks-1]))
+
+	require.True(t, m.Delete("job=api"))
+	require.False(t, m.Delete("job=api"))
+	m.Set("job=api", 5)
+
+	var keys []string
+	m.Range(func(k string, _ int) bool {
+		keys = append(keys, k)
+		return len(keys) < 3
+	})
+	require.Equal(t, []string{"", "instance=a", strings.Repeat("x", 2*keyChunkSize)}, keys)
+	m.Range(func(k string, v int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	require.Equal(t, "job=api", keys[len(keys)-1])
+	require.Zero(t, arenaMetrics(arena).HeapFallbacks)
+}
+
+func TestStringMapHeap(t *testing.T) {
+	var m StringMap[string]
+	for i := 0; i < 100; i++ {
+		m.Set(strconv.Itoa(i), strconv.Itoa(-i))
+		m.Delete(strconv.Itoa(i - 1))
+	}
+	require.Equal(t, 1, m.Len())
+	v, ok := m.Get("99")
+	require.True(t, ok)
+	require.Equal(t, "-99", v)
+}
@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+
+package nuke
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+// traceCategory is the category of the runtime/trace events emitted by arenas.
+const traceCategory = "nuke"
+
+// traceRegion starts a runtime/trace region, which is a no-op unless tracing is enabled.
+func traceRegion(name string) *trace.Region {
+	return trace.StartRegion(context.Background(), name)
+}
+
+// traceOverflow logs a runtime/trace event for an allocation that couldn't be served by an arena.
+func traceOverflow(size uintptr) {
+	if trace.IsEnabled() {
+		trace.Logf(context.Background(), traceCategory, "overflow: %d bytes", size)
+	}
+}
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !tinygo
+
+package nuke
+
+import (
+	"bytes"
+	"runtime/trace"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceAnnotations(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	var buf bytes.Buffer
+	require.NoError(t, trace.Start(&buf))
+
+	arena := NewMonotonicArena(1024, 1)
+	_ = New[int](arena)
+	_ = MakeSlice[byte](arena, 0, 2048) // overflow
+	arena.Reset(true)
+
+	trace.Stop()
+
+	for _, s := range []string{"nuke.allocBuffer", "nuke.Reset", "overflow: 2048 bytes"} {
+		require.True(t, bytes.Contains(buf.Bytes(), []byte(s)), s)
+	}
+}
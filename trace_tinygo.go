@@ -0,0 +1,15 @@
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build tinygo
+
+package nuke
+
+// TinyGo lacks runtime/trace, so arenas emit no trace events.
+
+type traceRegionStub struct{}
+
+func (traceRegionStub) End() {}
+
+func traceRegion(string) traceRegionStub { return traceRegionStub{} }
+
+func traceOverflow(uintptr) {}
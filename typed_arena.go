@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"unsafe"
+)
+
+// TypedArena allocates values and slices of type T from an arena.
+// Size and alignment of T are computed only once, and allocations from a monotonic arena
+// bypass the Arena interface altogether. Since the size of a Go type is a multiple of its
+// alignment, values allocated one after another from a monotonic arena are packed with no
+// padding in between, which suits code allocating large numbers of a single type, such as
+// columnar or entity-component data.
+type TypedArena[T any] struct {
+	a     Arena
+	ma    *monotonicArena
+	ta    typedAllocator
+	size  uintptr
+	align uintptr
+}
+
+// NewTypedArena returns a TypedArena that allocates values of type T from the provided Arena.
+// If passed arena is nil, values are allocated using Go's built-in new function.
+func NewTypedArena[T any](a Arena) *TypedArena[T] {
+	var x T
+	ta := &TypedArena[T]{
+		a:     a,
+		size:  unsafe.Sizeof(x),
+		align: unsafe.Alignof(x),
+	}
+	ta.ma, _ = a.(*monotonicArena)
+	ta.ta, _ = a.(typedAllocator)
+
+	if debugEnabled && a != nil && ta.ta == nil {
+		assertPointerFree(typeOf[T]())
+	}
+	return ta
+}
+
+// New allocates memory for a value of type T and returns a pointer to it.
+// Like New, it falls back to Go's built-in new function when the arena can't serve the allocation.
+func (ta *TypedArena[T]) New() *T {
+	if arenasDisabled {
+		return new(T)
+	}
+	if ptr := ta.alloc(ta.size, 1); ptr != nil {
+		return (*T)(ptr)
+	}
+	return new(T)
+}
+
+// Make creates a slice of type T with the given length and capacity.
+// Like MakeSlice, it falls back to Go's built-in make function when the arena can't serve the allocation.
+func (ta *TypedArena[T]) Make(len, cap int) []T {
+	if arenasDisabled {
+		return make([]T, len, cap)
+	}
+	size, ok := sliceSize(ta.size, len, cap)
+	if !ok {
+		// Leave invalid lengths and capacities to make, which panics accordingly.
+		return make([]T, len, cap)
+	}
+	if ptr := ta.alloc(size, cap); ptr != nil {
+		return unsafe.Slice((*T)(ptr), cap)[:len]
+	}
+	return make([]T, len, cap)
+}
+
+// Append appends elements to a slice of type T, growing it from the arena if needed,
+// like SliceAppend does.
+func (ta *TypedArena[T]) Append(s []T, data ...T) []T {
+	if ta.a == nil || arenasDisabled {
+		return append(s, data...)
+	}
+	if newCap := growCap(len(s), cap(s), len(data)); newCap != cap(s) {
+		s2 := ta.Make(len(s), newCap)
+		copy(s2, s)
+		s = s2
+	}
+	return append(s, data...)
+}
+
+// alloc allocates size bytes for n values of type T, recording a heap fallback if the
+// arena can't serve them.
+func (ta *TypedArena[T]) alloc(size uintptr, n int) unsafe.Pointer {
+	var ptr unsafe.Pointer
+	if ta.ma != nil {
+		ptr = ta.ma.Alloc(size, ta.align)
+	} else if ta.ta != nil {
+		ptr = ta.ta.allocTyped(typeOf[T](), n)
+	} else if ta.a != nil {
+		ptr = ta.a.Alloc(size, ta.align)
+	}
+	if ptr != nil {
+		if debugEnabled {
+			assertAligned(ptr, ta.align)
+		}
+		return ptr
+	}
+	if ta.a != nil {
+		recordHeapFallback(ta.a)
+	}
+	return nil
+}
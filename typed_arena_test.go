@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"fmt"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedArenaNew(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(4*int(unsafe.Sizeof(noScanObject{})), 1) // 4 objects room
+
+	ta := NewTypedArena[noScanObject](arena)
+	for i := 0; i < 4; i++ {
+		obj := ta.New()
+		require.True(t, isMonotonicArenaPtr(arena, unsafe.Pointer(obj)))
+		require.Zero(t, uintptr(unsafe.Pointer(obj))%unsafe.Alignof(*obj))
+		require.Equal(t, noScanObject{}, *obj)
+	}
+	// Arena is exhausted, so objects are sent to the heap.
+	require.False(t, isMonotonicArenaPtr(arena, unsafe.Pointer(ta.New())))
+}
+
+func TestTypedArenaNilArena(t *testing.T) {
+	ta := NewTypedArena[int](nil)
+	require.NotNil(t, ta.New())
+}
+
+func TestTypedArenaMake(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	arena := NewMonotonicArena(1024, 1)
+	ta := NewTypedArena[uint32](arena)
+
+	s := ta.Make(2, 4)
+	require.Len(t, s, 2)
+	require.Equal(t, 4, cap(s))
+	require.True(t, isMonotonicArenaPtr(arena, unsafe.Pointer(unsafe.SliceData(s))))
+
+	s = ta.Append(s, 1, 2)
+	require.Equal(t, []uint32{0, 0, 1, 2}, s)
+	s = ta.Append(s, 3)
+	require.Equal(t, []uint32{0, 0, 1, 2, 3}, s)
+	require.Equal(t, 8, cap(s))
+	require.True(t, isMonotonicArenaPtr(arena, unsafe.Pointer(unsafe.SliceData(s))))
+
+	require.Panics(t, func() { ta.Make(2, 1) })
+	require.Equal(t, []int{1}, NewTypedArena[int](nil).Append(nil, 1))
+	require.Len(t, NewTypedArena[int](nil).Make(1, 1), 1)
+
+	// Values of the same type are packed with no padding in between.
+	ta2 := NewTypedArena[[3]uint64](arena)
+	x, y := ta2.New(), ta2.New()
+	require.Equal(t, unsafe.Add(unsafe.Pointer(x), 24), unsafe.Pointer(y))
+}
+
+func BenchmarkTypedArenaNewObject(b *testing.B) {
+	monotonicArena := NewMonotonicArena(32*1024*1024, 6) // 32Mb buffer size (192Mb max size)
+
+	ta := NewTypedArena[noScanObject](monotonicArena)
+
+	for _, objectCount := range []int{100, 1_000, 10_000, 100_000, 1_000_000} {
+		b.Run(fmt.Sprintf("%d", objectCount), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for j := 0; j < objectCount; j++ {
+					_ = ta.New()
+				}
+				monotonicArena.Reset(false)
+			}
+		})
+	}
+}
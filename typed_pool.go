@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import "sync"
+
+// typedPoolChunkSize is the chunk size of the arena created for a TypedPool not given one.
+const typedPoolChunkSize = 64 * 1024
+
+// TypedPool is a pool of values of type T allocated from an arena, meant as a drop-in replacement
+// for a sync.Pool whose values would otherwise be allocated from the heap one by one. Like with a
+// sync.Pool, values put back into the pool are handed out again by Get as they are, without being
+// cleared, and a TypedPool must not be copied after first use.
+//
+// Unlike a sync.Pool, values aren't dropped at garbage collections, but only once the pool is
+// reset, which resets its arena too. Values are allocated by calling New with the arena while
+// holding the pool lock, so the arena needs not be concurrent-safe itself.
+type TypedPool[T any] struct {
+	// New optionally allocates a value from the given arena when the pool is empty.
+	// If nil, Get allocates a zero value with New[T].
+	New func(Arena) *T
+
+	// Arena is the arena values are allocated from. If nil, an arena created with NewGCArena
+	// is used, which grows as needed and may hold values of any type.
+	Arena Arena
+
+	mtx  sync.Mutex
+	free []*T
+}
+
+// Get returns a value from the pool, allocating a new one from the arena if the pool is empty.
+func (p *TypedPool[T]) Get() *T {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if n := len(p.free); n > 0 {
+		x := p.free[n-1]
+		p.free[n-1] = nil
+		p.free = p.free[:n-1]
+		return x
+	}
+	if p.Arena == nil {
+		p.Arena = NewGCArena(typedPoolChunkSize)
+	}
+	if p.New != nil {
+		return p.New(p.Arena)
+	}
+	return New[T](p.Arena)
+}
+
+// Put returns a value obtained from Get to the pool. Nil values are ignored.
+func (p *TypedPool[T]) Put(x *T) {
+	if x == nil {
+		return
+	}
+	p.mtx.Lock()
+	p.free = append(p.free, x)
+	p.mtx.Unlock()
+}
+
+// Reset drains the pool, dropping every value put back into it, and resets its arena,
+// keeping its memory for the values allocated afterwards. Values obtained from the pool
+// must not be accessed anymore once it has been reset, whether they were put back or not.
+func (p *TypedPool[T]) Reset() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	clear(p.free)
+	p.free = p.free[:0]
+	if p.Arena != nil {
+		p.Arena.Reset(false)
+	}
+}
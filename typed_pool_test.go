@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package nuke
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedPool(t *testing.T) {
+	if arenasDisabled {
+		t.Skip("arenas are disabled")
+	}
+	type item struct {
+		id   int
+		data []byte
+	}
+	a := NewMonotonicArena(1024, 1)
+	p := TypedPool[item]{
+		New: func(a Arena) *item {
+			return &item{data: MakeSlice[byte](a, 0, 64)} // the item itself lives on the heap
+		},
+		Arena: a,
+	}
+
+	x := p.Get()
+	require.Equal(t, 64, cap(x.data))
+	x.id = 1
+	p.Put(x)
+	p.Put(nil)
+
+	// Values are reused as they are.
+	y := p.Get()
+	require.Same(t, x, y)
+	require.Equal(t, 1, y.id)
+	require.NotSame(t, y, p.Get())
+	require.Equal(t, uint64(2), arenaMetrics(a).Allocs)
+
+	p.Put(y)
+	p.Reset()
+	require.Equal(t, uint64(1), arenaMetrics(a).Resets)
+	require.NotSame(t, y, p.Get())
+}
+
+func TestTypedPoolDefaultArena(t *testing.T) {
+	var p TypedPool[string]
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s := p.Get()
+				*s = "hello" // GC arenas may hold pointers
+				p.Put(s)
+			}
+		}()
+	}
+	wg.Wait()
+	require.IsType(t, &gcArena{}, p.Arena)
+	require.LessOrEqual(t, arenaMetrics(p.Arena).Allocs, uint64(8))
+}